@@ -9,12 +9,16 @@ import (
 	"github.com/uhppoted/uhppote-core/uhppote"
 )
 
-func Grant(u uhppote.IUHPPOTE, devices []uhppote.Device, cardID uint32, from, to types.Date, profile int, doors []string) error {
+// Grants a card access to a list of doors. The door list may include zone names, which are
+// expanded to the doors in the zone.
+func Grant(u uhppote.IUHPPOTE, devices []uhppote.Device, cardID uint32, from, to types.Date, profile int, doors []string, zones ...Zones) error {
 	m, err := mapDeviceDoors(devices)
 	if err != nil {
 		return err
 	}
 
+	doors = merge(zones).Expand(doors)
+
 	if reflect.DeepEqual(doors, []string{"ALL"}) {
 		for _, d := range devices {
 			if err := grantAll(u, d.DeviceID, cardID, from, to); err != nil {
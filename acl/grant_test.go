@@ -562,3 +562,56 @@ func TestGrantWithNoCurrentPermissions(t *testing.T) {
 		t.Errorf("Device internal card list not updated correctly:\n    expected:%+v\n    got:     %+v", expected, cards)
 	}
 }
+
+func TestGrantWithZone(t *testing.T) {
+	expected := []types.Card{
+		types.Card{CardNumber: 65538, From: types.MustParseDate("2023-01-01"), To: types.MustParseDate("2023-12-31"), Doors: map[uint8]uint8{1: 1, 2: 0, 3: 1, 4: 1}, PIN: 5432},
+	}
+
+	devices := []uhppote.Device{
+		uhppote.Device{
+			DeviceID: 12345,
+			Doors:    []string{"Front Door", "Side Door", "Garage", "Workshop"},
+		},
+	}
+
+	zones := Zones{
+		"Outbuildings": []string{"Garage", "Workshop"},
+	}
+
+	cards := []types.Card{
+		types.Card{CardNumber: 65538, From: types.MustParseDate("2023-02-03"), To: types.MustParseDate("2023-11-30"), Doors: map[uint8]uint8{1: 1, 2: 0, 3: 0, 4: 0}, PIN: 5432},
+	}
+
+	u := mock{
+		getCardByID: func(deviceID, cardID uint32) (*types.Card, error) {
+			for _, c := range cards {
+				if c.CardNumber == cardID {
+					return &c, nil
+				}
+			}
+			return nil, nil
+		},
+		putCard: func(deviceID uint32, card types.Card) (bool, error) {
+			for ix, c := range cards {
+				if c.CardNumber == card.CardNumber {
+					cards[ix] = card
+					return true, nil
+				}
+			}
+
+			cards = append(cards, card)
+
+			return true, nil
+		},
+	}
+
+	err := Grant(&u, devices, 65538, types.MustParseDate("2023-01-01"), types.MustParseDate("2023-12-31"), 0, []string{"outbuildings", "Garage"}, zones)
+	if err != nil {
+		t.Fatalf("Unexpected error invoking 'grant': %v", err)
+	}
+
+	if !reflect.DeepEqual(cards, expected) {
+		t.Errorf("Device internal card list not updated correctly:\n    expected:%+v\n    got:     %+v", expected, cards)
+	}
+}
@@ -3,6 +3,7 @@ package acl
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -11,7 +12,8 @@ import (
 	"github.com/uhppoted/uhppote-core/uhppote"
 )
 
-func parseHeader(header []string, devices []uhppote.Device) (*index, error) {
+func parseHeader(header []string, devices []uhppote.Device, zones ...Zones) (*index, error) {
+	z := merge(zones)
 	columns := make(map[string]struct {
 		door  string
 		index int
@@ -57,6 +59,10 @@ loop:
 				}
 			}
 
+			if _, ok := z.lookup(c); ok {
+				continue loop
+			}
+
 			return nil, fmt.Errorf("no configured door matches '%s'", v.door)
 		}
 	}
@@ -87,6 +93,46 @@ loop:
 		}
 	}
 
+	// ... zone columns apply to any zone door without a door column
+	isDoor := func(c string) bool {
+		for _, device := range devices {
+			for _, door := range device.Doors {
+				if clean(door) == c {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	// ... a door without a door column that is in more than one zone column is ambiguous (the zone
+	//     columns are processed in header order so that the error is reproducible)
+	zoned := map[uint32][]string{}
+	for _, device := range devices {
+		zoned[device.DeviceID] = make([]string, len(device.Doors))
+	}
+
+	for _, field := range header {
+		c := clean(field)
+		v := columns[c]
+
+		if zone, ok := z.lookup(c); ok && !isDoor(c) {
+			for _, device := range devices {
+				for i, door := range device.Doors {
+					if d := clean(door); d != "" && slices.ContainsFunc(zone, func(zd string) bool { return clean(zd) == d }) {
+						if index.doors[device.DeviceID][i] == 0 {
+							index.doors[device.DeviceID][i] = v.index
+							zoned[device.DeviceID][i] = field
+						} else if zoned[device.DeviceID][i] != "" {
+							return nil, fmt.Errorf("door '%s' is in more than one zone column ('%s' and '%s')", door, zoned[device.DeviceID][i], field)
+						}
+					}
+				}
+			}
+		}
+	}
+
 	if index.cardnumber == 0 {
 		return nil, fmt.Errorf("missing 'Card Number' column")
 	}
@@ -41,6 +41,79 @@ func TestParseHeader(t *testing.T) {
 	}
 }
 
+func TestParseHeaderWithZone(t *testing.T) {
+	expected := index{
+		cardnumber: 1,
+		from:       2,
+		to:         3,
+		doors: map[uint32][]int{
+			12345: []int{0, 4, 5, 5},
+		},
+		PIN: 0,
+	}
+
+	header := []string{"Card Number", "From", "To", "Side Door", "Outbuildings"}
+
+	devices := []uhppote.Device{
+		uhppote.Device{
+			DeviceID: 12345,
+			Doors:    []string{"Front Door", "Side Door", "Garage", "Workshop"},
+		},
+	}
+
+	zones := Zones{
+		"outbuildings": []string{"Side Door", "Garage", "Workshop"},
+	}
+
+	ix, err := parseHeader(header, devices, zones)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing header: %v", err)
+	} else if ix == nil {
+		t.Fatalf("parseHeader returned 'nil'")
+	}
+
+	if !reflect.DeepEqual(*ix, expected) {
+		t.Errorf("Invalid index\n   expected: %+v\n   got:      %+v", expected, *ix)
+	}
+}
+
+func TestParseHeaderWithOverlappingZones(t *testing.T) {
+	devices := []uhppote.Device{
+		uhppote.Device{
+			DeviceID: 12345,
+			Doors:    []string{"Front Door", "Side Door", "Garage", "Workshop"},
+		},
+	}
+
+	zones := Zones{
+		"outbuildings": []string{"Side Door", "Garage", "Workshop"},
+		"workshops":    []string{"Garage", "Workshop"},
+	}
+
+	header := []string{"Card Number", "From", "To", "Outbuildings", "Workshops"}
+	if _, err := parseHeader(header, devices, zones); err == nil {
+		t.Errorf("Expected error for door in more than one zone column, got:%v", err)
+	}
+
+	// ... door columns resolve the overlap
+	expected := index{
+		cardnumber: 1,
+		from:       2,
+		to:         3,
+		doors: map[uint32][]int{
+			12345: []int{0, 4, 6, 7},
+		},
+		PIN: 0,
+	}
+
+	header = []string{"Card Number", "From", "To", "Outbuildings", "Workshops", "Garage", "Workshop"}
+	if ix, err := parseHeader(header, devices, zones); err != nil {
+		t.Fatalf("Unexpected error parsing header: %v", err)
+	} else if !reflect.DeepEqual(*ix, expected) {
+		t.Errorf("Invalid index\n   expected: %+v\n   got:      %+v", expected, *ix)
+	}
+}
+
 func TestParseHeaderWithPIN(t *testing.T) {
 	expected := index{
 		cardnumber: 1,
@@ -6,6 +6,8 @@ import (
 
 	"github.com/uhppoted/uhppote-core/types"
 	"github.com/uhppoted/uhppote-core/uhppote"
+
+	"github.com/uhppoted/uhppoted-lib/tracing"
 )
 
 func PutACL(u uhppote.IUHPPOTE, acl ACL, dryrun bool, formats ...types.CardFormat) (map[uint32]Report, []error) {
@@ -25,6 +27,8 @@ func PutACLWithPIN(u uhppote.IUHPPOTE, acl ACL, dryrun bool, formats ...types.Ca
 }
 
 func putACLImpl(u uhppote.IUHPPOTE, acl ACL, dryrun bool, write put, eq equivalent) (map[uint32]Report, []error) {
+	tracer := currentTracer()
+	_, span := trace(tracer, u, nil, "put-acl", tracing.Attr("controllers", len(acl)), tracing.Attr("dryrun", dryrun))
 	report := sync.Map{}
 	errors := []error{}
 	guard := sync.RWMutex{}
@@ -51,12 +55,15 @@ func putACLImpl(u uhppote.IUHPPOTE, acl ACL, dryrun bool, write put, eq equivale
 			var rpt *Report
 			var err error
 
+			x, child := trace(tracer, u, span, "put-controller-acl", tracing.Attr("controller", id), tracing.Attr("cards", len(cards)))
 			if dryrun {
-				rpt, err = fakePutACL(u, id, cards)
+				rpt, err = fakePutACL(x, id, cards)
 			} else {
-				rpt, err = putACL(u, id, cards, write, eq)
+				rpt, err = putACL(x, id, cards, write, eq)
 			}
 
+			child.End(err)
+
 			if rpt != nil {
 				report.Store(id, *rpt)
 			}
@@ -72,6 +79,12 @@ func putACLImpl(u uhppote.IUHPPOTE, acl ACL, dryrun bool, write put, eq equivale
 
 	wg.Wait()
 
+	if len(errors) > 0 {
+		span.End(fmt.Errorf("%v of %v controllers not updated (%w)", len(errors), len(acl), errors[0]))
+	} else {
+		span.End(nil)
+	}
+
 	r := map[uint32]Report{}
 	report.Range(func(k, v any) bool {
 		r[k.(uint32)] = v.(Report)
@@ -8,7 +8,9 @@ import (
 	"github.com/uhppoted/uhppote-core/uhppote"
 )
 
-func Revoke(u uhppote.IUHPPOTE, devices []uhppote.Device, cardID uint32, doors []string) error {
+// Revokes card access to a list of doors. The door list may include zone names, which are
+// expanded to the doors in the zone.
+func Revoke(u uhppote.IUHPPOTE, devices []uhppote.Device, cardID uint32, doors []string, zones ...Zones) error {
 	m, err := mapDeviceDoors(devices)
 	if err != nil {
 		return err
@@ -20,7 +22,7 @@ func Revoke(u uhppote.IUHPPOTE, devices []uhppote.Device, cardID uint32, doors [
 			list = append(list, k)
 		}
 	} else {
-		list = append(list, doors...)
+		list = append(list, merge(zones).Expand(doors)...)
 	}
 
 	for _, dd := range list {
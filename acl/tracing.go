@@ -0,0 +1,49 @@
+package acl
+
+import (
+	"sync/atomic"
+
+	"github.com/uhppoted/uhppote-core/uhppote"
+
+	"github.com/uhppoted/uhppoted-lib/tracing"
+)
+
+var tracer atomic.Pointer[tracing.Tracer]
+
+// Sets the tracer for ACL updates (PutACL and PutACLWithPIN), which are traced as a 'put-acl'
+// span with a child span for each controller and for each controller call. A nil tracer (the
+// default) disables tracing. Changing the tracer does not affect ACL updates that have already
+// been started.
+func SetTracer(t tracing.Tracer) {
+	if t == nil {
+		tracer.Store(nil)
+	} else {
+		tracer.Store(&t)
+	}
+}
+
+// Returns the current ACL tracer or nil if tracing is disabled.
+func currentTracer() tracing.Tracer {
+	if t := tracer.Load(); t != nil {
+		return *t
+	}
+
+	return nil
+}
+
+// Starts a span for the operation (as a child span of the parent span if not nil) and returns
+// the IUHPPOTE that traces the controller calls as child spans of the new span.
+func trace(t tracing.Tracer, u uhppote.IUHPPOTE, parent tracing.Span, operation string, attributes ...tracing.Attribute) (uhppote.IUHPPOTE, tracing.Span) {
+	if t == nil {
+		return u, tracing.NopTracer{}.Start(operation)
+	}
+
+	var span tracing.Span
+	if parent != nil {
+		span = parent.Start(operation, attributes...)
+	} else {
+		span = t.Start(operation, attributes...)
+	}
+
+	return tracing.NewUHPPOTE(u, span), span
+}
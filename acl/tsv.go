@@ -10,7 +10,7 @@ import (
 	"github.com/uhppoted/uhppote-core/uhppote"
 )
 
-func ParseTSV(f io.Reader, devices []uhppote.Device, strict bool) (ACL, []error, error) {
+func ParseTSV(f io.Reader, devices []uhppote.Device, strict bool, zones ...Zones) (ACL, []error, error) {
 	acl := make(ACL)
 	for _, device := range devices {
 		acl[device.DeviceID] = make(map[uint32]types.Card)
@@ -24,7 +24,7 @@ func ParseTSV(f io.Reader, devices []uhppote.Device, strict bool) (ACL, []error,
 		return nil, nil, err
 	}
 
-	index, err := parseHeader(header, devices)
+	index, err := parseHeader(header, devices, zones...)
 	if err != nil {
 		return nil, nil, err
 	} else if index == nil {
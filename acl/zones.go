@@ -0,0 +1,62 @@
+package acl
+
+import (
+	"strings"
+)
+
+// Zones maps a zone name to the doors in the zone, e.g. as defined by
+//
+//	zone.lab = Lab A, Lab B, Store
+//
+// in the uhppoted.conf file. Zone names can be used anywhere a door name is accepted.
+type Zones map[string][]string
+
+// Expands any zone names in the list of doors to the doors in the zone. Zone names are matched
+// case-insensitively, ignoring spaces (as for door names). Duplicate doors are removed.
+func (z Zones) Expand(doors []string) []string {
+	list := []string{}
+	added := map[string]bool{}
+
+	add := func(door string) {
+		if k := clean(door); !added[k] {
+			added[k] = true
+			list = append(list, door)
+		}
+	}
+
+	for _, door := range doors {
+		if zone, ok := z.lookup(door); ok {
+			for _, d := range zone {
+				add(strings.TrimSpace(d))
+			}
+		} else {
+			add(door)
+		}
+	}
+
+	return list
+}
+
+func (z Zones) lookup(name string) ([]string, bool) {
+	key := clean(name)
+
+	for k, v := range z {
+		if clean(k) == key {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func merge(zones []Zones) Zones {
+	m := Zones{}
+
+	for _, z := range zones {
+		for k, v := range z {
+			m[k] = v
+		}
+	}
+
+	return m
+}
@@ -6,9 +6,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/netip"
-	"os"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strconv"
@@ -20,16 +21,20 @@ import (
 	"github.com/uhppoted/uhppote-core/uhppote"
 	"github.com/uhppoted/uhppoted-lib/encoding/conf"
 	"github.com/uhppoted/uhppoted-lib/monitoring"
+	"github.com/uhppoted/uhppoted-lib/scheduler"
 )
 
 type DeviceMap map[uint32]*Device
 
 type Device struct {
+	Model    string
 	Name     string
 	Address  types.ControllerAddr
 	Doors    []string
 	TimeZone string
 	Protocol string
+	Network  string
+	Settings DeviceSettings
 }
 
 type kv struct {
@@ -38,6 +43,13 @@ type kv struct {
 	IsDefault bool
 }
 
+// Name of the 'drop-in' directory for configuration files, alongside the configuration file.
+const DROPIN_DIR = "conf.d"
+
+// Prefix for environment variables that override configuration values e.g.
+// UHPPOTED_MQTT_CONNECTION_PASSWORD overrides mqtt.connection.password.
+const ENV_PREFIX = "UHPPOTED"
+
 var INADDR_ANY = netip.AddrFrom4([4]byte{0, 0, 0, 0})
 var BROADCAST_ADDR = netip.AddrFrom4([4]byte{255, 255, 255, 255})
 
@@ -62,16 +74,16 @@ const pretty = `# SYSTEM{{range .system}}
 # OPEN API{{range .openapi}}
 {{if .IsDefault}}# {{end}}{{.Key}} = {{.Value}}{{end}}
 
-# DEVICES{{range $id,$device := .devices}}
-UT0311-L0x.{{$id}}.name = {{$device.Name}}{{if $device.Address}}
-UT0311-L0x.{{$id}}.address = {{$device.Address}}{{end}}
-UT0311-L0x.{{$id}}.door.1 = {{index $device.Doors 0}}
-UT0311-L0x.{{$id}}.door.2 = {{index $device.Doors 1}}
-UT0311-L0x.{{$id}}.door.3 = {{index $device.Doors 2}}
-UT0311-L0x.{{$id}}.door.4 = {{index $device.Doors 3}}
-UT0311-L0x.{{$id}}.timezone = {{$device.TimeZone}}
+# DEVICES{{range $id,$device := .devices}}{{$prefix := prefix $id $device}}
+{{$prefix}}.name = {{$device.Name}}{{if $device.Address}}
+{{$prefix}}.address = {{$device.Address}}{{end}}{{range doors $device}}
+{{$prefix}}.{{.Key}} = {{.Value}}{{end}}
+{{$prefix}}.timezone = {{$device.TimeZone}}{{if $device.Network}}
+{{$prefix}}.network = {{$device.Network}}{{end}}{{range declared $device}}
+{{$prefix}}.{{.Key}} = {{.Value}}{{end}}
 {{else}}
-# Example configuration for UTO311-L04 with serial number 405419896
+# Example configuration for UTO311-L04 with serial number 405419896 (use UT0311-L01, UT0311-L02
+# or UT0311-L04 in place of UT0311-L0x to declare the controller model)
 # UT0311-L0x.405419896.name = D405419896
 # UT0311-L0x.405419896.address = 192.168.1.100:60000
 # UT0311-L0x.405419896.door.1 = Front Door
@@ -79,11 +91,26 @@ UT0311-L0x.{{$id}}.timezone = {{$device.TimeZone}}
 # UT0311-L0x.405419896.door.3 = Garage
 # UT0311-L0x.405419896.door.4 = Workshop
 # UT0311-L0x.405419896.timezone = UTC+2
+# UT0311-L0x.405419896.door.1.mode = controlled
+# UT0311-L0x.405419896.door.1.delay = 5
+# UT0311-L0x.405419896.interlock = 1&2
+# UT0311-L0x.405419896.anti-passback = (1:2);(3:4)
+# UT0311-L0x.405419896.keypads = 1, 2
+# UT0311-L0x.405419896.listener = 192.168.1.100:60001
+# UT0311-L0x.405419896.timeout = 5s
+{{end}}{{if .networks}}
+# NETWORKS{{range $name,$network := .networks}}{{range network $network}}
+network.{{$name}}.{{.Key}} = {{.Value}}{{end}}{{end}}
+{{end}}{{if .zones}}
+# ZONES{{range $zone,$doors := .zones}}
+zone.{{$zone}} = {{range $i,$door := $doors}}{{if $i}}, {{end}}{{$door}}{{end}}{{end}}
 {{end}}`
 
 type Config struct {
 	System      `conf:""`
-	Devices     DeviceMap `conf:"/^UT0311-L0x\\.([0-9]+)\\.(.*)/"`
+	Devices     DeviceMap  `conf:"/^(UT0311-L0[124x])\\.([0-9]+)\\.(.*)/"`
+	Networks    NetworkMap `conf:"/^network\\.([A-Za-z0-9_-]+)\\.((?:bind|broadcast|listen)\\.address)$/"`
+	Zones       ZoneMap    `conf:"/^zone\\.(.+)/"`
 	REST        `conf:"rest"`
 	MQTT        `conf:"mqtt"`
 	AWS         `conf:"aws"`
@@ -93,15 +120,20 @@ type Config struct {
 }
 
 type System struct {
-	BindAddress         *types.BindAddr      `conf:"bind.address"`
-	BroadcastAddress    *types.BroadcastAddr `conf:"broadcast.address"`
-	ListenAddress       *types.ListenAddr    `conf:"listen.address"`
-	Timeout             time.Duration        `conf:"timeout"`
-	HealthCheckInterval time.Duration        `conf:"monitoring.healthcheck.interval"`
-	HealthCheckIdle     time.Duration        `conf:"monitoring.healthcheck.idle"`
-	HealthCheckIgnore   time.Duration        `conf:"monitoring.healthcheck.ignore"`
-	WatchdogInterval    time.Duration        `conf:"monitoring.watchdog.interval"`
-	CardFormat          types.CardFormat     `conf:"card.format"`
+	BindAddress          *types.BindAddr      `conf:"bind.address"`
+	BroadcastAddress     *types.BroadcastAddr `conf:"broadcast.address"`
+	ListenAddress        *types.ListenAddr    `conf:"listen.address"`
+	Timeout              time.Duration        `conf:"timeout"`
+	HealthCheckInterval  time.Duration        `conf:"monitoring.healthcheck.interval"`
+	HealthCheckIdle      time.Duration        `conf:"monitoring.healthcheck.idle"`
+	HealthCheckIgnore    time.Duration        `conf:"monitoring.healthcheck.ignore"`
+	WatchdogInterval     time.Duration        `conf:"monitoring.watchdog.interval"`
+	TimeSyncInterval     time.Duration        `conf:"monitoring.timesync.interval"`
+	TimeSyncThreshold    time.Duration        `conf:"monitoring.timesync.threshold"`
+	TimeSyncHoldoff      time.Duration        `conf:"monitoring.timesync.holdoff"`
+	SchedulerConcurrency int                  `conf:"scheduler.concurrency"`
+	SchedulerInterval    time.Duration        `conf:"scheduler.interval"`
+	CardFormat           types.CardFormat     `conf:"card.format"`
 }
 
 type Lockfile struct {
@@ -114,15 +146,20 @@ func NewConfig() *Config {
 
 	c := Config{
 		System: System{
-			BindAddress:         &bind,
-			BroadcastAddress:    &broadcast,
-			ListenAddress:       &listen,
-			Timeout:             2500 * time.Millisecond,
-			HealthCheckInterval: 15 * time.Second,
-			HealthCheckIdle:     monitoring.IDLE,
-			HealthCheckIgnore:   monitoring.IGNORE,
-			WatchdogInterval:    5 * time.Second,
-			CardFormat:          types.WiegandAny,
+			BindAddress:          &bind,
+			BroadcastAddress:     &broadcast,
+			ListenAddress:        &listen,
+			Timeout:              2500 * time.Millisecond,
+			HealthCheckInterval:  15 * time.Second,
+			HealthCheckIdle:      monitoring.IDLE,
+			HealthCheckIgnore:    monitoring.IGNORE,
+			WatchdogInterval:     5 * time.Second,
+			TimeSyncInterval:     monitoring.SYNC_INTERVAL,
+			TimeSyncThreshold:    monitoring.SYNC_THRESHOLD,
+			TimeSyncHoldoff:      monitoring.SYNC_HOLDOFF,
+			SchedulerConcurrency: scheduler.CONCURRENCY,
+			SchedulerInterval:    scheduler.INTERVAL,
+			CardFormat:           types.WiegandAny,
 		},
 		REST:        *NewREST(),
 		MQTT:        *NewMQTT(),
@@ -131,6 +168,8 @@ func NewConfig() *Config {
 		WildApricot: *NewWildApricot(),
 		OpenAPI:     *NewOpenAPI(),
 		Devices:     make(DeviceMap, 0),
+		Networks:    make(NetworkMap, 0),
+		Zones:       make(ZoneMap, 0),
 	}
 
 	return &c
@@ -141,18 +180,15 @@ func (c *Config) Load(path string) error {
 		return nil
 	}
 
-	f, err := os.Open(path)
-	if err != nil {
+	if _, err := c.load(path); err != nil {
 		return err
 	}
 
-	defer f.Close()
-
-	if err := c.Read(f); err != nil {
-		return err
-	}
+	return c.hmac()
+}
 
-	// generate random 'temporary' HMAC key just to avoid defaulting to ""
+// Generates a random 'temporary' HMAC key just to avoid defaulting to "".
+func (c *Config) hmac() error {
 	if c.MQTT.HMAC.Key == "" {
 		hmac := make([]byte, 16)
 		if _, err := rand.Read(hmac); err != nil {
@@ -165,48 +201,105 @@ func (c *Config) Load(path string) error {
 	return nil
 }
 
+// Reads the configuration file (expanding any 'include' directives) followed by the *.conf,
+// *.json and *.toml files in the conf.d 'drop-in' directory alongside the configuration file, in
+// lexical order, and then applies any UHPPOTED_<SECTION>_<KEY> environment variable overrides.
+// Returns the list of files read.
+func (c *Config) load(path string) ([]string, error) {
+	d, err := document(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Files(), d.Unmarshal(c)
+}
+
+func document(path string) (*conf.Document, error) {
+	d := conf.NewDocument()
+
+	if err := d.Load(path); err != nil {
+		return nil, err
+	}
+
+	if err := d.LoadDir(filepath.Join(filepath.Dir(path), DROPIN_DIR)); err != nil {
+		return nil, err
+	}
+
+	d.Environment(ENV_PREFIX, NewConfig())
+
+	return d, nil
+}
+
 func (c *Config) Validate() error {
 	if c != nil {
 		// validate bind.address port
 		port := c.System.BindAddress.Port()
 		if port == uint16(60000) {
-			return fmt.Errorf("port %v is not a valid port for bind.address", port)
+			return invalid("bind.address", "port %v is not a valid port for bind.address", port)
 		} else if port != 0 && port == c.System.BroadcastAddress.Port() {
-			return fmt.Errorf("bind.address port (%v) must not be the same as the broadcast.address port", port)
+			return invalid("bind.address", "bind.address port (%v) must not be the same as the broadcast.address port", port)
 		}
 
 		if port != 0 && port == c.System.ListenAddress.Port() {
-			return fmt.Errorf("bind.address port (%v) must not be the same as the listen.address port", port)
+			return invalid("bind.address", "bind.address port (%v) must not be the same as the listen.address port", port)
 		}
 
 		// validate broadcast.address port
 		if c.System.BroadcastAddress.Port() == 0 {
-			return fmt.Errorf("port %v is not a valid port for broadcast.address", c.System.BroadcastAddress.Port())
+			return invalid("broadcast.address", "port %v is not a valid port for broadcast.address", c.System.BroadcastAddress.Port())
 		}
 
 		// validate listen.address port
 		if c.System.ListenAddress.Port() == 0 {
-			return fmt.Errorf("port %v is not a valid port for listen.address", c.System.ListenAddress.Port())
+			return invalid("listen.address", "port %v is not a valid port for listen.address", c.System.ListenAddress.Port())
+		}
+
+		// check door names and settings against the controller models
+		for _, id := range slices.Sorted(maps.Keys(c.Devices)) {
+			if device := c.Devices[id]; device != nil {
+				if err := device.validate(id); err != nil {
+					return err
+				}
+			}
 		}
 
 		// check for duplicate doors
 		doors := make(map[string]bool)
-		for _, device := range c.Devices {
-			for _, door := range device.Doors {
-				d := strings.ReplaceAll(strings.ToLower(door), " ", "")
+		for _, id := range slices.Sorted(maps.Keys(c.Devices)) {
+			if device := c.Devices[id]; device != nil {
+				for i, door := range device.Doors {
+					d := strings.ReplaceAll(strings.ToLower(door), " ", "")
 
-				if d != "" && doors[d] {
-					return fmt.Errorf("door '%s' is defined more than once in configuration", door)
-				}
+					if d != "" && doors[d] {
+						return invalid(fmt.Sprintf("%v.door.%v", device.prefix(id), i+1), "door '%s' is defined more than once in configuration", door)
+					}
 
-				doors[d] = true
+					doors[d] = true
+				}
 			}
 		}
+
+		if err := c.Networks.validate(c.Devices, c.System.ListenAddress); err != nil {
+			return err
+		}
+
+		if err := c.Zones.validate(c.Devices); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// Returns a validation error for a configuration key.
+func invalid(key string, format string, args ...any) error {
+	return &conf.Error{
+		Key: key,
+		Err: fmt.Errorf(format, args...),
+	}
+}
+
+// Reads a configuration in conf, JSON or TOML format (sniffed from the content).
 func (c *Config) Read(r io.Reader) error {
 	bytes, err := io.ReadAll(r)
 	if err != nil {
@@ -237,6 +330,8 @@ func (c *Config) Write(w io.Writer) error {
 		"wildapricot": listify("wild-apricot.", &c.WildApricot),
 		"openapi":     listify("openapi.", &c.OpenAPI),
 		"devices":     c.Devices,
+		"networks":    c.Networks,
+		"zones":       c.Zones,
 	}
 
 	for k, l := range defv {
@@ -248,7 +343,14 @@ func (c *Config) Write(w io.Writer) error {
 		}
 	}
 
-	return template.Must(template.New("uhppoted.conf").Parse(pretty)).Execute(w, config)
+	functions := template.FuncMap{
+		"prefix":   func(id uint32, d *Device) string { return d.prefix(id) },
+		"doors":    func(d *Device) []kv { return d.doors() },
+		"declared": func(d *Device) []kv { return d.Settings.declared() },
+		"network":  func(n *Network) []kv { return n.settings() },
+	}
+
+	return template.Must(template.New("uhppoted.conf").Funcs(functions).Parse(pretty)).Execute(w, config)
 }
 
 func listify(parent string, s any) []kv {
@@ -308,21 +410,34 @@ func (f DeviceMap) MarshalConf(tag string) ([]byte, error) {
 
 	if len(f) > 0 {
 		fmt.Fprintf(&s, "# DEVICES\n")
-		for id, device := range f {
-			fmt.Fprintf(&s, "UT0311-L0x.%d.name = %s\n", id, device.Name)
+		for _, id := range slices.Sorted(maps.Keys(f)) {
+			device := f[id]
+			prefix := device.prefix(id)
+
+			fmt.Fprintf(&s, "%s.name = %s\n", prefix, device.Name)
 
 			if device.Address.IsValid() {
 				if device.Protocol == "udp" {
-					fmt.Fprintf(&s, "UT0311-L0x.%d.address = udp:%s\n", id, device.Address)
+					fmt.Fprintf(&s, "%s.address = udp:%s\n", prefix, device.Address)
 				} else if device.Protocol == "tcp" {
-					fmt.Fprintf(&s, "UT0311-L0x.%d.address = tcp:%s\n", id, device.Address)
+					fmt.Fprintf(&s, "%s.address = tcp:%s\n", prefix, device.Address)
 				} else {
-					fmt.Fprintf(&s, "UT0311-L0x.%d.address = %s\n", id, device.Address)
+					fmt.Fprintf(&s, "%s.address = %s\n", prefix, device.Address)
 				}
 			}
 
 			for d, door := range device.Doors {
-				fmt.Fprintf(&s, "UT0311-L0x.%d.door.%d = %s\n", id, d+1, door)
+				if d < device.DoorCount() {
+					fmt.Fprintf(&s, "%s.door.%d = %s\n", prefix, d+1, door)
+				}
+			}
+
+			if device.Network != "" {
+				fmt.Fprintf(&s, "%s.network = %s\n", prefix, device.Network)
+			}
+
+			for _, v := range device.Settings.declared() {
+				fmt.Fprintf(&s, "%s.%s = %s\n", prefix, v.Key, v.Value)
 			}
 			fmt.Fprintf(&s, "\n")
 		}
@@ -346,10 +461,16 @@ func (f *DeviceMap) UnmarshalConf(tag string, values map[string]string) (any, er
 	for key, value := range values {
 		match := re.FindStringSubmatch(key)
 
-		if len(match) > 1 {
-			id, err := strconv.ParseUint(match[1], 10, 32)
+		if len(match) > 2 {
+			// ... <model>.<controller>.<setting> or (for the legacy tag) <controller>.<setting>
+			model, controller, setting := "", match[1], match[2]
+			if len(match) > 3 {
+				model, controller, setting = match[1], match[2], match[3]
+			}
+
+			id, err := strconv.ParseUint(controller, 10, 32)
 			if err != nil {
-				return f, fmt.Errorf("invalid 'testMap' key %s: %v", key, err)
+				return f, &conf.Error{Key: key, Err: fmt.Errorf("invalid controller ID (%v)", err)}
 			}
 
 			d, ok := (*f)[uint32(id)]
@@ -361,13 +482,21 @@ func (f *DeviceMap) UnmarshalConf(tag string, values map[string]string) (any, er
 				(*f)[uint32(id)] = d
 			}
 
-			switch match[2] {
+			if model != "" && model != UT0311_L0x {
+				if d.Model != "" && d.Model != model {
+					return f, &conf.Error{Key: key, Err: fmt.Errorf("device %v, conflicting models '%v' and '%v'", id, d.Model, model)}
+				}
+
+				d.Model = model
+			}
+
+			switch setting {
 			case "name":
 				d.Name = value
 
 			case "address":
 				if address, protocol, err := resolve(value); err != nil {
-					return f, fmt.Errorf("device %v, invalid address '%s': %v", id, value, err)
+					return f, &conf.Error{Key: key, Err: fmt.Errorf("device %v, invalid address '%s': %v", id, value, err)}
 				} else {
 					d.Address = address
 					d.Protocol = protocol
@@ -387,6 +516,14 @@ func (f *DeviceMap) UnmarshalConf(tag string, values map[string]string) (any, er
 
 			case "timezone":
 				d.TimeZone = value
+
+			case "network":
+				d.Network = strings.TrimSpace(value)
+
+			default:
+				if err := d.Settings.set(setting, value); err != nil {
+					return f, &conf.Error{Key: key, Err: fmt.Errorf("device %v, %v", id, err)}
+				}
 			}
 		}
 	}
@@ -395,7 +532,9 @@ func (f *DeviceMap) UnmarshalConf(tag string, values map[string]string) (any, er
 }
 
 /*
- * Returns a list of uhppote.Device sorted by controller ID (required for ACLs)
+ * Returns a list of uhppote.Device sorted by controller ID (required for ACLs), with the
+ * door list trimmed to the number of doors of the controller model. uhppote.Device has no
+ * model field - use Models() for the configured controller models.
  *
  */
 func (f DeviceMap) ToControllers() []uhppote.Device {
@@ -418,6 +557,10 @@ func (f DeviceMap) ToControllers() []uhppote.Device {
 			doors := v.Doors
 			timezone := time.Local
 
+			if len(doors) > v.DoorCount() {
+				doors = doors[:v.DoorCount()]
+			}
+
 			if v.TimeZone != "" {
 				if tz, err := time.LoadLocation(v.TimeZone); err == nil && tz != nil {
 					timezone = tz
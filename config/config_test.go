@@ -3,8 +3,11 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,6 +26,11 @@ monitoring.healthcheck.interval = 31s
 monitoring.healthcheck.idle = 67s
 monitoring.healthcheck.ignore = 97s
 monitoring.watchdog.interval = 23s
+monitoring.timesync.interval = 13m
+monitoring.timesync.threshold = 7s
+monitoring.timesync.holdoff = 2h
+scheduler.concurrency = 2
+scheduler.interval = 50ms
 
 # MQTT
 mqtt.connection.broker = tls://127.0.0.63:8887
@@ -73,15 +81,20 @@ func TestDefaultConfig(t *testing.T) {
 
 	expected := Config{
 		System: System{
-			BindAddress:         &bind,
-			BroadcastAddress:    &broadcast,
-			ListenAddress:       &listen,
-			Timeout:             2500 * time.Millisecond,
-			HealthCheckInterval: 15 * time.Second,
-			HealthCheckIdle:     60 * time.Second,
-			HealthCheckIgnore:   5 * time.Minute,
-			WatchdogInterval:    5 * time.Second,
-			CardFormat:          types.WiegandAny,
+			BindAddress:          &bind,
+			BroadcastAddress:     &broadcast,
+			ListenAddress:        &listen,
+			Timeout:              2500 * time.Millisecond,
+			HealthCheckInterval:  15 * time.Second,
+			HealthCheckIdle:      60 * time.Second,
+			HealthCheckIgnore:    5 * time.Minute,
+			WatchdogInterval:     5 * time.Second,
+			TimeSyncInterval:     5 * time.Minute,
+			TimeSyncThreshold:    5 * time.Second,
+			TimeSyncHoldoff:      time.Hour,
+			SchedulerConcurrency: 1,
+			SchedulerInterval:    0,
+			CardFormat:           types.WiegandAny,
 		},
 
 		MQTT: MQTT{
@@ -157,15 +170,20 @@ func TestConfigUnmarshal(t *testing.T) {
 
 	expected := Config{
 		System: System{
-			BindAddress:         &bind,
-			BroadcastAddress:    &broadcast,
-			ListenAddress:       &listen,
-			Timeout:             3750 * time.Millisecond,
-			HealthCheckInterval: 31 * time.Second,
-			HealthCheckIdle:     67 * time.Second,
-			HealthCheckIgnore:   97 * time.Second,
-			WatchdogInterval:    23 * time.Second,
-			CardFormat:          types.Wiegand26,
+			BindAddress:          &bind,
+			BroadcastAddress:     &broadcast,
+			ListenAddress:        &listen,
+			Timeout:              3750 * time.Millisecond,
+			HealthCheckInterval:  31 * time.Second,
+			HealthCheckIdle:      67 * time.Second,
+			HealthCheckIgnore:    97 * time.Second,
+			WatchdogInterval:     23 * time.Second,
+			TimeSyncInterval:     13 * time.Minute,
+			TimeSyncThreshold:    7 * time.Second,
+			TimeSyncHoldoff:      2 * time.Hour,
+			SchedulerConcurrency: 2,
+			SchedulerInterval:    50 * time.Millisecond,
+			CardFormat:           types.Wiegand26,
 		},
 
 		MQTT: MQTT{
@@ -370,6 +388,11 @@ func TestDefaultConfigWrite(t *testing.T) {
 ; monitoring.healthcheck.idle = 1m0s
 ; monitoring.healthcheck.ignore = 5m0s
 ; monitoring.watchdog.interval = 5s
+; monitoring.timesync.interval = 5m0s
+; monitoring.timesync.threshold = 5s
+; monitoring.timesync.holdoff = 1h0m0s
+; scheduler.concurrency = 1
+; scheduler.interval = 0s
 ; card.format = any
 
 # REST
@@ -509,7 +532,8 @@ func TestDefaultConfigWrite(t *testing.T) {
 # openapi.directory = ./openapi
 
 # DEVICES
-# Example configuration for UTO311-L04 with serial number 405419896
+# Example configuration for UTO311-L04 with serial number 405419896 (use UT0311-L01, UT0311-L02
+# or UT0311-L04 in place of UT0311-L0x to declare the controller model)
 # UT0311-L0x.405419896.name = D405419896
 # UT0311-L0x.405419896.address = 192.168.1.100:60000
 # UT0311-L0x.405419896.door.1 = Front Door
@@ -517,6 +541,13 @@ func TestDefaultConfigWrite(t *testing.T) {
 # UT0311-L0x.405419896.door.3 = Garage
 # UT0311-L0x.405419896.door.4 = Workshop
 # UT0311-L0x.405419896.timezone = UTC+2
+# UT0311-L0x.405419896.door.1.mode = controlled
+# UT0311-L0x.405419896.door.1.delay = 5
+# UT0311-L0x.405419896.interlock = 1&2
+# UT0311-L0x.405419896.anti-passback = (1:2);(3:4)
+# UT0311-L0x.405419896.keypads = 1, 2
+# UT0311-L0x.405419896.listener = 192.168.1.100:60001
+# UT0311-L0x.405419896.timeout = 5s
 `, bind.String(), broadcast.String(), listen.String(),
 		restUsers, restGroups, restHOTP,
 		mqttBrokerCertificate, mqttClientCertificate, mqttClientKey, eventIDs, mqttUsers, mqttGroups, mqttCards, hotpSecrets, hotpCounters, rsaKeyDir,
@@ -568,6 +599,11 @@ timeout = %[4]v
 ; monitoring.healthcheck.idle = 1m0s
 ; monitoring.healthcheck.ignore = 5m0s
 ; monitoring.watchdog.interval = 5s
+; monitoring.timesync.interval = 5m0s
+; monitoring.timesync.threshold = 5s
+; monitoring.timesync.holdoff = 1h0m0s
+; scheduler.concurrency = 1
+; scheduler.interval = 0s
 ; card.format = any
 
 # REST
@@ -805,7 +841,7 @@ listen.address = 192.168.1.100:60001
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	expected := fmt.Errorf("bind.address port (12345) must not be the same as the broadcast.address port")
+	expected := fmt.Errorf("bind.address: bind.address port (12345) must not be the same as the broadcast.address port")
 
 	err := config.Validate()
 	if err == nil || err.Error() != expected.Error() {
@@ -824,7 +860,7 @@ listen.address = 192.168.1.100:60001
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	expected := fmt.Errorf("bind.address port (60001) must not be the same as the listen.address port")
+	expected := fmt.Errorf("bind.address: bind.address port (60001) must not be the same as the listen.address port")
 
 	err := config.Validate()
 	if err == nil || err.Error() != expected.Error() {
@@ -894,7 +930,7 @@ UT0311-L0x.405419896.timezone = France/Paris
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	expected := fmt.Errorf("door 'Front Door' is defined more than once in configuration")
+	expected := fmt.Errorf("UT0311-L0x.405419896.door.4: door 'Front Door' is defined more than once in configuration")
 
 	err := config.Validate()
 	if err == nil || err.Error() != expected.Error() {
@@ -922,3 +958,41 @@ UT0311-L0x.405419896.timezone = France/Paris
 		t.Errorf("Unexpected error: %v", err)
 	}
 }
+
+func TestConfigLoadWithIncludesAndDropIns(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"uhppoted.conf":      "# SYSTEM\ntimeout = 5s\n\ninclude = devices.conf\n",
+		"devices.conf":       "UT0311-L0x.405419896.door.1 = Front Door\nUT0311-L0x.405419896.door.2 = Side Door\n",
+		"conf.d/mqtt.conf":   "mqtt.connection.client.ID = uhppoted-test\n",
+		"conf.d/zzzzzz.conf": "UT0311-L0x.405419896.door.2 = Back Door\n",
+	}
+
+	for file, content := range files {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0755)
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatalf("Error writing test configuration (%v)", err)
+		}
+	}
+
+	config := NewConfig()
+	if err := config.Load(filepath.Join(dir, "uhppoted.conf")); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if config.Timeout != 5*time.Second || config.MQTT.Connection.ClientID != "uhppoted-test" {
+		t.Errorf("Incorrect configuration %+v", config)
+	}
+
+	if doors := config.Devices[405419896].Doors; doors[0] != "Front Door" || doors[1] != "Back Door" {
+		t.Errorf("Incorrect doors - expected:%v, got:%v", []string{"Front Door", "Back Door"}, doors)
+	}
+
+	// ... invalid device address
+	os.WriteFile(filepath.Join(dir, "devices.conf"), []byte("\nUT0311-L0x.405419896.address = 192.168.1.300\n"), 0644)
+
+	expected := filepath.Join(dir, "devices.conf") + ":2: UT0311-L0x.405419896.address:"
+	if err := NewConfig().Load(filepath.Join(dir, "uhppoted.conf")); err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("Expected error with file and line (%v), got %v", expected, err)
+	}
+}
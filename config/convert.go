@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"github.com/uhppoted/uhppoted-lib/encoding/conf"
+)
+
+// Writes a configuration file (along with any included files) in another format e.g. to convert
+// an existing uhppoted.conf to JSON or TOML. Boolean and numeric settings are written as JSON/TOML
+// booleans and numbers, everything else (including durations and addresses) as strings.
+func Convert(path string, w io.Writer, format conf.Format) error {
+	d := conf.NewDocument()
+	if err := d.Load(path); err != nil {
+		return err
+	}
+
+	schema := newSchema()
+	values := map[string]any{}
+
+	for key, value := range d.Values() {
+		values[key] = typed(schema.keys[key], value)
+	}
+
+	return conf.Encode(w, format, values)
+}
+
+// Returns the value as a bool, integer or float if the Config field is a bool, integer or float
+// (and not e.g. a time.Duration) and the value is a valid literal. Otherwise returns the value as
+// is, since it may be an ${ENV_VAR} reference or a file: secret.
+func typed(field any, value string) any {
+	if _, ok := field.(fmt.Stringer); ok || field == nil {
+		return value
+	}
+
+	switch reflect.ValueOf(field).Kind() {
+	case reflect.Bool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return v
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	}
+
+	return value
+}
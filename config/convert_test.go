@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uhppoted/uhppoted-lib/encoding/conf"
+)
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uhppoted.conf")
+	if err := os.WriteFile(path, configuration, 0644); err != nil {
+		t.Fatalf("Error writing test configuration (%v)", err)
+	}
+
+	expected := NewConfig()
+	if err := expected.Read(bytes.NewReader(configuration)); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	for _, format := range []conf.Format{conf.JSON, conf.TOML} {
+		var b bytes.Buffer
+		if err := Convert(path, &b, format); err != nil {
+			t.Fatalf("Unexpected error converting to %v (%v)", format, err)
+		}
+
+		if format == conf.JSON && !strings.Contains(b.String(), `"concurrency": 2`) {
+			t.Errorf("Expected numeric JSON value for scheduler.concurrency\n%v", b.String())
+		}
+
+		// ... read (sniffed)
+		config := NewConfig()
+		if err := config.Read(bytes.NewReader(b.Bytes())); err != nil {
+			t.Fatalf("Unexpected error reading %v (%v)", format, err)
+		} else if !reflect.DeepEqual(config, expected) {
+			t.Errorf("Incorrect %v configuration\n   expected:%+v\n   got:     %+v", format, expected, config)
+		}
+
+		// ... load (by extension)
+		file := filepath.Join(dir, "uhppoted."+string(format))
+		if err := os.WriteFile(file, b.Bytes(), 0644); err != nil {
+			t.Fatalf("Error writing %v configuration (%v)", format, err)
+		}
+
+		config = NewConfig()
+		if err := config.Load(file); err != nil {
+			t.Fatalf("Unexpected error loading %v (%v)", format, err)
+		}
+
+		config.MQTT.HMAC.Key = "" // ... randomly generated by Load
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("Incorrect %v configuration\n   expected:%+v\n   got:     %+v", format, expected, config)
+		}
+	}
+}
+
+func TestLoadStrictTOML(t *testing.T) {
+	configuration := `# SYSTEM
+timeout = "5s"
+
+[mqtt.conection]
+broker = "tls://127.0.0.1:8883"
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uhppoted.toml")
+	if err := os.WriteFile(path, []byte(configuration), 0644); err != nil {
+		t.Fatalf("Error writing test configuration (%v)", err)
+	}
+
+	expected := path + ":5: mqtt.conection.broker: unknown key (did you mean 'mqtt.connection.broker'?)"
+	if err := NewConfig().LoadStrict(path); err == nil || err.Error() != expected {
+		t.Errorf("Incorrect error\n   expected:%v\n   got:     %v", expected, err)
+	}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Diff is the structured difference between two configurations: the changed settings in each
+// section (system, rest, mqtt, aws, httpd, wild-apricot and openapi) and the added, removed
+// and changed devices, networks and zones.
+type Diff struct {
+	Sections map[string][]Change `json:"sections,omitempty"`
+	Devices  Changes[uint32]     `json:"devices"`
+	Networks Changes[string]     `json:"networks"`
+	Zones    Changes[string]     `json:"zones"`
+}
+
+// Change is a changed setting. The values are formatted as in the conf file and may include
+// credentials (e.g. the MQTT HMAC key), so should not be logged as is.
+type Change struct {
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Changes lists the added, removed and changed items (devices, networks or zones), ordered
+// by key.
+type Changes[K cmp.Ordered] struct {
+	Added   []K `json:"added,omitempty"`
+	Removed []K `json:"removed,omitempty"`
+	Changed []K `json:"changed,omitempty"`
+}
+
+// Compares two configurations.
+func Compare(old, new *Config) Diff {
+	diff := Diff{
+		Sections: map[string][]Change{},
+	}
+
+	p := sections(old)
+	q := sections(new)
+	for _, section := range slices.Sorted(maps.Keys(q)) {
+		if changes := compareSection(p[section], q[section]); len(changes) > 0 {
+			diff.Sections[section] = changes
+		}
+	}
+
+	diff.Devices = compare(old.Devices, new.Devices, func(p, q *Device) bool {
+		return reflect.DeepEqual(p, q)
+	})
+
+	diff.Networks = compare(old.Networks, new.Networks, func(p, q *Network) bool {
+		return reflect.DeepEqual(p, q)
+	})
+
+	diff.Zones = compare(old.Zones, new.Zones, func(p, q []string) bool {
+		return slices.Equal(p, q)
+	})
+
+	return diff
+}
+
+// Returns true if there are no differences.
+func (d Diff) IsEmpty() bool {
+	return len(d.Sections) == 0 && d.Devices.IsEmpty() && d.Networks.IsEmpty() && d.Zones.IsEmpty()
+}
+
+func (c Changes[K]) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+func sections(c *Config) map[string][]kv {
+	return map[string][]kv{
+		"system":       listify("", &c.System),
+		"rest":         listify("rest.", &c.REST),
+		"mqtt":         listify("mqtt.", &c.MQTT),
+		"aws":          listify("aws.", &c.AWS),
+		"httpd":        listify("httpd.", &c.HTTPD),
+		"wild-apricot": listify("wild-apricot.", &c.WildApricot),
+		"openapi":      listify("openapi.", &c.OpenAPI),
+	}
+}
+
+func compareSection(p, q []kv) []Change {
+	changes := []Change{}
+	values := map[string]any{}
+
+	for _, v := range p {
+		values[v.Key] = v.Value
+	}
+
+	for _, v := range q {
+		if old, ok := values[v.Key]; !ok || old != v.Value {
+			changes = append(changes, Change{
+				Key: v.Key,
+				Old: fmt.Sprintf("%v", old),
+				New: fmt.Sprintf("%v", v.Value),
+			})
+		}
+	}
+
+	return changes
+}
+
+func compare[K cmp.Ordered, V any](p, q map[K]V, eq func(V, V) bool) Changes[K] {
+	changes := Changes[K]{}
+
+	for _, k := range slices.Sorted(maps.Keys(q)) {
+		if v, ok := p[k]; !ok {
+			changes.Added = append(changes.Added, k)
+		} else if !eq(v, q[k]) {
+			changes.Changed = append(changes.Changed, k)
+		}
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(p)) {
+		if _, ok := q[k]; !ok {
+			changes.Removed = append(changes.Removed, k)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/uhppoted/uhppoted-lib/encoding/conf"
+	lib "github.com/uhppoted/uhppoted-lib/os"
+)
+
+// Editor updates the settings in an existing configuration file while preserving comments,
+// ordering and unknown keys, unlike Write which regenerates the whole file.
+type Editor struct {
+	editor *conf.Editor
+	schema schema
+}
+
+func NewEditor(r io.Reader) (*Editor, error) {
+	e, err := conf.NewEditor(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Editor{
+		editor: e,
+		schema: newSchema(),
+	}, nil
+}
+
+// Reads a configuration file, applies the edits and replaces the file with the updated
+// configuration. The file is left unchanged if the edit function returns an error.
+func Edit(path string, f func(e *Editor) error) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	e, err := NewEditor(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	if err := f(e); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmpfile := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err := os.WriteFile(tmpfile, e.Bytes(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	return lib.Rename(tmpfile, path)
+}
+
+// Returns the (last defined) value for a key.
+func (e *Editor) Get(key string) (string, bool) {
+	return e.editor.Get(key)
+}
+
+// Sets the value for a key, updating the existing line if the key is already defined. Returns
+// an error if the key is not a known configuration key or the value is invalid.
+func (e *Editor) Set(key, value string) error {
+	if message := e.schema.check(key, value); message != "" {
+		return fmt.Errorf("%v: %v", key, message)
+	}
+
+	e.editor.Set(key, value)
+
+	return nil
+}
+
+// Deletes all definitions of a key, returning false if the key is not defined.
+func (e *Editor) Delete(key string) bool {
+	return e.editor.Delete(key)
+}
+
+// Adds or updates the settings for a controller. The settings for an existing controller are
+// updated in place, otherwise the controller is added as a block after the last controller (or
+// at the end of the file if there are no controllers). A controller with a changed model is
+// replaced with a new block for the model.
+func (e *Editor) SetDevice(id uint32, device Device) error {
+	prefix := device.prefix(id) + "."
+	settings := device.settings()
+
+	if err := device.validate(id); err != nil {
+		return err
+	}
+
+	for _, v := range settings {
+		if v.Value != "" {
+			if message := e.schema.check(prefix+v.Key, v.Value.(string)); message != "" {
+				return fmt.Errorf("%v%v: %v", prefix, v.Key, message)
+			}
+		}
+	}
+
+	if e.HasDevice(id) && !e.hasPrefix(prefix) {
+		e.DeleteDevice(id)
+	}
+
+	if !e.HasDevice(id) {
+		lines := []string{}
+		for _, v := range settings {
+			if v.Value != "" || device.required(v.Key) {
+				lines = append(lines, strings.TrimSpace(fmt.Sprintf("%v%v = %v", prefix, v.Key, v.Value)))
+			}
+		}
+
+		e.editor.InsertBlock(func(key string) bool { return deviceKey.MatchString(key) }, lines...)
+
+		return nil
+	}
+
+	for _, v := range settings {
+		if v.Value != "" || device.required(v.Key) {
+			e.editor.Set(prefix+v.Key, v.Value.(string))
+		} else {
+			e.editor.Delete(prefix + v.Key)
+		}
+	}
+
+	return nil
+}
+
+// Removes all the settings for a controller, returning false if the controller is not defined.
+func (e *Editor) DeleteDevice(id uint32) bool {
+	return e.editor.DeleteFunc(func(key string) bool { return isDeviceKey(key, id) }) > 0
+}
+
+// Returns true if the configuration has settings for the controller.
+func (e *Editor) HasDevice(id uint32) bool {
+	for _, key := range e.editor.Keys() {
+		if isDeviceKey(key, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Editor) hasPrefix(prefix string) bool {
+	for _, key := range e.editor.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the edited configuration file.
+func (e *Editor) Bytes() []byte {
+	return e.editor.Bytes()
+}
+
+func (e *Editor) WriteTo(w io.Writer) (int64, error) {
+	return e.editor.WriteTo(w)
+}
+
+// Returns the conf settings for a device, formatted as for the configuration file.
+func (d Device) settings() []kv {
+	address := ""
+	if d.Address.IsValid() {
+		switch d.Protocol {
+		case "udp", "tcp":
+			address = fmt.Sprintf("%v:%v", d.Protocol, d.Address)
+		default:
+			address = fmt.Sprintf("%v", d.Address)
+		}
+	}
+
+	settings := []kv{
+		{"name", d.Name, false},
+		{"address", address, false},
+	}
+
+	for i := range 4 {
+		door := ""
+		if i < len(d.Doors) && i < d.DoorCount() {
+			door = d.Doors[i]
+		}
+
+		settings = append(settings, kv{fmt.Sprintf("door.%v", i+1), door, false})
+	}
+
+	settings = append(settings, kv{"timezone", d.TimeZone, false})
+	settings = append(settings, kv{"network", d.Network, false})
+
+	return append(settings, d.Settings.settings()...)
+}
+
+// Returns true for the settings that are written even if empty i.e. the door names for the
+// doors of the device model.
+func (d Device) required(key string) bool {
+	for _, door := range d.doors() {
+		if door.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isDeviceKey(key string, id uint32) bool {
+	match := deviceKey.FindStringSubmatch(key)
+
+	return match != nil && match[2] == fmt.Sprintf("%v", id)
+}
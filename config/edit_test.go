@@ -0,0 +1,144 @@
+package config
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+const editable = `# SYSTEM
+bind.address = 192.168.1.100:54321
+; timeout = 2.5s
+
+# local settings
+my.custom.setting = 42
+
+# DEVICES
+UT0311-L0x.405419896.name = Alpha
+UT0311-L0x.405419896.address = 192.168.1.100:60000
+UT0311-L0x.405419896.door.1 = Front Door   # main entrance
+UT0311-L0x.405419896.door.2 = Side Door
+UT0311-L0x.405419896.door.3 =
+UT0311-L0x.405419896.door.4 =
+
+UT0311-L0x.303986753.name = Beta
+UT0311-L0x.303986753.door.1 = Back Door
+UT0311-L0x.303986753.door.2 =
+UT0311-L0x.303986753.door.3 =
+UT0311-L0x.303986753.door.4 =
+`
+
+func TestEdit(t *testing.T) {
+	expected := `# SYSTEM
+bind.address = 192.168.1.100:54321
+; timeout = 2.5s
+timeout = 5s
+
+# local settings
+my.custom.setting = 42
+
+# DEVICES
+UT0311-L0x.405419896.name = Alpha
+UT0311-L0x.405419896.address = 192.168.1.100:60000
+UT0311-L0x.405419896.door.1 = Front Door   # main entrance
+UT0311-L0x.405419896.door.2 = Side Door
+UT0311-L0x.405419896.door.3 =
+UT0311-L0x.405419896.door.4 =
+
+UT0311-L0x.201020304.name = Gamma
+UT0311-L0x.201020304.address = tcp:192.168.1.101
+UT0311-L0x.201020304.door.1 = Gate
+UT0311-L0x.201020304.door.2 =
+UT0311-L0x.201020304.door.3 =
+UT0311-L0x.201020304.door.4 =
+UT0311-L0x.201020304.timezone = UTC+2
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uhppoted.conf")
+	if err := os.WriteFile(path, []byte(editable), 0600); err != nil {
+		t.Fatalf("Error writing test configuration (%v)", err)
+	}
+
+	err := Edit(path, func(e *Editor) error {
+		if err := e.Set("timeout", "5s"); err != nil {
+			return err
+		}
+
+		if !e.DeleteDevice(303986753) {
+			t.Errorf("Expected DeleteDevice to return true for existing controller")
+		}
+
+		return e.SetDevice(201020304, Device{
+			Name:     "Gamma",
+			Address:  types.ControllerAddrFrom(netip.MustParseAddr("192.168.1.101"), 60000),
+			Protocol: "tcp",
+			Doors:    []string{"Gate", "", "", ""},
+			TimeZone: "UTC+2",
+		})
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if b, err := os.ReadFile(path); err != nil {
+		t.Fatalf("Error reading edited configuration (%v)", err)
+	} else if string(b) != expected {
+		t.Errorf("Incorrect edited configuration\n   expected:%v\n   got:     %v", expected, string(b))
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Edited configuration file mode not preserved (%v)", info.Mode())
+	}
+}
+
+func TestEditSetDevice(t *testing.T) {
+	expected := strings.Replace(editable,
+		"UT0311-L0x.405419896.name = Alpha\nUT0311-L0x.405419896.address = 192.168.1.100:60000\n",
+		"UT0311-L0x.405419896.name = Alpha\n", 1)
+	expected = strings.Replace(expected, "door.2 = Side Door", "door.2 = Garage", 1)
+
+	e, _ := NewEditor(strings.NewReader(editable))
+
+	err := e.SetDevice(405419896, Device{
+		Name:  "Alpha",
+		Doors: []string{"Front Door   # main entrance", "Garage", "", ""},
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if s := string(e.Bytes()); s != expected {
+		t.Errorf("Incorrect edited configuration\n   expected:%v\n   got:     %v", expected, s)
+	}
+}
+
+func TestEditWithInvalidValues(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uhppoted.conf")
+	if err := os.WriteFile(path, []byte(editable), 0644); err != nil {
+		t.Fatalf("Error writing test configuration (%v)", err)
+	}
+
+	tests := []func(e *Editor) error{
+		func(e *Editor) error { return e.Set("timout", "5s") },
+		func(e *Editor) error { return e.Set("timeout", "5x") },
+		func(e *Editor) error { return e.SetDevice(405419896, Device{TimeZone: "Nowhere/Atlantis"}) },
+	}
+
+	for _, f := range tests {
+		if err := Edit(path, f); err == nil {
+			t.Errorf("Expected error editing configuration")
+		}
+	}
+
+	if b, _ := os.ReadFile(path); string(b) != editable {
+		t.Errorf("Configuration modified by failed edits\n%v", string(b))
+	}
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uhppoted/uhppoted-lib/encoding/conf"
+)
+
+// Diagnostic is a problem found by the strict validation of a configuration file.
+type Diagnostic struct {
+	conf.Position
+	Key     string
+	Message string
+}
+
+// Diagnostics is the list of problems found by the strict validation of a configuration file,
+// ordered by file and line number.
+type Diagnostics []Diagnostic
+
+// Controller settings key i.e. <model>.<controller>.<setting>
+var deviceKey = regexp.MustCompile(`^(UT0311-L0[124x])\.([0-9]+)\.(.*)`)
+
+// Valid controller settings i.e. <model>.<controller>.<setting>
+var deviceKeys = []string{
+	"name",
+	"address",
+	"door.1",
+	"door.2",
+	"door.3",
+	"door.4",
+	"timezone",
+	"network",
+	"door.1.mode",
+	"door.1.delay",
+	"door.2.mode",
+	"door.2.delay",
+	"door.3.mode",
+	"door.3.delay",
+	"door.4.mode",
+	"door.4.delay",
+	"interlock",
+	"anti-passback",
+	"keypads",
+	"card.format",
+	"listener",
+	"listener.interval",
+	"timeout",
+}
+
+// Loads the configuration as for Load, but fails with Diagnostics for unknown keys (with 'did
+// you mean' suggestions), malformed lines, invalid values and validation errors rather than
+// silently ignoring them.
+func (c *Config) LoadStrict(path string) error {
+	d, err := document(path)
+	if err != nil {
+		return err
+	}
+
+	if diagnostics := lint(d); len(diagnostics) > 0 {
+		return diagnostics
+	}
+
+	if err := d.Unmarshal(c); err != nil {
+		return err
+	}
+
+	if err := c.hmac(); err != nil {
+		return err
+	}
+
+	if err := c.Validate(); err != nil {
+		return Diagnostics{locate(d, err)}
+	}
+
+	return nil
+}
+
+func (d Diagnostic) String() string {
+	if d.Key != "" {
+		return fmt.Sprintf("%v: %v: %v", d.Position, d.Key, d.Message)
+	}
+
+	return fmt.Sprintf("%v: %v", d.Position, d.Message)
+}
+
+func (d Diagnostics) Error() string {
+	lines := []string{}
+	for _, v := range d {
+		lines = append(lines, v.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func lint(d *conf.Document) Diagnostics {
+	diagnostics := Diagnostics{}
+	schema := newSchema()
+
+	// ... malformed lines
+	for _, p := range d.Malformed() {
+		diagnostics = append(diagnostics, Diagnostic{
+			Position: p,
+			Message:  "malformed line (expected 'key = value')",
+		})
+	}
+
+	// ... keys and values
+	for key, value := range d.Values() {
+		if message := schema.check(key, value); message != "" {
+			p, _ := d.Position(key)
+			diagnostics = append(diagnostics, Diagnostic{
+				Position: p,
+				Key:      key,
+				Message:  message,
+			})
+		}
+	}
+
+	slices.SortFunc(diagnostics, func(p, q Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(p.File, q.File),
+			cmp.Compare(p.Line, q.Line),
+			cmp.Compare(p.Key, q.Key))
+	})
+
+	return diagnostics
+}
+
+// The known keys and key patterns of a Config.
+type schema struct {
+	keys     map[string]any
+	patterns []*regexp.Regexp
+}
+
+func newSchema() schema {
+	s := schema{
+		keys:     map[string]any{},
+		patterns: []*regexp.Regexp{},
+	}
+
+	conf.Range(NewConfig(), func(key string, v any) bool {
+		if match := regexp.MustCompile(`^/(.*?)/$`).FindStringSubmatch(key); match != nil {
+			s.patterns = append(s.patterns, regexp.MustCompile(match[1]))
+		} else {
+			s.keys[key] = v
+		}
+
+		return true
+	})
+
+	return s
+}
+
+// Returns a description of the problem with a key/value pair, or "" if the key is known and the
+// value is valid.
+func (s schema) check(key, value string) string {
+	if v, ok := s.keys[key]; ok {
+		if err := conf.UnmarshalValues(map[string]string{key: value}, NewConfig()); err != nil {
+			return fmt.Sprintf("%v", cause(err))
+		} else if _, ok := v.(time.Duration); ok {
+			if dt, err := time.ParseDuration(value); err == nil && dt < 0 {
+				return fmt.Sprintf("invalid duration '%v' (must not be negative)", value)
+			}
+		}
+
+		return ""
+	}
+
+	if match := deviceKey.FindStringSubmatch(key); match != nil {
+		if id, err := strconv.ParseUint(match[2], 10, 32); err != nil || id == 0 {
+			return fmt.Sprintf("invalid controller ID '%v'", match[2])
+		} else if !slices.Contains(deviceKeys, match[3]) {
+			return fmt.Sprintf("unknown controller setting%v", suggest(match[3], deviceKeys))
+		} else if match[3] == "timezone" {
+			if _, err := timezone(value); err != nil {
+				return fmt.Sprintf("%v", err)
+			}
+		} else if err := conf.UnmarshalValues(map[string]string{key: value}, NewConfig()); err != nil {
+			return fmt.Sprintf("%v", cause(err))
+		}
+
+		return ""
+	}
+
+	if slices.ContainsFunc(s.patterns, func(re *regexp.Regexp) bool { return re.MatchString(key) }) {
+		if err := conf.UnmarshalValues(map[string]string{key: value}, NewConfig()); err != nil {
+			return fmt.Sprintf("%v", cause(err))
+		}
+
+		return ""
+	}
+
+	return fmt.Sprintf("unknown key%v", suggest(key, slices.Collect(maps.Keys(s.keys))))
+}
+
+// Returns a Diagnostic for a validation error, using the position of the key in the error (if the
+// error is a *conf.Error) or otherwise the position of the (last defined) key or value that is
+// referenced in the error message.
+func locate(d *conf.Document, err error) Diagnostic {
+	diagnostic := Diagnostic{
+		Message: fmt.Sprintf("%v", err),
+	}
+
+	var cerr *conf.Error
+	if errors.As(err, &cerr) && cerr.Key != "" {
+		if key, p, ok := position(d, cerr.Key); ok {
+			diagnostic.Position = p
+			diagnostic.Key = key
+			diagnostic.Message = fmt.Sprintf("%v", cerr.Err)
+
+			return diagnostic
+		}
+	}
+
+	msg := err.Error()
+	for key, value := range d.Values() {
+		if strings.Contains(msg, key) || (value != "" && strings.Contains(msg, "'"+value+"'")) {
+			if p, ok := d.Position(key); ok && (diagnostic.File == "" || p.File > diagnostic.File || (p.File == diagnostic.File && p.Line > diagnostic.Line)) {
+				diagnostic.Position = p
+			}
+		}
+	}
+
+	if diagnostic.File == "" {
+		if files := d.Files(); len(files) > 0 {
+			diagnostic.Position = conf.Position{File: files[0]}
+		}
+	}
+
+	return diagnostic
+}
+
+// Returns the defined key and position for a key. A key that is not defined in the document is
+// located by the closest defined key i.e. a controller key with a different model prefix (e.g.
+// 'UT0311-L0x' rather than 'UT0311-L02') or the first defined key with the same parent (e.g. any
+// of the 'network.vlan2.*' keys for 'network.vlan2.broadcast.address').
+func position(d *conf.Document, key string) (string, conf.Position, bool) {
+	if p, ok := d.Position(key); ok {
+		return key, p, true
+	}
+
+	first := func(f func(k string) bool) (string, conf.Position, bool) {
+		var key string
+		var position conf.Position
+		var found bool
+
+		for k := range d.Values() {
+			if p, ok := d.Position(k); ok && f(k) {
+				if !found || p.File < position.File || (p.File == position.File && p.Line < position.Line) {
+					key = k
+					position = p
+					found = true
+				}
+			}
+		}
+
+		return key, position, found
+	}
+
+	if match := deviceKey.FindStringSubmatch(key); match != nil {
+		if k, p, ok := first(func(k string) bool {
+			m := deviceKey.FindStringSubmatch(k)
+			return m != nil && m[2] == match[2] && m[3] == match[3]
+		}); ok {
+			return k, p, true
+		}
+
+		return first(func(k string) bool {
+			m := deviceKey.FindStringSubmatch(k)
+			return m != nil && m[2] == match[2]
+		})
+	}
+
+	for parent := key; strings.Count(parent, ".") > 1; {
+		parent = parent[:strings.LastIndex(parent, ".")]
+		if k, p, ok := first(func(k string) bool { return strings.HasPrefix(k, parent+".") }); ok {
+			return k, p, true
+		}
+	}
+
+	return "", conf.Position{}, false
+}
+
+// Returns the underlying error of a conf.Error i.e. without the key.
+func cause(err error) error {
+	var cerr *conf.Error
+	if errors.As(err, &cerr) {
+		return cerr.Err
+	}
+
+	return err
+}
+
+// Returns a 'did you mean' suggestion for the closest matching key, if it is close enough to be
+// a plausible typo.
+func suggest(key string, keys []string) string {
+	best := ""
+	distance := max(2, len(key)/5) + 1
+
+	for _, k := range slices.Sorted(slices.Values(keys)) {
+		if d := levenshtein(strings.ToLower(key), strings.ToLower(k)); d < distance {
+			best = k
+			distance = d
+		}
+	}
+
+	if best != "" {
+		return fmt.Sprintf(" (did you mean '%v'?)", best)
+	}
+
+	return ""
+}
+
+func levenshtein(p, q string) int {
+	a := []rune(p)
+	b := []rune(q)
+	row := make([]int, len(b)+1)
+
+	for j := range row {
+		row[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		previous := row[0]
+		row[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			current := row[j]
+			if a[i-1] == b[j-1] {
+				row[j] = previous
+			} else {
+				row[j] = 1 + min(previous, row[j], row[j-1])
+			}
+
+			previous = current
+		}
+	}
+
+	return row[len(b)]
+}
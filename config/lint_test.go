@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadStrict(t *testing.T) {
+	configuration := `# SYSTEM
+bind.address = 192.168.1.100:54321
+timeout = 5x
+monitoring.watchdog.interval = -5s
+this line is not a key value pair
+
+# MQTT
+mqtt.conection.broker = tls://127.0.0.1:8883
+
+# DEVICES
+UT0311-L0x.405419896.name = Alpha
+UT0311-L0x.405419896.address = 192.168.1.300
+UT0311-L0x.405419896.timezone = Nowhere/Atlantis
+UT0311-L0x.405419896.dor.1 = Front Door
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uhppoted.conf")
+	if err := os.WriteFile(path, []byte(configuration), 0644); err != nil {
+		t.Fatalf("Error writing test configuration (%v)", err)
+	}
+
+	expected := []struct {
+		line    int
+		key     string
+		message string
+	}{
+		{3, "timeout", ""},
+		{4, "monitoring.watchdog.interval", "invalid duration '-5s' (must not be negative)"},
+		{5, "", "malformed line (expected 'key = value')"},
+		{8, "mqtt.conection.broker", "unknown key (did you mean 'mqtt.connection.broker'?)"},
+		{12, "UT0311-L0x.405419896.address", ""},
+		{13, "UT0311-L0x.405419896.timezone", ""},
+		{14, "UT0311-L0x.405419896.dor.1", "unknown controller setting (did you mean 'door.1'?)"},
+	}
+
+	var diagnostics Diagnostics
+	if err := NewConfig().LoadStrict(path); !errors.As(err, &diagnostics) {
+		t.Fatalf("Expected Diagnostics, got %v", err)
+	}
+
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("Incorrect diagnostics - expected:%v, got:%v\n%v", len(expected), len(diagnostics), diagnostics)
+	}
+
+	for i, e := range expected {
+		d := diagnostics[i]
+		if d.File != path || d.Line != e.line || d.Key != e.key {
+			t.Errorf("Incorrect diagnostic %v - expected:%v:%v %v, got:%v", i+1, path, e.line, e.key, d)
+		}
+
+		if e.message != "" && d.Message != e.message {
+			t.Errorf("Incorrect diagnostic %v message - expected:%v, got:%v", i+1, e.message, d.Message)
+		}
+	}
+}
+
+func TestLoadStrictWithValidationError(t *testing.T) {
+	configuration := `# DEVICES
+UT0311-L0x.405419896.door.1 = Front Door
+UT0311-L0x.405419896.door.2 = Side Door
+
+UT0311-L0x.303986753.door.1 = Front Door
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uhppoted.conf")
+	if err := os.WriteFile(path, []byte(configuration), 0644); err != nil {
+		t.Fatalf("Error writing test configuration (%v)", err)
+	}
+
+	var diagnostics Diagnostics
+	if err := NewConfig().LoadStrict(path); !errors.As(err, &diagnostics) {
+		t.Fatalf("Expected Diagnostics, got %v", err)
+	} else if len(diagnostics) != 1 || diagnostics[0].File != path || diagnostics[0].Line != 2 || diagnostics[0].Key != "UT0311-L0x.405419896.door.1" {
+		t.Errorf("Incorrect diagnostics - expected:%v:%v, got:%v", path, 2, diagnostics)
+	}
+}
+
+func TestLoadStrictWithKeyedValidationErrors(t *testing.T) {
+	tests := []struct {
+		configuration string
+		line          int
+		key           string
+	}{
+		{
+			configuration: `# NETWORKS
+network.vlan2.bind.address = 192.168.2.100
+
+# DEVICES
+UT0311-L0x.405419896.network = vlan2
+`,
+			line: 2,
+			key:  "network.vlan2.bind.address",
+		},
+		{
+			configuration: `# DEVICES
+UT0311-L01.405419896.name = Alpha
+UT0311-L01.405419896.door.1 = Front Door
+UT0311-L0x.405419896.door.2 = Side Door
+`,
+			line: 4,
+			key:  "UT0311-L0x.405419896.door.2",
+		},
+		{
+			configuration: `# DEVICES
+UT0311-L02.405419896.name = Alpha
+UT0311-L02.405419896.door.3.mode = controlled
+`,
+			line: 3,
+			key:  "UT0311-L02.405419896.door.3.mode",
+		},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "uhppoted.conf")
+		if err := os.WriteFile(path, []byte(test.configuration), 0644); err != nil {
+			t.Fatalf("Error writing test configuration (%v)", err)
+		}
+
+		var diagnostics Diagnostics
+		if err := NewConfig().LoadStrict(path); !errors.As(err, &diagnostics) {
+			t.Fatalf("Expected Diagnostics, got %v", err)
+		} else if len(diagnostics) != 1 || diagnostics[0].File != path || diagnostics[0].Line != test.line || diagnostics[0].Key != test.key {
+			t.Errorf("Incorrect diagnostics - expected:%v:%v %v, got:%v", path, test.line, test.key, diagnostics)
+		}
+	}
+}
+
+func TestLoadStrictWithValidConfiguration(t *testing.T) {
+	configuration := `# SYSTEM
+bind.address = 192.168.1.100:54321
+broadcast.address = 192.168.1.255:60000
+timeout = 3.75s
+monitoring.healthcheck.interval = 31s
+
+# MQTT
+mqtt.connection.broker = tls://127.0.0.63:8887
+mqtt.connection.client.ID = muppet
+
+# DEVICES
+UT0311-L0x.405419896.name = Alpha
+UT0311-L0x.405419896.address = 192.168.1.100:60000
+UT0311-L0x.405419896.door.1 = Front Door
+UT0311-L0x.405419896.door.2 = Side Door
+UT0311-L0x.405419896.timezone = Europe/Paris
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uhppoted.conf")
+	if err := os.WriteFile(path, []byte(configuration), 0644); err != nil {
+		t.Fatalf("Error writing test configuration (%v)", err)
+	}
+
+	if err := NewConfig().LoadStrict(path); err != nil {
+		t.Errorf("Unexpected error (%v)", err)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	keys := []string{"timeout", "bind.address", "broadcast.address", "listen.address"}
+	tests := map[string]string{
+		"timout":          " (did you mean 'timeout'?)",
+		"bind.adress":     " (did you mean 'bind.address'?)",
+		"listen.adresses": " (did you mean 'listen.address'?)",
+		"qwerty":          "",
+	}
+
+	for key, expected := range tests {
+		if s := suggest(key, keys); s != expected {
+			t.Errorf("Incorrect suggestion for '%v' - expected:%q, got:%q", key, expected, s)
+		}
+	}
+	if expected := []string{"timeout", "bind.address", "broadcast.address", "listen.address"}; !slices.Equal(keys, expected) {
+		t.Errorf("suggest reordered the keys - expected:%v, got:%v", expected, keys)
+	}
+}
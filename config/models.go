@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Generic controller model prefix for devices without an explicit model.
+const UT0311_L0x = "UT0311-L0x"
+
+// Door counts for the supported controller models. The generic UT0311-L0x model is assumed
+// to be a 4 door controller.
+var models = map[string]int{
+	"UT0311-L01": 1,
+	"UT0311-L02": 2,
+	"UT0311-L04": 4,
+	UT0311_L0x:   4,
+}
+
+// Returns the number of doors for the device model.
+func (d Device) DoorCount() int {
+	if n, ok := models[d.Model]; ok {
+		return n
+	}
+
+	return 4
+}
+
+// Returns the explicitly configured controller models, keyed by controller ID.
+func (f DeviceMap) Models() map[uint32]string {
+	m := map[uint32]string{}
+
+	for id, d := range f {
+		if d != nil && d.Model != "" {
+			m[id] = d.Model
+		}
+	}
+
+	return m
+}
+
+// Returns the configuration key prefix for the device i.e. <model>.<controller>
+func (d Device) prefix(id uint32) string {
+	if d.Model == "" {
+		return fmt.Sprintf("%v.%v", UT0311_L0x, id)
+	}
+
+	return fmt.Sprintf("%v.%v", d.Model, id)
+}
+
+// Returns the door names for the number of doors of the device model.
+func (d Device) doors() []kv {
+	doors := []kv{}
+
+	for i := range d.DoorCount() {
+		door := ""
+		if i < len(d.Doors) {
+			door = d.Doors[i]
+		}
+
+		doors = append(doors, kv{fmt.Sprintf("door.%v", i+1), door, false})
+	}
+
+	return doors
+}
+
+// Returns an error if the device has a door name or door setting for a door that does not exist
+// on the device model.
+func (d Device) validate(id uint32) error {
+	if _, ok := models[d.Model]; d.Model != "" && !ok {
+		return invalid(d.prefix(id)+".name", "controller %v: unknown model '%v'", id, d.Model)
+	}
+
+	N := d.DoorCount()
+
+	for i := N; i < len(d.Doors); i++ {
+		if strings.TrimSpace(d.Doors[i]) != "" {
+			return invalid(fmt.Sprintf("%v.door.%v", d.prefix(id), i+1), "controller %v: %v has %v door(s) - door %v ('%v') is not valid", id, d.Model, N, i+1, d.Doors[i])
+		}
+	}
+
+	for i := N; i < len(d.Settings.Doors); i++ {
+		if door := d.Settings.Doors[i]; door.Mode != 0 || door.Delay != nil {
+			key := fmt.Sprintf("%v.door.%v.mode", d.prefix(id), i+1)
+			if door.Mode == 0 {
+				key = fmt.Sprintf("%v.door.%v.delay", d.prefix(id), i+1)
+			}
+
+			return invalid(key, "controller %v: %v has %v door(s) - door %v settings are not valid", id, d.Model, N, i+1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+func TestDeviceModelUnmarshal(t *testing.T) {
+	text := `UT0311-L02.405419896.name = Alpha
+UT0311-L02.405419896.door.1 = Front Door
+UT0311-L02.405419896.door.2 = Side Door
+UT0311-L0x.303986753.name = Beta
+UT0311-L01.201020304.name = Gamma
+UT0311-L0x.201020304.door.1 = Gate
+`
+
+	c := NewConfig()
+	if err := c.Read(strings.NewReader(text)); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	expected := map[uint32]string{
+		405419896: "UT0311-L02",
+		201020304: "UT0311-L01",
+	}
+
+	if models := c.Devices.Models(); !reflect.DeepEqual(models, expected) {
+		t.Errorf("Incorrect models - expected:%v, got:%v", expected, models)
+	}
+
+	if c.Devices[201020304].Doors[0] != "Gate" {
+		t.Errorf("Incorrect door 1 - expected:%v, got:%v", "Gate", c.Devices[201020304].Doors[0])
+	}
+
+	if n := c.Devices[303986753].DoorCount(); n != 4 {
+		t.Errorf("Incorrect door count - expected:%v, got:%v", 4, n)
+	}
+}
+
+func TestDeviceModelUnmarshalWithConflictingModels(t *testing.T) {
+	text := `UT0311-L02.405419896.name = Alpha
+UT0311-L04.405419896.door.1 = Front Door
+`
+
+	if err := NewConfig().Read(strings.NewReader(text)); err == nil {
+		t.Errorf("Expected error for conflicting controller models, got:%v", err)
+	}
+}
+
+func TestDeviceModelValidate(t *testing.T) {
+	delay := uint8(5)
+
+	tests := []Device{
+		{Model: "UT0311-L02", Doors: []string{"Front Door", "Side Door", "Garage", ""}},
+		{Model: "UT0311-L01", Doors: []string{"Front Door", "", "", ""}, Settings: DeviceSettings{Doors: [4]DoorSettings{{}, {Delay: &delay}}}},
+		{Model: "UT0311-L03", Doors: []string{"Front Door", "", "", ""}},
+	}
+
+	for _, device := range tests {
+		c := NewConfig()
+		c.Devices = DeviceMap{405419896: &device}
+
+		if err := c.Validate(); err == nil {
+			t.Errorf("Expected validation error for %+v, got:%v", device, err)
+		}
+	}
+
+	c := NewConfig()
+	c.Devices = DeviceMap{
+		405419896: &Device{Model: "UT0311-L02", Doors: []string{"Front Door", "Side Door", "", ""}},
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("Unexpected validation error (%v)", err)
+	}
+}
+
+func TestDeviceModelMarshal(t *testing.T) {
+	expected := `# DEVICES
+UT0311-L02.405419896.name = Alpha
+UT0311-L02.405419896.door.1 = Front Door
+UT0311-L02.405419896.door.2 = Side Door
+
+`
+
+	devices := DeviceMap{
+		405419896: &Device{
+			Model: "UT0311-L02",
+			Name:  "Alpha",
+			Doors: []string{"Front Door", "Side Door", "", ""},
+		},
+	}
+
+	b, err := devices.MarshalConf("devices")
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if string(b) != expected {
+		t.Errorf("Incorrectly marshalled device list\n   expected:%v\n   got:     %v", expected, string(b))
+	}
+}
+
+func TestDeviceModelWrite(t *testing.T) {
+	expected := `# DEVICES
+UT0311-L01.405419896.name = Alpha
+UT0311-L01.405419896.address = 192.168.1.100
+UT0311-L01.405419896.door.1 = Front Door
+UT0311-L01.405419896.timezone = UTC
+`
+
+	c := NewConfig()
+	c.Devices = DeviceMap{
+		405419896: &Device{
+			Model:    "UT0311-L01",
+			Name:     "Alpha",
+			Address:  types.MustParseControllerAddr("192.168.1.100:60000"),
+			Doors:    []string{"Front Door", "", "", ""},
+			TimeZone: "UTC",
+		},
+	}
+
+	var b bytes.Buffer
+	if err := c.Write(&b); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !strings.Contains(b.String(), expected) {
+		t.Errorf("Incorrect device configuration\n   expected:%v\n   got:     %v", expected, b.String())
+	}
+}
+
+func TestDeviceModelToControllers(t *testing.T) {
+	devices := DeviceMap{
+		405419896: &Device{
+			Model: "UT0311-L02",
+			Name:  "Alpha",
+			Doors: []string{"Front Door", "Side Door", "", ""},
+		},
+	}
+
+	expected := []uhppote.Device{
+		{
+			Name:     "Alpha",
+			DeviceID: 405419896,
+			Doors:    []string{"Front Door", "Side Door"},
+			TimeZone: time.Local,
+			Protocol: "udp",
+		},
+	}
+
+	if controllers := devices.ToControllers(); !reflect.DeepEqual(controllers, expected) {
+		t.Errorf("Incorrect controllers\n   expected:%v\n   got:     %v", expected, controllers)
+	}
+}
+
+func TestEditSetDeviceModel(t *testing.T) {
+	expected := strings.Replace(editable, `UT0311-L0x.303986753.name = Beta
+UT0311-L0x.303986753.door.1 = Back Door
+UT0311-L0x.303986753.door.2 =
+UT0311-L0x.303986753.door.3 =
+UT0311-L0x.303986753.door.4 =
+`, `UT0311-L02.303986753.name = Beta
+UT0311-L02.303986753.address = 192.168.1.101
+UT0311-L02.303986753.door.1 = Back Door
+UT0311-L02.303986753.door.2 = Side Gate
+`, 1)
+
+	e, _ := NewEditor(strings.NewReader(editable))
+
+	err := e.SetDevice(303986753, Device{
+		Model:   "UT0311-L02",
+		Name:    "Beta",
+		Address: types.MustParseControllerAddr("192.168.1.101:60000"),
+		Doors:   []string{"Back Door", "Side Gate", "", ""},
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if s := string(e.Bytes()); s != expected {
+		t.Errorf("Incorrect edited configuration\n   expected:%v\n   got:     %v", expected, s)
+	}
+
+	if err := e.SetDevice(303986753, Device{Model: "UT0311-L01", Doors: []string{"Back Door", "Side Gate"}}); err == nil {
+		t.Errorf("Expected error for door 2 on a UT0311-L01, got:%v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppoted-lib/encoding/conf"
+	"github.com/uhppoted/uhppoted-lib/network"
+)
+
+// NetworkMap maps a network profile name to the bind, broadcast and listen addresses for the
+// network, defined in the conf file as e.g.
+//
+//	network.vlan2.bind.address = 192.168.2.10
+//	network.vlan2.broadcast.address = 192.168.2.255:60000
+//	network.vlan2.listen.address = 192.168.2.10:60001
+//
+// Controllers are assigned to a network profile with the controller 'network' setting e.g.
+//
+//	UT0311-L0x.405419896.network = vlan2
+//
+// Controllers without a network profile use the system bind, broadcast and listen addresses.
+type NetworkMap map[string]*Network
+
+type Network struct {
+	BindAddress      *types.BindAddr
+	BroadcastAddress *types.BroadcastAddr
+	ListenAddress    *types.ListenAddr
+}
+
+var networkName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func (n NetworkMap) MarshalConf(tag string) ([]byte, error) {
+	var s strings.Builder
+
+	if len(n) > 0 {
+		fmt.Fprintf(&s, "# NETWORKS\n")
+		for _, name := range slices.Sorted(maps.Keys(n)) {
+			for _, v := range n[name].settings() {
+				fmt.Fprintf(&s, "network.%s.%s = %v\n", name, v.Key, v.Value)
+			}
+		}
+		fmt.Fprintf(&s, "\n")
+	}
+
+	return []byte(s.String()), nil
+}
+
+func (n *NetworkMap) UnmarshalConf(tag string, values map[string]string) (any, error) {
+	re := regexp.MustCompile(`^/(.*?)/$`)
+	match := re.FindStringSubmatch(tag)
+	if len(match) < 2 {
+		return n, fmt.Errorf("invalid 'conf' regular expression tag: %s", tag)
+	}
+
+	re, err := regexp.Compile(match[1])
+	if err != nil {
+		return n, err
+	}
+
+	if *n == nil {
+		*n = NetworkMap{}
+	}
+
+	for key, value := range values {
+		if match := re.FindStringSubmatch(key); len(match) > 2 {
+			name := match[1]
+
+			p, ok := (*n)[name]
+			if !ok || p == nil {
+				p = &Network{}
+				(*n)[name] = p
+			}
+
+			switch match[2] {
+			case "bind.address":
+				if addr, err := types.ParseBindAddr(value); err != nil {
+					return n, &conf.Error{Key: key, Err: fmt.Errorf("network %v, invalid bind address '%s': %v", name, value, err)}
+				} else {
+					p.BindAddress = &addr
+				}
+
+			case "broadcast.address":
+				if addr, err := types.ParseBroadcastAddr(value); err != nil {
+					return n, &conf.Error{Key: key, Err: fmt.Errorf("network %v, invalid broadcast address '%s': %v", name, value, err)}
+				} else {
+					p.BroadcastAddress = &addr
+				}
+
+			case "listen.address":
+				if addr, err := types.ParseListenAddr(value); err != nil {
+					return n, &conf.Error{Key: key, Err: fmt.Errorf("network %v, invalid listen address '%s': %v", name, value, err)}
+				} else {
+					p.ListenAddress = &addr
+				}
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// Returns the bind, broadcast and listen addresses for each network, along with the controllers
+// assigned to the network. Controllers without a network profile are returned in the default
+// (unnamed) network with the system addresses. The list is ordered by network name and a
+// network without any controllers is included so that it is still used for discovery.
+func (c *Config) ToNetworks() []network.Network {
+	devices := map[string]DeviceMap{}
+
+	for id, d := range c.Devices {
+		if d != nil {
+			if devices[d.Network] == nil {
+				devices[d.Network] = DeviceMap{}
+			}
+
+			devices[d.Network][id] = d
+		}
+	}
+
+	networks := []network.Network{
+		{
+			Name:             "",
+			BindAddress:      *c.BindAddress,
+			BroadcastAddress: *c.BroadcastAddress,
+			ListenAddress:    *c.ListenAddress,
+			Controllers:      devices[""].ToControllers(),
+		},
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Networks)) {
+		if p := c.Networks[name]; p != nil && p.BroadcastAddress != nil {
+			bind := types.BindAddrFrom(INADDR_ANY, 0)
+			if p.BindAddress != nil {
+				bind = *p.BindAddress
+			}
+
+			n := network.Network{
+				Name:             name,
+				BindAddress:      bind,
+				BroadcastAddress: *p.BroadcastAddress,
+				Controllers:      devices[name].ToControllers(),
+			}
+
+			if p.ListenAddress != nil {
+				n.ListenAddress = *p.ListenAddress
+			}
+
+			networks = append(networks, n)
+		}
+	}
+
+	return networks
+}
+
+// Verifies that each network has a valid name and broadcast address, that the network ports
+// are valid, that no two networks (including the system network) listen on the same address
+// and that every controller network profile is defined.
+func (n NetworkMap) validate(devices DeviceMap, listen *types.ListenAddr) error {
+	listeners := map[string]*types.ListenAddr{}
+	if listen != nil && listen.IsValid() {
+		listeners["listen.address"] = listen
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(n)) {
+		p := n[name]
+		key := func(setting string) string {
+			return fmt.Sprintf("network.%v.%v", name, setting)
+		}
+
+		if !networkName.MatchString(name) {
+			return invalid(key("broadcast.address"), "invalid network name '%s'", name)
+		} else if p == nil || p.BroadcastAddress == nil {
+			setting := "bind.address"
+			if p != nil && p.BindAddress == nil && p.ListenAddress != nil {
+				setting = "listen.address"
+			}
+
+			return invalid(key(setting), "network %v: missing broadcast.address", name)
+		} else if p.BroadcastAddress.Port() == 0 {
+			return invalid(key("broadcast.address"), "network %v: port 0 is not a valid port for broadcast.address", name)
+		}
+
+		if p.BindAddress != nil {
+			if port := p.BindAddress.Port(); port == 60000 {
+				return invalid(key("bind.address"), "network %v: port %v is not a valid port for bind.address", name, port)
+			} else if port != 0 && port == p.BroadcastAddress.Port() {
+				return invalid(key("bind.address"), "network %v: bind.address port (%v) must not be the same as the broadcast.address port", name, port)
+			} else if port != 0 && p.ListenAddress != nil && port == p.ListenAddress.Port() {
+				return invalid(key("bind.address"), "network %v: bind.address port (%v) must not be the same as the listen.address port", name, port)
+			}
+		}
+
+		if p.ListenAddress != nil && p.ListenAddress.Port() == 0 {
+			return invalid(key("listen.address"), "network %v: port 0 is not a valid port for listen.address", name)
+		}
+
+		if p.ListenAddress != nil && p.ListenAddress.IsValid() {
+			for _, k := range slices.Sorted(maps.Keys(listeners)) {
+				if overlaps(*p.ListenAddress, *listeners[k]) {
+					return invalid(key("listen.address"), "network %v: listen.address %v conflicts with %v (%v)", name, p.ListenAddress, k, listeners[k])
+				}
+			}
+
+			listeners[key("listen.address")] = p.ListenAddress
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(devices)) {
+		if d := devices[id]; d != nil && d.Network != "" {
+			if _, ok := n[d.Network]; !ok {
+				return invalid(d.prefix(id)+".network", "controller %v: network '%v' is not defined", id, d.Network)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the network settings formatted as for the configuration file.
+func (p *Network) settings() []kv {
+	settings := []kv{}
+
+	if p == nil {
+		return settings
+	}
+
+	if p.BindAddress != nil {
+		settings = append(settings, kv{"bind.address", fmt.Sprintf("%v", p.BindAddress), false})
+	}
+
+	if p.BroadcastAddress != nil {
+		settings = append(settings, kv{"broadcast.address", fmt.Sprintf("%v", p.BroadcastAddress), false})
+	}
+
+	if p.ListenAddress != nil {
+		settings = append(settings, kv{"listen.address", fmt.Sprintf("%v", p.ListenAddress), false})
+	}
+
+	return settings
+}
+
+// Returns true if two listen addresses would bind the same UDP port i.e. the ports are the
+// same and the addresses are either the same or one of them is INADDR_ANY.
+func overlaps(p, q types.ListenAddr) bool {
+	if p.Port() != q.Port() {
+		return false
+	}
+
+	return p.Addr() == q.Addr() || p.Addr().IsUnspecified() || q.Addr().IsUnspecified()
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+const networks = `# SYSTEM
+bind.address = 192.168.1.100
+broadcast.address = 192.168.1.255:60000
+listen.address = 192.168.1.100:60001
+
+# NETWORKS
+network.vlan2.bind.address = 192.168.2.100
+network.vlan2.broadcast.address = 192.168.2.255:60000
+network.vlan2.listen.address = 192.168.2.100:60001
+network.vlan3.broadcast.address = 192.168.3.255:60000
+
+# DEVICES
+UT0311-L0x.405419896.name = Alpha
+UT0311-L0x.405419896.door.1 = Front Door
+UT0311-L0x.303986753.name = Beta
+UT0311-L0x.303986753.network = vlan2
+UT0311-L0x.303986753.door.1 = Back Door
+`
+
+func TestNetworksUnmarshal(t *testing.T) {
+	bind := types.MustParseBindAddr("192.168.2.100")
+	broadcast := types.MustParseBroadcastAddr("192.168.2.255:60000")
+	listen := types.MustParseListenAddr("192.168.2.100:60001")
+
+	c := NewConfig()
+	if err := c.Read(strings.NewReader(networks)); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	expected := &Network{
+		BindAddress:      &bind,
+		BroadcastAddress: &broadcast,
+		ListenAddress:    &listen,
+	}
+
+	if len(c.Networks) != 2 {
+		t.Errorf("Incorrect number of networks - expected:%v, got:%v", 2, len(c.Networks))
+	}
+
+	if !reflect.DeepEqual(c.Networks["vlan2"], expected) {
+		t.Errorf("Incorrect network - expected:%+v, got:%+v", expected, c.Networks["vlan2"])
+	}
+
+	if network := c.Devices[303986753].Network; network != "vlan2" {
+		t.Errorf("Incorrect controller network - expected:%v, got:%v", "vlan2", network)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("Unexpected validation error (%v)", err)
+	}
+}
+
+func TestToNetworks(t *testing.T) {
+	c := NewConfig()
+	if err := c.Read(strings.NewReader(networks)); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	list := c.ToNetworks()
+
+	if len(list) != 3 {
+		t.Fatalf("Incorrect number of networks - expected:%v, got:%v", 3, len(list))
+	}
+
+	tests := []struct {
+		name        string
+		bind        string
+		broadcast   string
+		listen      string
+		controllers []uint32
+	}{
+		{"", "192.168.1.100", "192.168.1.255", "192.168.1.100:60001", []uint32{405419896}},
+		{"vlan2", "192.168.2.100", "192.168.2.255", "192.168.2.100:60001", []uint32{303986753}},
+		{"vlan3", "0.0.0.0", "192.168.3.255", "", []uint32{}},
+	}
+
+	for i, test := range tests {
+		n := list[i]
+		controllers := []uint32{}
+		for _, d := range n.Controllers {
+			controllers = append(controllers, d.DeviceID)
+		}
+
+		if n.Name != test.name {
+			t.Errorf("Incorrect network %v name - expected:'%v', got:'%v'", i+1, test.name, n.Name)
+		}
+
+		if s := n.BindAddress.String(); s != test.bind {
+			t.Errorf("Incorrect network '%v' bind address - expected:%v, got:%v", n.Name, test.bind, s)
+		}
+
+		if s := n.BroadcastAddress.String(); s != test.broadcast {
+			t.Errorf("Incorrect network '%v' broadcast address - expected:%v, got:%v", n.Name, test.broadcast, s)
+		}
+
+		if test.listen != "" && n.ListenAddress.String() != test.listen {
+			t.Errorf("Incorrect network '%v' listen address - expected:%v, got:%v", n.Name, test.listen, n.ListenAddress.String())
+		}
+
+		if !reflect.DeepEqual(controllers, test.controllers) {
+			t.Errorf("Incorrect network '%v' controllers - expected:%v, got:%v", n.Name, test.controllers, controllers)
+		}
+	}
+}
+
+func TestNetworksValidate(t *testing.T) {
+	tests := map[string]string{
+		"undefined network": `network.vlan2.broadcast.address = 192.168.2.255:60000
+UT0311-L0x.405419896.network = vlan3
+`,
+		"missing broadcast address": `network.vlan2.bind.address = 192.168.2.100
+UT0311-L0x.405419896.network = vlan2
+`,
+		"conflicting ports": `network.vlan2.bind.address = 192.168.2.100:60001
+network.vlan2.broadcast.address = 192.168.2.255:60000
+network.vlan2.listen.address = 192.168.2.100:60001
+`,
+		"duplicate network listen address": `network.vlan2.broadcast.address = 192.168.2.255:60000
+network.vlan2.listen.address = 192.168.2.100:60002
+network.vlan3.broadcast.address = 192.168.3.255:60000
+network.vlan3.listen.address = 192.168.2.100:60002
+`,
+		"duplicate system listen address": `listen.address = 192.168.1.100:60001
+network.vlan2.broadcast.address = 192.168.2.255:60000
+network.vlan2.listen.address = 192.168.1.100:60001
+`,
+		"overlapping listen address": `listen.address = 0.0.0.0:60001
+network.vlan2.broadcast.address = 192.168.2.255:60000
+network.vlan2.listen.address = 192.168.2.100:60001
+`,
+	}
+
+	for name, text := range tests {
+		c := NewConfig()
+		if err := c.Read(strings.NewReader(text)); err != nil {
+			t.Fatalf("Unexpected error (%v)", err)
+		}
+
+		if err := c.Validate(); err == nil {
+			t.Errorf("Expected validation error for %v, got:%v", name, err)
+		}
+	}
+}
+
+func TestNetworksWrite(t *testing.T) {
+	expected := `# NETWORKS
+network.vlan2.bind.address = 192.168.2.100
+network.vlan2.broadcast.address = 192.168.2.255
+network.vlan2.listen.address = 192.168.2.100:60001
+network.vlan3.broadcast.address = 192.168.3.255
+`
+
+	c := NewConfig()
+	if err := c.Read(strings.NewReader(networks)); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	var b bytes.Buffer
+	if err := c.Write(&b); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !strings.Contains(b.String(), expected) {
+		t.Errorf("Incorrect network configuration\n   expected:%v\n   got:     %v", expected, b.String())
+	}
+
+	if !strings.Contains(b.String(), "UT0311-L0x.303986753.network = vlan2\n") {
+		t.Errorf("Missing controller network in configuration\n   got:%v", b.String())
+	}
+}
+
+func TestLoadStrictWithInvalidNetwork(t *testing.T) {
+	configuration := `# NETWORKS
+network.vlan2.broadcast.address = 192.168.2.255:60000
+network.vlan2.listen.address = qwerty
+
+# DEVICES
+UT0311-L0x.405419896.network = vlan2
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "uhppoted.conf")
+	if err := os.WriteFile(path, []byte(configuration), 0644); err != nil {
+		t.Fatalf("Error writing test configuration (%v)", err)
+	}
+
+	var diagnostics Diagnostics
+	if err := NewConfig().LoadStrict(path); !errors.As(err, &diagnostics) {
+		t.Fatalf("Expected Diagnostics, got %v", err)
+	} else if len(diagnostics) == 0 || diagnostics[0].Line != 3 {
+		t.Errorf("Incorrect diagnostics - expected:%v:%v, got:%v", path, 3, diagnostics)
+	}
+}
@@ -0,0 +1,380 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppoted-lib/uhppoted"
+)
+
+// DeviceSettings holds the optional controller settings declared for a device in the
+// configuration file. Undeclared settings are nil (or zero) and are left 'as is' on the
+// controller.
+type DeviceSettings struct {
+	Doors            [4]DoorSettings
+	Interlock        *types.Interlock
+	AntiPassback     *types.AntiPassback
+	Keypads          map[uint8]bool
+	CardFormat       *types.CardFormat
+	Listener         *netip.AddrPort
+	ListenerInterval *uint8
+	Timeout          time.Duration
+}
+
+// DoorSettings holds the declared control mode and unlock delay for a door. A zero mode and
+// nil delay mean the setting is not declared.
+type DoorSettings struct {
+	Mode  types.ControlState
+	Delay *uint8
+}
+
+var doorSetting = regexp.MustCompile(`^door\.([1-4])\.(mode|delay)$`)
+
+var controlStates = []types.ControlState{
+	types.NormallyOpen,
+	types.NormallyClosed,
+	types.Controlled,
+	types.FirstCardOnly,
+}
+
+var interlocks = []types.Interlock{
+	types.NoInterlock,
+	types.Interlock12,
+	types.Interlock34,
+	types.Interlock12_34,
+	types.Interlock123,
+	types.Interlock1234,
+}
+
+var antipassbacks = []types.AntiPassback{
+	types.Disabled,
+	types.Readers12_34,
+	types.Readers13_24,
+	types.Readers1_23,
+	types.Readers1_234,
+}
+
+// Returns the configured request timeout for a controller i.e. the device timeout if it has one
+// and the system timeout otherwise.
+func (c *Config) DeviceTimeout(id uint32) time.Duration {
+	if d, ok := c.Devices[id]; ok && d != nil && d.Settings.Timeout > 0 {
+		return d.Settings.Timeout
+	}
+
+	return c.Timeout
+}
+
+// Returns the configured card format for a controller i.e. the device card format if it has one
+// and the system card format otherwise.
+func (c *Config) DeviceCardFormat(id uint32) types.CardFormat {
+	if d, ok := c.Devices[id]; ok && d != nil && d.Settings.CardFormat != nil {
+		return *d.Settings.CardFormat
+	}
+
+	return c.CardFormat
+}
+
+// Returns the declared controller settings in the configuration as a reconcile request for
+// UHPPOTED. Devices without any declared controller settings are omitted. The card format and
+// timeout are local settings and are not included.
+func NewReconcileRequest(devices DeviceMap, dryrun bool) uhppoted.ReconcileRequest {
+	request := uhppoted.ReconcileRequest{
+		Controllers: map[uint32]uhppoted.ControllerSettings{},
+		DryRun:      dryrun,
+	}
+
+	for id, d := range devices {
+		if d == nil {
+			continue
+		}
+
+		settings := uhppoted.ControllerSettings{
+			Doors:            map[uint8]uhppoted.DoorSettings{},
+			Interlock:        d.Settings.Interlock,
+			AntiPassback:     d.Settings.AntiPassback,
+			Keypads:          d.Settings.Keypads,
+			Listener:         d.Settings.Listener,
+			ListenerInterval: d.Settings.ListenerInterval,
+		}
+
+		for i, door := range d.Settings.Doors {
+			if door.Mode != 0 || door.Delay != nil {
+				settings.Doors[uint8(i+1)] = uhppoted.DoorSettings{
+					Mode:  door.Mode,
+					Delay: door.Delay,
+				}
+			}
+		}
+
+		if len(settings.Doors) > 0 || settings.Interlock != nil || settings.AntiPassback != nil || settings.Keypads != nil || settings.Listener != nil || settings.ListenerInterval != nil {
+			request.Controllers[id] = settings
+		}
+	}
+
+	return request
+}
+
+// Parses a device setting, ignoring unknown settings.
+func (s *DeviceSettings) set(setting, value string) error {
+	if match := doorSetting.FindStringSubmatch(setting); match != nil {
+		door, _ := strconv.Atoi(match[1])
+
+		switch match[2] {
+		case "mode":
+			if mode, err := parseControlState(value); err != nil {
+				return fmt.Errorf("invalid door %v mode '%s'", door, value)
+			} else {
+				s.Doors[door-1].Mode = mode
+			}
+
+		case "delay":
+			if delay, err := strconv.ParseUint(strings.TrimSpace(value), 10, 8); err != nil {
+				return fmt.Errorf("invalid door %v delay '%s'", door, value)
+			} else {
+				v := uint8(delay)
+				s.Doors[door-1].Delay = &v
+			}
+		}
+
+		return nil
+	}
+
+	switch setting {
+	case "interlock":
+		if interlock, err := parseInterlock(value); err != nil {
+			return fmt.Errorf("invalid interlock '%s'", value)
+		} else {
+			s.Interlock = &interlock
+		}
+
+	case "anti-passback":
+		if antipassback, err := parseAntiPassback(value); err != nil {
+			return fmt.Errorf("invalid anti-passback '%s'", value)
+		} else {
+			s.AntiPassback = &antipassback
+		}
+
+	case "keypads":
+		if keypads, err := parseKeypads(value); err != nil {
+			return fmt.Errorf("invalid keypads '%s'", value)
+		} else {
+			s.Keypads = keypads
+		}
+
+	case "card.format":
+		if format, err := types.CardFormatFromString(value); err != nil {
+			return fmt.Errorf("invalid card format '%s'", value)
+		} else {
+			s.CardFormat = &format
+		}
+
+	case "listener":
+		if listener, err := netip.ParseAddrPort(strings.TrimSpace(value)); err != nil || !listener.Addr().Is4() {
+			return fmt.Errorf("invalid listener '%s'", value)
+		} else {
+			s.Listener = &listener
+		}
+
+	case "listener.interval":
+		if interval, err := strconv.ParseUint(strings.TrimSpace(value), 10, 8); err != nil {
+			return fmt.Errorf("invalid listener interval '%s'", value)
+		} else {
+			v := uint8(interval)
+			s.ListenerInterval = &v
+		}
+
+	case "timeout":
+		if timeout, err := time.ParseDuration(strings.TrimSpace(value)); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid timeout '%s'", value)
+		} else {
+			s.Timeout = timeout
+		}
+	}
+
+	return nil
+}
+
+// Returns the device settings formatted as for the configuration file, with an empty value for
+// undeclared settings.
+func (s DeviceSettings) settings() []kv {
+	settings := []kv{}
+
+	for i, door := range s.Doors {
+		mode := ""
+		delay := ""
+
+		if door.Mode != 0 {
+			mode = fmt.Sprintf("%v", door.Mode)
+		}
+
+		if door.Delay != nil {
+			delay = fmt.Sprintf("%v", *door.Delay)
+		}
+
+		settings = append(settings,
+			kv{fmt.Sprintf("door.%v.mode", i+1), mode, false},
+			kv{fmt.Sprintf("door.%v.delay", i+1), delay, false})
+	}
+
+	format := func(v any) string {
+		switch v := v.(type) {
+		case *types.Interlock:
+			if v != nil {
+				return fmt.Sprintf("%v", *v)
+			}
+
+		case *types.AntiPassback:
+			if v != nil {
+				return fmt.Sprintf("%v", *v)
+			}
+
+		case *types.CardFormat:
+			if v != nil {
+				return fmt.Sprintf("%v", *v)
+			}
+
+		case *netip.AddrPort:
+			if v != nil {
+				return fmt.Sprintf("%v", *v)
+			}
+
+		case *uint8:
+			if v != nil {
+				return fmt.Sprintf("%v", *v)
+			}
+
+		case time.Duration:
+			if v > 0 {
+				return fmt.Sprintf("%v", v)
+			}
+		}
+
+		return ""
+	}
+
+	keypads := ""
+	if s.Keypads != nil {
+		keypads = formatKeypads(s.Keypads)
+	}
+
+	return append(settings,
+		kv{"interlock", format(s.Interlock), false},
+		kv{"anti-passback", format(s.AntiPassback), false},
+		kv{"keypads", keypads, false},
+		kv{"card.format", format(s.CardFormat), false},
+		kv{"listener", format(s.Listener), false},
+		kv{"listener.interval", format(s.ListenerInterval), false},
+		kv{"timeout", format(s.Timeout), false})
+}
+
+// Returns the declared device settings formatted as for the configuration file.
+func (s DeviceSettings) declared() []kv {
+	declared := []kv{}
+	for _, v := range s.settings() {
+		if v.Value != "" {
+			declared = append(declared, v)
+		}
+	}
+
+	return declared
+}
+
+// Parses a door control mode e.g. 'controlled', 'normally open' or 'normally-closed'.
+func parseControlState(s string) (types.ControlState, error) {
+	v := strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), " ")
+
+	if v == "first card" {
+		v = "firstcard"
+	}
+
+	for _, mode := range controlStates {
+		if v == fmt.Sprintf("%v", mode) {
+			return mode, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid door control mode '%v'", s)
+}
+
+// Parses an interlock mode e.g. 'disabled', '1&2' or '1&2,3&4'.
+func parseInterlock(s string) (types.Interlock, error) {
+	v := strings.ReplaceAll(strings.ToLower(s), " ", "")
+
+	if v == "none" {
+		return types.NoInterlock, nil
+	}
+
+	for _, interlock := range interlocks {
+		if v == fmt.Sprintf("%v", interlock) {
+			return interlock, nil
+		}
+	}
+
+	return types.NoInterlock, fmt.Errorf("invalid interlock '%v'", s)
+}
+
+// Parses an anti-passback mode e.g. 'disabled', '(1:2);(3:4)' or '1:(2,3)'.
+func parseAntiPassback(s string) (types.AntiPassback, error) {
+	v := strings.ReplaceAll(strings.ToLower(s), " ", "")
+
+	if v == "none" {
+		return types.Disabled, nil
+	}
+
+	for _, antipassback := range antipassbacks {
+		if v == fmt.Sprintf("%v", antipassback) {
+			return antipassback, nil
+		}
+	}
+
+	return types.Disabled, fmt.Errorf("invalid anti-passback '%v'", s)
+}
+
+// Parses the list of readers with active keypads e.g. '1, 2', 'all' or 'none'. The keypads
+// for the readers that are not in the list are deactivated.
+func parseKeypads(s string) (map[uint8]bool, error) {
+	keypads := map[uint8]bool{1: false, 2: false, 3: false, 4: false}
+	v := strings.ToLower(strings.TrimSpace(s))
+
+	switch v {
+	case "none", "":
+		return keypads, nil
+
+	case "all":
+		return map[uint8]bool{1: true, 2: true, 3: true, 4: true}, nil
+	}
+
+	for token := range strings.SplitSeq(v, ",") {
+		if reader, err := strconv.ParseUint(strings.TrimSpace(token), 10, 8); err != nil || reader < 1 || reader > 4 {
+			return nil, fmt.Errorf("invalid keypad '%v'", token)
+		} else {
+			keypads[uint8(reader)] = true
+		}
+	}
+
+	return keypads, nil
+}
+
+func formatKeypads(keypads map[uint8]bool) string {
+	active := []string{}
+	for _, reader := range slices.Sorted(maps.Keys(keypads)) {
+		if keypads[reader] {
+			active = append(active, fmt.Sprintf("%v", reader))
+		}
+	}
+
+	if len(active) == 0 {
+		return "none"
+	}
+
+	return strings.Join(active, ", ")
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppoted-lib/encoding/conf"
+	"github.com/uhppoted/uhppoted-lib/uhppoted"
+)
+
+func TestDeviceUnmarshalSettings(t *testing.T) {
+	tag := `/^UT0311-L0x\.([0-9]+)\.(.*)/`
+	values := map[string]string{
+		"UT0311-L0x.405419896.name":              "Alpha",
+		"UT0311-L0x.405419896.door.1":            "Gryffindor",
+		"UT0311-L0x.405419896.door.1.mode":       "normally-open",
+		"UT0311-L0x.405419896.door.2.delay":      "7",
+		"UT0311-L0x.405419896.interlock":         "1&2, 3&4",
+		"UT0311-L0x.405419896.anti-passback":     "(1,3):(2,4)",
+		"UT0311-L0x.405419896.keypads":           "1, 3",
+		"UT0311-L0x.405419896.card.format":       "Wiegand-26",
+		"UT0311-L0x.405419896.listener":          "192.168.1.100:60001",
+		"UT0311-L0x.405419896.listener.interval": "15",
+		"UT0311-L0x.405419896.timeout":           "5s",
+	}
+
+	delay := uint8(7)
+	interlock := types.Interlock12_34
+	antipassback := types.Readers13_24
+	format := types.Wiegand26
+	listener := netip.MustParseAddrPort("192.168.1.100:60001")
+	interval := uint8(15)
+
+	expected := DeviceSettings{
+		Doors: [4]DoorSettings{
+			{Mode: types.NormallyOpen},
+			{Delay: &delay},
+		},
+		Interlock:        &interlock,
+		AntiPassback:     &antipassback,
+		Keypads:          map[uint8]bool{1: true, 2: false, 3: true, 4: false},
+		CardFormat:       &format,
+		Listener:         &listener,
+		ListenerInterval: &interval,
+		Timeout:          5 * time.Second,
+	}
+
+	devices := DeviceMap{}
+	if _, err := devices.UnmarshalConf(tag, values); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if settings := devices[405419896].Settings; !reflect.DeepEqual(settings, expected) {
+		t.Errorf("Incorrect device settings\n   expected:%+v\n   got:     %+v", expected, settings)
+	}
+}
+
+func TestDeviceUnmarshalWithInvalidSettings(t *testing.T) {
+	tag := `/^UT0311-L0x\.([0-9]+)\.(.*)/`
+	tests := map[string]string{
+		"UT0311-L0x.405419896.door.1.mode":       "sometimes",
+		"UT0311-L0x.405419896.door.3.delay":      "300",
+		"UT0311-L0x.405419896.interlock":         "1&3",
+		"UT0311-L0x.405419896.anti-passback":     "1:4",
+		"UT0311-L0x.405419896.keypads":           "1, 5",
+		"UT0311-L0x.405419896.card.format":       "Wiegand-34",
+		"UT0311-L0x.405419896.listener":          "192.168.1.100",
+		"UT0311-L0x.405419896.listener.interval": "-1",
+		"UT0311-L0x.405419896.timeout":           "0s",
+	}
+
+	for key, value := range tests {
+		devices := DeviceMap{}
+
+		_, err := devices.UnmarshalConf(tag, map[string]string{key: value})
+
+		var cerr *conf.Error
+		if err == nil {
+			t.Errorf("Expected error for %v = %v, got:%v", key, value, err)
+		} else if !errors.As(err, &cerr) || cerr.Key != key {
+			t.Errorf("Incorrect error for %v = %v - got:%v", key, value, err)
+		}
+	}
+}
+
+func TestDeviceMarshalSettings(t *testing.T) {
+	expected := `# DEVICES
+UT0311-L0x.405419896.name = Alpha
+UT0311-L0x.405419896.door.1 = Gryffindor
+UT0311-L0x.405419896.door.2 = Ravenclaw
+UT0311-L0x.405419896.door.3 = Hufflepuff
+UT0311-L0x.405419896.door.4 = Slytherin
+UT0311-L0x.405419896.door.1.mode = normally closed
+UT0311-L0x.405419896.door.2.delay = 0
+UT0311-L0x.405419896.interlock = 1&2&3&4
+UT0311-L0x.405419896.keypads = none
+UT0311-L0x.405419896.timeout = 1.5s
+
+`
+	delay := uint8(0)
+	interlock := types.Interlock1234
+
+	devices := DeviceMap{
+		405419896: &Device{
+			Name:  "Alpha",
+			Doors: []string{"Gryffindor", "Ravenclaw", "Hufflepuff", "Slytherin"},
+			Settings: DeviceSettings{
+				Doors: [4]DoorSettings{
+					{Mode: types.NormallyClosed},
+					{Delay: &delay},
+				},
+				Interlock: &interlock,
+				Keypads:   map[uint8]bool{1: false, 2: false, 3: false, 4: false},
+				Timeout:   1500 * time.Millisecond,
+			},
+		},
+	}
+
+	bytes, err := devices.MarshalConf("devices")
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if string(bytes) != expected {
+		t.Errorf("Incorrectly marshalled device list\n   expected:%v\n   got:     %v", expected, string(bytes))
+	}
+
+	unmarshalled := DeviceMap{}
+	if _, err := unmarshalled.UnmarshalConf(`/^UT0311-L0x\.([0-9]+)\.(.*)/`, parse(bytes)); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if !reflect.DeepEqual(unmarshalled[405419896].Settings, devices[405419896].Settings) {
+		t.Errorf("Incorrect round trip settings\n   expected:%+v\n   got:     %+v", devices[405419896].Settings, unmarshalled[405419896].Settings)
+	}
+}
+
+func TestNewReconcileRequest(t *testing.T) {
+	delay := uint8(5)
+	antipassback := types.Readers1_234
+	format := types.Wiegand26
+
+	devices := DeviceMap{
+		405419896: &Device{
+			Name: "Alpha",
+			Settings: DeviceSettings{
+				Doors: [4]DoorSettings{
+					{},
+					{},
+					{Mode: types.Controlled, Delay: &delay},
+				},
+				AntiPassback: &antipassback,
+			},
+		},
+		303986753: &Device{
+			Name: "Beta",
+			Settings: DeviceSettings{
+				CardFormat: &format,
+				Timeout:    time.Second,
+			},
+		},
+	}
+
+	expected := uhppoted.ReconcileRequest{
+		Controllers: map[uint32]uhppoted.ControllerSettings{
+			405419896: {
+				Doors: map[uint8]uhppoted.DoorSettings{
+					3: {Mode: types.Controlled, Delay: &delay},
+				},
+				AntiPassback: &antipassback,
+			},
+		},
+		DryRun: true,
+	}
+
+	if request := NewReconcileRequest(devices, true); !reflect.DeepEqual(request, expected) {
+		t.Errorf("Incorrect reconcile request\n   expected:%+v\n   got:     %+v", expected, request)
+	}
+}
+
+func TestDeviceTimeout(t *testing.T) {
+	format := types.Wiegand26
+
+	c := NewConfig()
+	c.Timeout = 2 * time.Second
+	c.Devices = DeviceMap{
+		405419896: &Device{Settings: DeviceSettings{Timeout: 5 * time.Second, CardFormat: &format}},
+		303986753: &Device{},
+	}
+
+	if timeout := c.DeviceTimeout(405419896); timeout != 5*time.Second {
+		t.Errorf("Incorrect device timeout - expected:%v, got:%v", 5*time.Second, timeout)
+	}
+
+	if timeout := c.DeviceTimeout(303986753); timeout != 2*time.Second {
+		t.Errorf("Incorrect device timeout - expected:%v, got:%v", 2*time.Second, timeout)
+	}
+
+	if f := c.DeviceCardFormat(405419896); f != types.Wiegand26 {
+		t.Errorf("Incorrect device card format - expected:%v, got:%v", types.Wiegand26, f)
+	}
+
+	if f := c.DeviceCardFormat(201020304); f != c.CardFormat {
+		t.Errorf("Incorrect device card format - expected:%v, got:%v", c.CardFormat, f)
+	}
+}
+
+func parse(b []byte) map[string]string {
+	values := map[string]string{}
+
+	for line := range strings.SplitSeq(string(b), "\n") {
+		if key, value, ok := strings.Cut(line, " = "); ok {
+			values[key] = value
+		}
+	}
+
+	return values
+}
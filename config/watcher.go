@@ -0,0 +1,237 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppoted-lib/log"
+)
+
+// Default interval between checks for a modified configuration file.
+const WATCH_INTERVAL = 2500 * time.Millisecond
+
+// Watcher reloads a configuration file when it (or an included or drop-in file) is modified and
+// notifies the subscribers with the new configuration and the differences from the previous
+// configuration. A configuration file that cannot be read or fails validation is logged and
+// ignored, retaining the last good configuration.
+//
+// The file is polled (rather than using e.g. fsnotify) for the same reasons as the
+// KeyValueStore Watch.
+type Watcher struct {
+	path        string
+	config      *Config
+	files       []string
+	modified    string
+	subscribers []func(*Config, Diff)
+	stop        chan struct{}
+	guard       sync.Mutex
+}
+
+// Loads and validates the configuration file, returning a Watcher for the file.
+func NewWatcher(path string) (*Watcher, error) {
+	c := NewConfig()
+	files, err := c.load(path)
+	if err != nil {
+		return nil, err
+	} else if err := c.hmac(); err != nil {
+		return nil, err
+	} else if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	modified, err := fingerprint(path, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		path:        path,
+		config:      c,
+		files:       files,
+		modified:    modified,
+		subscribers: []func(*Config, Diff){},
+	}, nil
+}
+
+// Returns the last good configuration. The configuration is replaced rather than updated
+// on reload and should not be modified.
+func (w *Watcher) Config() *Config {
+	w.guard.Lock()
+	defer w.guard.Unlock()
+
+	return w.config
+}
+
+// Adds a function to be invoked with the new configuration and the differences from the
+// previous configuration whenever the configuration is reloaded.
+func (w *Watcher) Subscribe(handler func(*Config, Diff)) {
+	w.guard.Lock()
+	defer w.guard.Unlock()
+
+	w.subscribers = append(w.subscribers, handler)
+}
+
+// Reloads the configuration if any of the files have been modified since it was last loaded. Returns
+// true if the configuration was reloaded and changed. The subscribers are only notified if
+// the reloaded configuration differs from the current configuration.
+func (w *Watcher) Check() (bool, error) {
+	w.guard.Lock()
+	files := w.files
+	current := w.config
+	w.guard.Unlock()
+
+	modified, err := fingerprint(w.path, files)
+	if err != nil {
+		return false, err
+	}
+
+	w.guard.Lock()
+	unchanged := modified == w.modified
+	w.guard.Unlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	c, files, err := w.load(current)
+
+	w.guard.Lock()
+	w.modified = modified
+	if files != nil {
+		w.files = files
+	}
+
+	if err != nil {
+		w.guard.Unlock()
+		return false, err
+	}
+
+	diff := Compare(current, c)
+	if diff.IsEmpty() {
+		w.guard.Unlock()
+		return false, nil
+	}
+
+	w.config = c
+	subscribers := slices.Clone(w.subscribers)
+	w.guard.Unlock()
+
+	for _, f := range subscribers {
+		f(c, diff)
+	}
+
+	return true, nil
+}
+
+// Starts a goroutine to check the configuration file for modifications at the interval (or
+// WATCH_INTERVAL if the interval is 0) until Close is invoked.
+func (w *Watcher) Watch(interval time.Duration) {
+	if interval <= 0 {
+		interval = WATCH_INTERVAL
+	}
+
+	w.guard.Lock()
+	if w.stop != nil {
+		w.guard.Unlock()
+		return
+	}
+
+	stop := make(chan struct{})
+	w.stop = stop
+	w.guard.Unlock()
+
+	go func() {
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+
+		logged := false
+		for {
+			select {
+			case <-stop:
+				return
+
+			case <-tick.C:
+				if updated, err := w.Check(); err != nil {
+					if !logged {
+						log.Errorf("failed to reload configuration from %s: %v", w.path, err)
+						logged = true
+					}
+				} else {
+					logged = false
+					if updated {
+						log.Infof("reloaded configuration from %s", w.path)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stops watching the configuration file.
+func (w *Watcher) Close() error {
+	w.guard.Lock()
+	defer w.guard.Unlock()
+
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+
+	return nil
+}
+
+// Reads and validates the configuration. A missing MQTT HMAC key retains the previous
+// (generated) key rather than generating a new one.
+func (w *Watcher) load(current *Config) (*Config, []string, error) {
+	c := NewConfig()
+	files, err := c.load(w.path)
+	if err != nil {
+		return nil, files, err
+	}
+
+	if c.MQTT.HMAC.Key == "" {
+		c.MQTT.HMAC.Key = current.MQTT.HMAC.Key
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, files, fmt.Errorf("invalid configuration (%w)", err)
+	}
+
+	return c, files, nil
+}
+
+// Returns a 'fingerprint' of the modification times of the configuration file, the drop-in
+// directory and the files read by the previous load, which changes if any of the files are
+// modified, added or removed.
+func fingerprint(path string, files []string) (string, error) {
+	var b strings.Builder
+
+	finfo, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(&b, "%v:%v;", path, finfo.ModTime().UnixNano())
+
+	dropins := filepath.Join(filepath.Dir(path), DROPIN_DIR)
+	list := []string{}
+	for _, ext := range []string{"*.conf", "*.json", "*.toml"} {
+		matches, _ := filepath.Glob(filepath.Join(dropins, ext))
+		list = append(list, matches...)
+	}
+
+	for _, file := range append(list, files...) {
+		if finfo, err := os.Stat(file); err == nil {
+			fmt.Fprintf(&b, "%v:%v;", file, finfo.ModTime().UnixNano())
+		} else {
+			fmt.Fprintf(&b, "%v:-;", file)
+		}
+	}
+
+	return b.String(), nil
+}
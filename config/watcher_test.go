@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	original := `# SYSTEM
+timeout = 5s
+
+# DEVICES
+UT0311-L0x.405419896.door.1 = Front Door
+UT0311-L0x.405419896.door.2 = Side Door
+UT0311-L0x.303986753.door.1 = Garage
+`
+
+	updated := `# SYSTEM
+timeout = 2s
+
+# DEVICES
+UT0311-L0x.405419896.door.1 = Front Door
+UT0311-L0x.405419896.door.2 = Back Door
+UT0311-L0x.201020304.door.1 = Workshop
+`
+
+	invalid := `# DEVICES
+UT0311-L0x.405419896.door.1 = Front Door
+UT0311-L0x.201020304.door.1 = Front Door
+`
+
+	path := filepath.Join(t.TempDir(), "uhppoted.conf")
+	modified := time.Now().Add(-time.Hour)
+
+	write := func(s string) {
+		if err := os.WriteFile(path, []byte(s), 0644); err != nil {
+			t.Fatalf("Error writing test configuration (%v)", err)
+		}
+
+		modified = modified.Add(time.Minute)
+		os.Chtimes(path, modified, modified)
+	}
+
+	write(original)
+
+	w, err := NewWatcher(path)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	diffs := []Diff{}
+	w.Subscribe(func(c *Config, diff Diff) {
+		diffs = append(diffs, diff)
+	})
+
+	if reloaded, err := w.Check(); err != nil || reloaded {
+		t.Errorf("Unexpected reload of unmodified configuration (%v,%v)", reloaded, err)
+	}
+
+	// ... valid update
+	write(updated)
+
+	if reloaded, err := w.Check(); err != nil || !reloaded {
+		t.Fatalf("Expected reload of modified configuration (%v,%v)", reloaded, err)
+	}
+
+	expected := Diff{
+		Sections: map[string][]Change{
+			"system": {{Key: "timeout", Old: "5s", New: "2s"}},
+		},
+		Devices: Changes[uint32]{
+			Added:   []uint32{201020304},
+			Removed: []uint32{303986753},
+			Changed: []uint32{405419896},
+		},
+	}
+
+	if len(diffs) != 1 {
+		t.Fatalf("Expected 1 notification, got %v", len(diffs))
+	} else if !reflect.DeepEqual(diffs[0], expected) {
+		t.Errorf("Incorrect diff\n   expected:%+v\n   got:     %+v", expected, diffs[0])
+	}
+
+	// ... invalid update
+	write(invalid)
+
+	if reloaded, err := w.Check(); err == nil || reloaded {
+		t.Errorf("Expected error reloading invalid configuration (%v,%v)", reloaded, err)
+	}
+
+	if c := w.Config(); c.Timeout != 2*time.Second || c.Devices[405419896].Doors[1] != "Back Door" {
+		t.Errorf("Expected last good configuration, got %+v", c)
+	}
+
+	if len(diffs) != 1 {
+		t.Errorf("Unexpected notification for invalid configuration %+v", diffs)
+	}
+}
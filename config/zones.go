@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ZoneMap maps a zone name to the doors in the zone, defined in the conf file as e.g.
+//
+//	zone.lab = Lab A, Lab B, Store
+type ZoneMap map[string][]string
+
+func (z ZoneMap) MarshalConf(tag string) ([]byte, error) {
+	var s strings.Builder
+
+	if len(z) > 0 {
+		zones := []string{}
+		for k := range z {
+			zones = append(zones, k)
+		}
+
+		slices.Sort(zones)
+
+		fmt.Fprintf(&s, "# ZONES\n")
+		for _, zone := range zones {
+			fmt.Fprintf(&s, "zone.%s = %s\n", zone, strings.Join(z[zone], ", "))
+		}
+		fmt.Fprintf(&s, "\n")
+	}
+
+	return []byte(s.String()), nil
+}
+
+func (z *ZoneMap) UnmarshalConf(tag string, values map[string]string) (any, error) {
+	re := regexp.MustCompile(`^/(.*?)/$`)
+	match := re.FindStringSubmatch(tag)
+	if len(match) < 2 {
+		return z, fmt.Errorf("invalid 'conf' regular expression tag: %s", tag)
+	}
+
+	re, err := regexp.Compile(match[1])
+	if err != nil {
+		return z, err
+	}
+
+	if *z == nil {
+		*z = ZoneMap{}
+	}
+
+	for key, value := range values {
+		if match := re.FindStringSubmatch(key); len(match) > 1 {
+			zone := strings.TrimSpace(match[1])
+			doors := []string{}
+
+			for door := range strings.SplitSeq(value, ",") {
+				if d := strings.TrimSpace(door); d != "" {
+					doors = append(doors, d)
+				}
+			}
+
+			(*z)[zone] = doors
+		}
+	}
+
+	return z, nil
+}
+
+// Verifies that every zone has at least one door, that all zone doors are defined in the device
+// configuration and that zone names are unique and not ambiguous with door names.
+func (z ZoneMap) validate(devices DeviceMap) error {
+	normalise := func(s string) string {
+		return strings.ReplaceAll(strings.ToLower(s), " ", "")
+	}
+
+	doors := map[string]bool{}
+	for _, device := range devices {
+		if device != nil {
+			for _, door := range device.Doors {
+				if d := normalise(door); d != "" {
+					doors[d] = true
+				}
+			}
+		}
+	}
+
+	zones := map[string]string{}
+	for _, zone := range slices.Sorted(maps.Keys(z)) {
+		list := z[zone]
+		key := "zone." + zone
+		k := normalise(zone)
+
+		if k == "" {
+			return invalid(key, "invalid zone name '%s'", zone)
+		} else if other, ok := zones[k]; ok {
+			return invalid(key, "zone '%s' is defined more than once in configuration ('%s')", zone, other)
+		} else if doors[k] {
+			return invalid(key, "zone '%s' has the same name as a door", zone)
+		} else if len(list) == 0 {
+			return invalid(key, "zone '%s' does not have any doors", zone)
+		}
+
+		zones[k] = zone
+
+		for _, door := range list {
+			if !doors[normalise(door)] {
+				return invalid(key, "zone '%s': door '%s' is not defined in the device configuration", zone, door)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/uhppoted/uhppoted-lib/encoding/conf"
+)
+
+func TestZonesUnmarshal(t *testing.T) {
+	configuration := []byte(`# DEVICES
+UT0311-L0x.405419896.door.1 = Lab A
+UT0311-L0x.405419896.door.2 = Lab B
+UT0311-L0x.405419896.door.3 = Store
+UT0311-L0x.405419896.door.4 = Front Door
+
+# ZONES
+zone.lab = Lab A, Lab B,  Store
+zone.front = Front Door
+`)
+
+	expected := ZoneMap{
+		"lab":   []string{"Lab A", "Lab B", "Store"},
+		"front": []string{"Front Door"},
+	}
+
+	config := NewConfig()
+	if err := conf.Unmarshal(configuration, config); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(config.Zones, expected) {
+		t.Errorf("Incorrectly unmarshalled zones\n   expected:%v\n   got:     %v", expected, config.Zones)
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestZonesMarshal(t *testing.T) {
+	zones := ZoneMap{
+		"lab":   []string{"Lab A", "Lab B", "Store"},
+		"front": []string{"Front Door"},
+	}
+
+	expected := `# ZONES
+zone.front = Front Door
+zone.lab = Lab A, Lab B, Store
+
+`
+
+	if bytes, err := zones.MarshalConf(""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	} else if string(bytes) != expected {
+		t.Errorf("Incorrectly marshalled zones\n   expected:%v\n   got:     %v", expected, string(bytes))
+	}
+}
+
+func TestZonesValidate(t *testing.T) {
+	tests := []struct {
+		zones    string
+		expected error
+	}{
+		{"zone.lab = Lab A, Lab C", fmt.Errorf("zone.lab: zone 'lab': door 'Lab C' is not defined in the device configuration")},
+		{"zone.lab a = Lab A", fmt.Errorf("zone.lab a: zone 'lab a' has the same name as a door")},
+		{"zone.lab = ", fmt.Errorf("zone.lab: zone 'lab' does not have any doors")},
+	}
+
+	for _, test := range tests {
+		configuration := []byte(`# DEVICES
+UT0311-L0x.405419896.door.1 = Lab A
+UT0311-L0x.405419896.door.2 = Lab B
+` + test.zones + "\n")
+
+		config := NewConfig()
+		if err := conf.Unmarshal(configuration, config); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if err := config.Validate(); err == nil || err.Error() != test.expected.Error() {
+			t.Errorf("Expected error:%v, got:%v", test.expected, err)
+		}
+	}
+}
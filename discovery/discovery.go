@@ -0,0 +1,275 @@
+// Package discovery finds the controllers on a network by combining a 'get-devices' broadcast
+// with unicast 'get-devices' probes to the hosts in a list of CIDR ranges (e.g. for controllers
+// on subnets that are not reachable by broadcast), and compares the controllers found with the
+// configured controllers.
+package discovery
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+
+	"github.com/uhppoted/uhppoted-lib/config"
+	lib "github.com/uhppoted/uhppoted-lib/os"
+)
+
+// Default UDP port for unicast probes.
+const PORT = 60000
+
+// Default number of concurrent unicast probes.
+const CONCURRENCY = 32
+
+// Maximum number of hosts that can be probed in a single discovery.
+const MAX_HOSTS = 65536
+
+// Probe sends a 'get-devices' request to a single address and returns the controllers that
+// replied.
+type Probe func(address netip.AddrPort) ([]types.Device, error)
+
+// Options is the discovery configuration. A zero Port or Concurrency is replaced by the
+// default value.
+type Options struct {
+	Ranges      []netip.Prefix // CIDR ranges to probe by unicast
+	Port        uint16         // controller UDP port for unicast probes
+	Concurrency int            // maximum concurrent unicast probes
+}
+
+// Controller is a discovered controller.
+type Controller struct {
+	DeviceID   uint32         `json:"device-id"`
+	Address    netip.AddrPort `json:"address"`
+	Netmask    netip.Addr     `json:"netmask"`
+	Gateway    netip.Addr     `json:"gateway"`
+	MAC        string         `json:"MAC"`
+	Version    string         `json:"version"`
+	Broadcast  bool           `json:"broadcast"`  // found by the broadcast request
+	Configured bool           `json:"configured"` // listed in the configuration DeviceMap
+	Device     *config.Device `json:"-"`
+}
+
+// Result is the merged list of discovered controllers (ordered by controller ID), along with
+// the IDs of the controllers that are not in the configuration (New) and the IDs of the
+// configured controllers that were not found (Missing).
+type Result struct {
+	Controllers []Controller `json:"controllers"`
+	New         []uint32     `json:"new"`
+	Missing     []uint32     `json:"missing"`
+	Errors      []error      `json:"-"`
+}
+
+// Broadcasts a 'get-devices' request using the IUHPPOTE and probes each host in the CIDR ranges
+// with a unicast 'get-devices' request, and then compares the controllers found with the
+// configured controllers. Probe errors (other than timeouts, which are expected for hosts
+// that are not controllers) are returned in the Result Errors.
+func Discover(u uhppote.IUHPPOTE, probe Probe, devices config.DeviceMap, options Options) (*Result, error) {
+	port := options.Port
+	if port == 0 {
+		port = PORT
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = CONCURRENCY
+	}
+
+	hosts, err := expand(options.Ranges, port)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[uint32]*Controller{}
+	errs := []error{}
+	guard := sync.Mutex{}
+
+	merge := func(list []types.Device, broadcast bool) {
+		guard.Lock()
+		defer guard.Unlock()
+
+		for _, d := range list {
+			id := uint32(d.SerialNumber)
+			c, ok := found[id]
+			if !ok {
+				c = controller(d)
+				found[id] = c
+			}
+
+			c.Broadcast = c.Broadcast || broadcast
+		}
+	}
+
+	// ... broadcast
+	if u != nil {
+		if list, err := u.GetDevices(); err != nil {
+			errs = append(errs, fmt.Errorf("get-devices: %w", err))
+		} else {
+			merge(list, true)
+		}
+	}
+
+	// ... unicast
+	if probe != nil && len(hosts) > 0 {
+		var wg sync.WaitGroup
+
+		queue := make(chan netip.AddrPort)
+		for range min(concurrency, len(hosts)) {
+			wg.Go(func() {
+				for address := range queue {
+					if list, err := probe(address); err != nil && !lib.IsTimeout(err) {
+						guard.Lock()
+						errs = append(errs, fmt.Errorf("%v: %w", address, err))
+						guard.Unlock()
+					} else {
+						merge(list, false)
+					}
+				}
+			})
+		}
+
+		for _, address := range hosts {
+			queue <- address
+		}
+
+		close(queue)
+		wg.Wait()
+	}
+
+	// ... compare with configuration
+	result := Result{
+		Controllers: []Controller{},
+		New:         []uint32{},
+		Missing:     []uint32{},
+		Errors:      errs,
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(found)) {
+		c := found[id]
+		if d, ok := devices[id]; ok && d != nil {
+			c.Configured = true
+			c.Device = d
+		} else {
+			result.New = append(result.New, id)
+		}
+
+		result.Controllers = append(result.Controllers, *c)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(devices)) {
+		if _, ok := found[id]; !ok {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+
+	return &result, nil
+}
+
+// Returns a DeviceMap for the controllers that are not in the configuration, with the unicast
+// address for controllers that were not found by the broadcast request.
+func (r Result) DeviceMap() config.DeviceMap {
+	devices := config.DeviceMap{}
+
+	for _, c := range r.Controllers {
+		if !c.Configured {
+			d := config.Device{
+				Name:  fmt.Sprintf("%v", c.DeviceID),
+				Doors: make([]string, 4),
+			}
+
+			if !c.Broadcast && c.Address.IsValid() {
+				d.Address = types.ControllerAddrFrom(c.Address.Addr(), c.Address.Port())
+				d.Protocol = "udp"
+			}
+
+			devices[c.DeviceID] = &d
+		}
+	}
+
+	return devices
+}
+
+// Returns the DeviceMap of the new controllers as a 'conf' file fragment that can be merged
+// into uhppoted.conf.
+func (r Result) Conf() ([]byte, error) {
+	return r.DeviceMap().MarshalConf("")
+}
+
+func controller(d types.Device) *Controller {
+	c := Controller{
+		DeviceID: uint32(d.SerialNumber),
+		Address:  d.Address,
+		MAC:      fmt.Sprintf("%v", d.MacAddress),
+		Version:  fmt.Sprintf("%v", d.Version),
+	}
+
+	if !c.Address.IsValid() {
+		if addr, ok := netip.AddrFromSlice(d.IpAddress.To4()); ok {
+			c.Address = netip.AddrPortFrom(addr, PORT)
+		}
+	}
+
+	if addr, ok := netip.AddrFromSlice(d.SubnetMask.To4()); ok {
+		c.Netmask = addr
+	}
+
+	if addr, ok := netip.AddrFromSlice(d.Gateway.To4()); ok {
+		c.Gateway = addr
+	}
+
+	return &c
+}
+
+// Expands the CIDR ranges to the list of (unique) host addresses, excluding the network and
+// broadcast addresses of IPv4 ranges larger than /31.
+func expand(ranges []netip.Prefix, port uint16) ([]netip.AddrPort, error) {
+	hosts := []netip.AddrPort{}
+	seen := map[netip.Addr]bool{}
+
+	for _, prefix := range ranges {
+		if !prefix.IsValid() || !prefix.Addr().Is4() {
+			return nil, fmt.Errorf("invalid IPv4 CIDR range '%v'", prefix)
+		}
+
+		prefix = prefix.Masked()
+		bits := 32 - prefix.Bits()
+		if bits > 16 {
+			return nil, fmt.Errorf("CIDR range '%v' exceeds the maximum of %v hosts", prefix, MAX_HOSTS)
+		}
+
+		N := 1 << bits
+		addr := prefix.Addr()
+		for i := range N {
+			if bits < 2 || (i != 0 && i != N-1) {
+				if !seen[addr] {
+					seen[addr] = true
+					hosts = append(hosts, netip.AddrPortFrom(addr, port))
+				}
+			}
+
+			addr = addr.Next()
+		}
+
+		if len(hosts) > MAX_HOSTS {
+			return nil, fmt.Errorf("CIDR ranges exceed the maximum of %v hosts", MAX_HOSTS)
+		}
+	}
+
+	return hosts, nil
+}
+
+// Returns a Probe that sends each unicast 'get-devices' request from an ephemeral UDP port on the
+// bind address, using a uhppote-core IUHPPOTE.
+func NewProbe(bind netip.Addr, timeout time.Duration, debug bool) Probe {
+	return func(address netip.AddrPort) ([]types.Device, error) {
+		bind := types.BindAddrFrom(bind, 0)
+		broadcast := types.BroadcastAddrFrom(address.Addr(), address.Port())
+		listen := types.ListenAddrFrom(netip.IPv4Unspecified(), 0)
+		u := uhppote.NewUHPPOTE(bind, broadcast, listen, timeout, nil, debug)
+
+		return u.GetDevices()
+	}
+}
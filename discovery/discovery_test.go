@@ -0,0 +1,117 @@
+package discovery
+
+import (
+	"net/netip"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/uhppoted/uhppote-core/types"
+
+	"github.com/uhppoted/uhppoted-lib/config"
+	"github.com/uhppoted/uhppoted-lib/simulator"
+)
+
+func TestDiscover(t *testing.T) {
+	local := simulator.NewSimulator(
+		simulator.NewController(405419896, "Alpha"),
+		simulator.NewController(303986753, "Beta"))
+
+	remote := simulator.NewController(201020304, "Gamma")
+	remote.Address = netip.MustParseAddrPort("10.0.1.10:60000")
+	routed := simulator.NewSimulator(remote)
+
+	probe := func(address netip.AddrPort) ([]types.Device, error) {
+		if address == remote.Address {
+			return routed.GetDevices()
+		}
+
+		return nil, os.ErrDeadlineExceeded
+	}
+
+	devices := config.DeviceMap{
+		405419896: &config.Device{Name: "Alpha"},
+		100000001: &config.Device{Name: "Delta"},
+	}
+
+	options := Options{
+		Ranges: []netip.Prefix{netip.MustParsePrefix("10.0.1.0/28")},
+	}
+
+	result, err := Discover(local, probe, devices, options)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if len(result.Errors) > 0 {
+		t.Errorf("Unexpected probe errors (%v)", result.Errors)
+	}
+
+	ids := []uint32{}
+	for _, c := range result.Controllers {
+		ids = append(ids, c.DeviceID)
+	}
+
+	if expected := []uint32{201020304, 303986753, 405419896}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Incorrect controllers - expected:%v, got:%v", expected, ids)
+	}
+
+	if expected := []uint32{201020304, 303986753}; !reflect.DeepEqual(result.New, expected) {
+		t.Errorf("Incorrect 'new' controllers - expected:%v, got:%v", expected, result.New)
+	}
+
+	if expected := []uint32{100000001}; !reflect.DeepEqual(result.Missing, expected) {
+		t.Errorf("Incorrect 'missing' controllers - expected:%v, got:%v", expected, result.Missing)
+	}
+
+	if c := result.Controllers[0]; c.Broadcast || c.Configured || c.Address != remote.Address {
+		t.Errorf("Incorrect unicast controller %+v", c)
+	}
+
+	expected := `# DEVICES
+UT0311-L0x.201020304.name = 201020304
+UT0311-L0x.201020304.address = udp:10.0.1.10
+UT0311-L0x.201020304.door.1 = 
+UT0311-L0x.201020304.door.2 = 
+UT0311-L0x.201020304.door.3 = 
+UT0311-L0x.201020304.door.4 = 
+
+UT0311-L0x.303986753.name = 303986753
+UT0311-L0x.303986753.door.1 = 
+UT0311-L0x.303986753.door.2 = 
+UT0311-L0x.303986753.door.3 = 
+UT0311-L0x.303986753.door.4 = 
+
+`
+
+	if conf, err := result.Conf(); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if string(conf) != expected {
+		t.Errorf("Incorrect conf fragment\n   expected:%q\n   got:     %q", expected, string(conf))
+	}
+}
+
+func TestExpand(t *testing.T) {
+	ranges := []netip.Prefix{
+		netip.MustParsePrefix("192.168.1.100/30"),
+		netip.MustParsePrefix("192.168.1.101/32"),
+		netip.MustParsePrefix("10.0.0.8/31"),
+	}
+
+	expected := []netip.AddrPort{
+		netip.MustParseAddrPort("192.168.1.101:60000"),
+		netip.MustParseAddrPort("192.168.1.102:60000"),
+		netip.MustParseAddrPort("10.0.0.8:60000"),
+		netip.MustParseAddrPort("10.0.0.9:60000"),
+	}
+
+	if hosts, err := expand(ranges, 60000); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("Incorrect hosts\n   expected:%v\n   got:     %v", expected, hosts)
+	}
+
+	if _, err := expand([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, 60000); err == nil {
+		t.Errorf("Expected error for oversized CIDR range")
+	}
+}
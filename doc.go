@@ -28,5 +28,11 @@ The library consists of the following packages:
   - [uhppoted-lib/log] which implements the common logging format used by other uhppoted modules.
   - [uhppoted-lib/lockfile] which implements the lockfiles used to ensure single active instances of an application.
   - [uhppoted-lib/monitoring] which implements the system health and watchdog functionality.
+  - [uhppoted-lib/simulator] which implements an in-memory simulated controller 'fleet' for testing.
+  - [uhppoted-lib/retry] which wraps a uhppote-core IUHPPOTE with retries and per-controller circuit breakers.
+  - [uhppoted-lib/scheduler] which wraps a uhppote-core IUHPPOTE with per-controller request scheduling.
+  - [uhppoted-lib/metrics] which implements the library metrics and a Prometheus exporter.
+  - [uhppoted-lib/tracing] which defines the tracer interface for tracing multi-step operations.
+  - [uhppoted-lib/discovery] which finds controllers by broadcast and unicast subnet probes.
 */
 package lib
@@ -3,12 +3,12 @@ package conf
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"reflect"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
@@ -54,7 +54,6 @@ func Marshal(m any) ([]byte, error) {
 }
 
 func marshal(s reflect.Value) ([]byte, error) {
-	println("marshal")
 	var c strings.Builder
 
 	if s.Kind() == reflect.Struct {
@@ -90,7 +89,7 @@ func marshal(s reflect.Value) ([]byte, error) {
 			}
 
 			// Marshal embedded structs
-			if f.Kind() == reflect.Struct {
+			if f.Kind() == reflect.Struct && !isValue(t.Type) {
 				if v, err := marshal(f); err != nil {
 					return []byte(c.String()), err
 				} else {
@@ -109,50 +108,13 @@ func marshal(s reflect.Value) ([]byte, error) {
 				continue
 			}
 
-			// Marshal built-in types
-
-			switch t.Type {
-			case tBool:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case tByte:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case tInt:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case tUint:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case tUint16:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case tUint32:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case tUint64:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case tString:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case tDuration:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case pBindAddr:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case pBroadcastAddr:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case pListenAddr:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			case pUDPAddr:
-				fmt.Fprintf(&c, "%s = %v\n", tag, f)
-
-			default:
-				panic(fmt.Errorf("cannot marshal field with type '%v'", t.Type))
+			// Marshal maps, slices and values
+			if lines, err := marshalField(tag, f); err != nil {
+				return []byte(c.String()), err
+			} else {
+				for _, line := range lines {
+					fmt.Fprintf(&c, "%s\n", line)
+				}
 			}
 		}
 	}
@@ -167,27 +129,69 @@ func Unmarshal(b []byte, m any) error {
 		return fmt.Errorf("cannot unmarshal %s: expected 'struct'", s.Kind())
 	}
 
-	values, err := parse(bytes.NewBuffer(b))
+	values, err := parse(FormatOf("", b), bytes.NewBuffer(b))
 	if err != nil {
 		return err
 	}
 
+	if values, err = resolve(values); err != nil {
+		return err
+	}
+
 	return unmarshal(s, "", values)
 }
 
-func parse(r io.Reader) (map[string]string, error) {
-	re := regexp.MustCompile(`^\s*(.*?)\s*=\s*(.*)\s*$`)
+func parse(format Format, r io.Reader) (map[string]string, error) {
 	m := make(map[string]string)
+
+	err := decode(format, r, func(key, value string, line int) error {
+		m[key] = value
+		return nil
+	}, nil)
+
+	return m, err
+}
+
+// Invokes f for each key = value line, ignoring blank lines and comment lines (i.e. lines starting
+// with # or ;). Any other lines are reported to the (optional) malformed function.
+func scan(r io.Reader, f func(key, value string, line int) error, malformed func(line int, text string)) error {
+	re := regexp.MustCompile(`^\s*(.*?)\s*=\s*(.*)\s*$`)
 	s := bufio.NewScanner(r)
+	line := 0
 
 	for s.Scan() {
-		match := re.FindStringSubmatch(s.Text())
-		if len(match) > 0 {
-			m[match[1]] = match[2]
+		line++
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		if match := re.FindStringSubmatch(s.Text()); len(match) > 0 {
+			if err := f(match[1], match[2], line); err != nil {
+				return err
+			}
+		} else if malformed != nil {
+			malformed(line, text)
 		}
 	}
 
-	return m, s.Err()
+	return s.Err()
+}
+
+// Unmarshals a set of key/value pairs (after expanding any ${ENV_VAR} references and reading
+// any file: values) into a struct.
+func UnmarshalValues(values map[string]string, m any) error {
+	s := reflect.ValueOf(m).Elem()
+	if s.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal %s: expected 'struct'", s.Kind())
+	}
+
+	values, err := resolve(values)
+	if err != nil {
+		return err
+	}
+
+	return unmarshal(s, "", values)
 }
 
 func unmarshal(s reflect.Value, prefix string, values map[string]string) error {
@@ -218,7 +222,7 @@ func unmarshal(s reflect.Value, prefix string, values map[string]string) error {
 		if u, ok := f.Addr().Interface().(Unmarshaler); ok {
 			p, err := u.UnmarshalConf(tag, values)
 			if err != nil {
-				return err
+				return keyError(tag, values, err)
 			}
 
 			f.Set(reflect.Indirect(reflect.ValueOf(p)))
@@ -229,7 +233,7 @@ func unmarshal(s reflect.Value, prefix string, values map[string]string) error {
 		if u, ok := f.Interface().(Unmarshaler); ok {
 			p, err := u.UnmarshalConf(tag, values)
 			if err != nil {
-				return err
+				return keyError(tag, values, err)
 			}
 
 			if p != nil {
@@ -239,7 +243,7 @@ func unmarshal(s reflect.Value, prefix string, values map[string]string) error {
 		}
 
 		// Unmarshal embedded structs
-		if f.Kind() == reflect.Struct {
+		if f.Kind() == reflect.Struct && !isValue(t.Type) {
 			if tag == "" {
 				if err := unmarshal(f, "", values); err != nil {
 					return err
@@ -252,135 +256,25 @@ func unmarshal(s reflect.Value, prefix string, values map[string]string) error {
 			continue
 		}
 
-		// Unmarshal built-in types
-		switch t.Type {
-		case tBool:
-			if value, ok := values[tag]; ok {
-				if value == "true" {
-					f.SetBool(true)
-				} else if value == "false" {
-					f.SetBool(false)
-				} else {
-					return fmt.Errorf("invalid boolean value: %s", value)
-				}
-			}
-
-		case tByte:
-			if value, ok := values[tag]; ok {
-				i, err := strconv.ParseUint(value, 10, 8)
-				if err != nil {
-					return err
-				}
-				f.SetUint(i)
-			}
-
-		case tInt:
-			if value, ok := values[tag]; ok {
-				i, err := strconv.ParseInt(value, 10, 0)
-				if err != nil {
-					return err
-				}
-				f.SetInt(i)
-			}
-
-		case tUint:
-			if value, ok := values[tag]; ok {
-				i, err := strconv.ParseUint(value, 10, 0)
-				if err != nil {
-					return err
-				}
-				f.SetUint(i)
-			}
-
-		case tUint16:
-			if value, ok := values[tag]; ok {
-				i, err := strconv.ParseUint(value, 10, 16)
-				if err != nil {
-					return err
-				}
-				f.SetUint(i)
-			}
-
-		case tUint32:
-			if value, ok := values[tag]; ok {
-				i, err := strconv.ParseUint(value, 10, 32)
-				if err != nil {
-					return err
-				}
-				f.SetUint(i)
-			}
-
-		case tUint64:
-			if value, ok := values[tag]; ok {
-				i, err := strconv.ParseUint(value, 10, 64)
-				if err != nil {
-					return err
-				}
-				f.SetUint(i)
-			}
-
-		case tString:
-			if value, ok := values[tag]; ok {
-				f.SetString(value)
-			}
-
-		case tDuration:
-			if value, ok := values[tag]; ok {
-				d, err := time.ParseDuration(value)
-				if err != nil {
-					return err
-				}
-				f.SetInt(int64(d))
-			}
-
-		case pBindAddr:
-			if value, ok := values[tag]; ok {
-				if v, err := types.ParseBindAddr(value); err != nil {
-					return err
-				} else {
-					f.Set(reflect.ValueOf(&v))
-				}
-			}
-
-		case pBroadcastAddr:
-			if value, ok := values[tag]; ok {
-				if v, err := types.ParseBroadcastAddr(value); err != nil {
-					return err
-				} else {
-					f.Set(reflect.ValueOf(&v))
-				}
-			}
-
-		case pListenAddr:
-			if value, ok := values[tag]; ok {
-				if v, err := types.ParseListenAddr(value); err != nil {
-					return err
-				} else {
-					f.Set(reflect.ValueOf(&v))
-				}
-			}
-
-		case pUDPAddr:
-			if value, ok := values[tag]; ok {
-				address, err := net.ResolveUDPAddr("udp", value)
-				if err != nil {
-					return err
-				}
+		// Unmarshal maps, slices and values
+		if err := unmarshalField(f, tag, values); err != nil {
+			return err
+		}
+	}
 
-				addr := net.UDPAddr{
-					IP:   make(net.IP, net.IPv4len),
-					Port: address.Port,
-					Zone: "",
-				}
+	return nil
+}
 
-				copy(addr.IP, address.IP.To4())
+// Wraps an Unmarshaler error for a field with a simple (i.e. not a regular expression) tag as
+// an *Error for the key, unless it is already an *Error.
+func keyError(tag string, values map[string]string, err error) error {
+	var cerr *Error
 
-				f.Set(reflect.ValueOf(&addr))
-			}
-		}
+	if _, ok := values[tag]; ok && !errors.As(err, &cerr) {
+		return &Error{Key: tag, Err: err}
 	}
 
-	return nil
+	return err
 }
 
 func Range(m any, g func(string, any) bool) {
@@ -432,7 +326,7 @@ func iterate(parent string, s reflect.Value, g func(string, any) bool) bool {
 			}
 
 			// Range over embedded structs
-			if f.Kind() == reflect.Struct {
+			if f.Kind() == reflect.Struct && !isValue(t.Type) {
 				if !iterate(tag, f, g) {
 					return false
 				}
@@ -527,7 +421,23 @@ func iterate(parent string, s reflect.Value, g func(string, any) bool) bool {
 				}
 
 			default:
-				panic(fmt.Errorf("cannot apply Range to field with type '%v'", t.Type))
+				err := flatten(tag, f, func(key string, v reflect.Value) error {
+					if isStruct(v.Type()) {
+						if !iterate(key, reflect.Indirect(v), g) {
+							return errStop
+						}
+					} else if !g(key, rangeValue(v)) {
+						return errStop
+					}
+
+					return nil
+				})
+
+				if errors.Is(err, errStop) {
+					return false
+				} else if err != nil {
+					panic(fmt.Errorf("cannot apply Range to field with type '%v'", t.Type))
+				}
 			}
 		}
 	}
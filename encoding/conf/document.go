@@ -0,0 +1,229 @@
+package conf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Name of the directive that includes another conf file (or the conf files that match a glob
+// pattern) e.g. include = devices.conf. Relative paths are resolved against the directory of
+// the file containing the directive.
+const INCLUDE = "include"
+
+// Document is the set of key/value pairs read from one or more conf files, along with the
+// file and line at which each value was (last) defined. Files are merged in the order in
+// which they are read, with later values replacing earlier values.
+type Document struct {
+	values    map[string]string
+	positions map[string]Position
+	files     []string
+	malformed []Position
+}
+
+// Position is the file and line number at which a value is defined, or the name of the
+// environment variable (as $NAME) for values overridden from the environment.
+type Position struct {
+	File string
+	Line int
+}
+
+// Error is an error in a conf file value, with the position of the value if known.
+type Error struct {
+	Position
+	Key string
+	Err error
+}
+
+func NewDocument() *Document {
+	return &Document{
+		values:    map[string]string{},
+		positions: map[string]Position{},
+		files:     []string{},
+		malformed: []Position{},
+	}
+}
+
+// Reads the key/value pairs from a conf file, expanding any 'include' directives in place.
+func (d *Document) Load(path string) error {
+	return d.load(path, Position{}, []string{})
+}
+
+// Reads the *.conf, *.json and *.toml files in a 'drop-in' directory (e.g. /etc/uhppoted/conf.d)
+// in lexical order. A directory that does not exist is ignored.
+func (d *Document) LoadDir(dir string) error {
+	files := []string{}
+	for _, ext := range []string{"*.conf", "*.json", "*.toml"} {
+		if matches, err := filepath.Glob(filepath.Join(dir, ext)); err != nil {
+			return err
+		} else {
+			files = append(files, matches...)
+		}
+	}
+
+	slices.Sort(files)
+
+	for _, file := range files {
+		if err := d.Load(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Returns the merged key/value pairs.
+func (d *Document) Values() map[string]string {
+	return maps.Clone(d.values)
+}
+
+// Returns the files that have been read, in the order in which they were read.
+func (d *Document) Files() []string {
+	return slices.Clone(d.files)
+}
+
+// Returns the positions of the lines that are neither blank, comments nor key = value pairs.
+func (d *Document) Malformed() []Position {
+	return slices.Clone(d.malformed)
+}
+
+// Returns the position at which the key was last defined.
+func (d *Document) Position(key string) (Position, bool) {
+	p, ok := d.positions[key]
+
+	return p, ok
+}
+
+// Unmarshals the merged key/value pairs (after expanding any ${ENV_VAR} references and reading
+// any file: values) into the struct, returning errors for invalid values
+// as an *Error with the position at which the value was defined.
+func (d *Document) Unmarshal(m any) error {
+	v := reflect.ValueOf(m)
+	s := v.Elem()
+	if s.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal %s: expected 'struct'", s.Kind())
+	}
+
+	values, err := resolve(d.values)
+	if err == nil {
+		err = unmarshal(s, "", values)
+	}
+
+	var cerr *Error
+	if errors.As(err, &cerr) && cerr.File == "" {
+		if p, ok := d.positions[cerr.Key]; ok {
+			cerr.Position = p
+		}
+	}
+
+	return err
+}
+
+func (d *Document) load(path string, from Position, stack []string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(stack, abs) {
+		return &Error{Position: from, Key: INCLUDE, Err: fmt.Errorf("circular include of %v", path)}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if from.File != "" {
+			return &Error{Position: from, Key: INCLUDE, Err: err}
+		}
+
+		return err
+	}
+
+	stack = append(stack, abs)
+	d.files = append(d.files, path)
+
+	err = decode(FormatOf(path, b), bytes.NewReader(b), func(key, value string, line int) error {
+		p := Position{File: path, Line: line}
+
+		if key != INCLUDE {
+			d.values[key] = value
+			d.positions[key] = p
+			return nil
+		}
+
+		// ... expand include directive in place
+		include := value
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		if !strings.ContainsAny(include, "*?[") {
+			return d.load(include, p, stack)
+		}
+
+		files, err := filepath.Glob(include)
+		if err != nil {
+			return &Error{Position: p, Key: INCLUDE, Err: err}
+		}
+
+		slices.Sort(files)
+		for _, file := range files {
+			if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() {
+				if err := d.load(file, p, stack); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}, func(line int, text string) {
+		d.malformed = append(d.malformed, Position{File: path, Line: line})
+	})
+
+	// ... JSON and TOML syntax errors
+	var cerr *Error
+	if errors.As(err, &cerr) && cerr.File == "" {
+		cerr.File = path
+	}
+
+	return err
+}
+
+func (p Position) String() string {
+	if p.File == "" && p.Line > 0 {
+		return fmt.Sprintf("line %v", p.Line)
+	} else if p.File == "" {
+		return ""
+	} else if p.Line == 0 {
+		return p.File
+	}
+
+	return fmt.Sprintf("%v:%v", p.File, p.Line)
+}
+
+func (e *Error) Error() string {
+	p := e.Position.String()
+
+	switch {
+	case p != "" && e.Key != "":
+		return fmt.Sprintf("%v: %v: %v", e.Position, e.Key, e.Err)
+
+	case p != "":
+		return fmt.Sprintf("%v: %v", e.Position, e.Err)
+
+	case e.Key != "":
+		return fmt.Sprintf("%v: %v", e.Key, e.Err)
+
+	default:
+		return fmt.Sprintf("%v", e.Err)
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
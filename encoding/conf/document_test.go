@@ -0,0 +1,146 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDocument struct {
+	Name    string        `conf:"name"`
+	Timeout time.Duration `conf:"timeout"`
+	MQTT    struct {
+		Broker string `conf:"broker"`
+		Client string `conf:"client"`
+	} `conf:"mqtt"`
+}
+
+func write(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Error creating test directory (%v)", err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Error creating test file (%v)", err)
+		}
+	}
+}
+
+func TestDocumentWithIncludes(t *testing.T) {
+	dir := t.TempDir()
+
+	write(t, dir, map[string]string{
+		"uhppoted.conf":  "name = main\ntimeout = 5s\ninclude = mqtt.conf\nmqtt.client = main\n",
+		"mqtt.conf":      "mqtt.broker = tcp://127.0.0.1:1883\nmqtt.client = included\nname = included\n",
+		"conf.d/20.conf": "timeout = 20s\n",
+		"conf.d/10.conf": "timeout = 10s\nname = drop-in\n",
+	})
+
+	d := NewDocument()
+	if err := d.Load(filepath.Join(dir, "uhppoted.conf")); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if err := d.LoadDir(filepath.Join(dir, "conf.d")); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	var v testDocument
+	if err := d.Unmarshal(&v); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if v.Name != "drop-in" || v.Timeout != 20*time.Second || v.MQTT.Broker != "tcp://127.0.0.1:1883" || v.MQTT.Client != "main" {
+		t.Errorf("Incorrectly merged values %+v", v)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "uhppoted.conf"),
+		filepath.Join(dir, "mqtt.conf"),
+		filepath.Join(dir, "conf.d", "10.conf"),
+		filepath.Join(dir, "conf.d", "20.conf"),
+	}
+
+	if files := d.Files(); !reflect.DeepEqual(files, expected) {
+		t.Errorf("Incorrect files\n   expected:%v\n   got:     %v", expected, files)
+	}
+
+	if p, ok := d.Position("mqtt.broker"); !ok || p.String() != filepath.Join(dir, "mqtt.conf")+":1" {
+		t.Errorf("Incorrect position for 'mqtt.broker' - got %v", p)
+	}
+}
+
+func TestDocumentWithGlobInclude(t *testing.T) {
+	dir := t.TempDir()
+
+	write(t, dir, map[string]string{
+		"uhppoted.conf":      "include = devices/*.conf\n",
+		"devices/alpha.conf": "name = alpha\n",
+		"devices/beta.conf":  "name = beta\n",
+	})
+
+	d := NewDocument()
+	if err := d.Load(filepath.Join(dir, "uhppoted.conf")); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if v := d.Values()["name"]; v != "beta" {
+		t.Errorf("Incorrect value - expected:%v, got:%v", "beta", v)
+	}
+}
+
+func TestDocumentInvalidValue(t *testing.T) {
+	dir := t.TempDir()
+
+	write(t, dir, map[string]string{
+		"uhppoted.conf": "name = main\ninclude = system.conf\n",
+		"system.conf":   "# SYSTEM\n\ntimeout = 5 seconds\n",
+	})
+
+	d := NewDocument()
+	if err := d.Load(filepath.Join(dir, "uhppoted.conf")); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	var v testDocument
+	var cerr *Error
+
+	err := d.Unmarshal(&v)
+	if !errors.As(err, &cerr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+
+	if cerr.File != filepath.Join(dir, "system.conf") || cerr.Line != 3 || cerr.Key != "timeout" {
+		t.Errorf("Incorrect error position - got %v", err)
+	}
+}
+
+func TestDocumentIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	write(t, dir, map[string]string{
+		"missing.conf":  "name = main\ninclude = nothing.conf\n",
+		"circular.conf": "name = main\n\ninclude = circular.conf\n",
+	})
+
+	tests := map[string]int{
+		"missing.conf":  2,
+		"circular.conf": 3,
+	}
+
+	for file, line := range tests {
+		var cerr *Error
+
+		err := NewDocument().Load(filepath.Join(dir, file))
+		if !errors.As(err, &cerr) {
+			t.Errorf("%v: expected *Error, got %v", file, err)
+		} else if cerr.File != filepath.Join(dir, file) || cerr.Line != line {
+			t.Errorf("%v: incorrect error position - expected:%v, got:%v", file, line, err)
+		}
+	}
+}
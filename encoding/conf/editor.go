@@ -0,0 +1,238 @@
+package conf
+
+import (
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Editor edits the key/value pairs in a conf file in place. Lines that are not changed (including
+// comments, blank lines, unknown keys and line endings) are preserved as is.
+type Editor struct {
+	lines []string
+}
+
+var (
+	kvLine      = regexp.MustCompile(`^(\s*)(.*?)(\s*=\s*)(.*?)(\r?)$`)
+	commentLine = regexp.MustCompile(`^\s*[#;]\s*(.*?)\s*=`)
+)
+
+func NewEditor(r io.Reader) (*Editor, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	e := Editor{
+		lines: []string{},
+	}
+
+	if len(b) > 0 {
+		e.lines = strings.Split(string(b), "\n")
+	}
+
+	return &e, nil
+}
+
+// Returns the (last defined) value for a key.
+func (e *Editor) Get(key string) (string, bool) {
+	if i := e.find(key); i >= 0 {
+		return kvLine.FindStringSubmatch(e.lines[i])[4], true
+	}
+
+	return "", false
+}
+
+// Returns the keys in the order in which they are (first) defined.
+func (e *Editor) Keys() []string {
+	keys := []string{}
+	for i := range e.lines {
+		if k, ok := e.key(i); ok && !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// Sets the value for a key. An existing key is updated in place (if it is defined more than
+// once, the last definition is updated). A new key is inserted after the commented out
+// definition of the key if there is one, or otherwise after the key with which it shares the
+// longest (dotted) prefix e.g. mqtt.connection.password after mqtt.connection.username. Keys
+// that have nothing in common with any existing key are appended to the end of the file.
+func (e *Editor) Set(key, value string) {
+	if i := e.find(key); i >= 0 {
+		match := kvLine.FindStringSubmatch(e.lines[i])
+		e.lines[i] = match[1] + match[2] + match[3] + value + match[5]
+		return
+	}
+
+	e.insert(e.anchor(key), strings.TrimSpace(key+" = "+value))
+}
+
+// Deletes all definitions of a key, returning false if the key is not defined.
+func (e *Editor) Delete(key string) bool {
+	return e.DeleteFunc(func(k string) bool { return k == key }) > 0
+}
+
+// Deletes all definitions of the keys for which the function returns true, returning the
+// number of lines deleted. The blank line separating a deleted block of keys from the following
+// lines is also removed.
+func (e *Editor) DeleteFunc(f func(key string) bool) int {
+	deleted := make([]bool, len(e.lines))
+	N := 0
+
+	for i := range e.lines {
+		if k, ok := e.key(i); ok && f(k) {
+			deleted[i] = true
+			N++
+		}
+	}
+
+	// ... remove the trailing blank line of a deleted block that follows a blank line or comment
+	for i := 0; i < len(e.lines); i++ {
+		if deleted[i] {
+			j := i
+			for j < len(e.lines) && deleted[j] {
+				j++
+			}
+
+			if (i == 0 || !e.isKey(i-1)) && j < len(e.lines)-1 && isBlank(e.lines[j]) {
+				deleted[j] = true
+			} else if i > 0 && isBlank(e.lines[i-1]) && j == len(e.lines)-1 && e.lines[j] == "" {
+				deleted[i-1] = true
+			}
+
+			i = j
+		}
+	}
+
+	lines := []string{}
+	for i, line := range e.lines {
+		if !deleted[i] {
+			lines = append(lines, line)
+		}
+	}
+
+	e.lines = lines
+
+	return N
+}
+
+// Inserts a block of lines after the last key for which the function returns true, separated
+// from the preceding lines by a blank line. The block is appended to the end of the file if
+// there is no matching key.
+func (e *Editor) InsertBlock(after func(key string) bool, lines ...string) {
+	index := -1
+	for i := range e.lines {
+		if k, ok := e.key(i); ok && after(k) {
+			index = i
+		}
+	}
+
+	block := append([]string{""}, lines...)
+	if index >= 0 {
+		e.lines = slices.Insert(e.lines, index+1, block...)
+	} else if N := len(e.lines); N == 0 || isBlank(e.lines[N-1]) && (N == 1 || isBlank(e.lines[N-2])) {
+		e.insert(-1, lines...)
+	} else {
+		e.insert(-1, block...)
+	}
+}
+
+// Returns the edited conf file.
+func (e *Editor) Bytes() []byte {
+	return []byte(strings.Join(e.lines, "\n"))
+}
+
+func (e *Editor) WriteTo(w io.Writer) (int64, error) {
+	N, err := w.Write(e.Bytes())
+
+	return int64(N), err
+}
+
+func (e *Editor) key(i int) (string, bool) {
+	line := strings.TrimSpace(e.lines[i])
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+		return "", false
+	}
+
+	if match := kvLine.FindStringSubmatch(e.lines[i]); match != nil && match[2] != "" {
+		return match[2], true
+	}
+
+	return "", false
+}
+
+func (e *Editor) isKey(i int) bool {
+	_, ok := e.key(i)
+
+	return ok
+}
+
+func (e *Editor) find(key string) int {
+	for i := len(e.lines) - 1; i >= 0; i-- {
+		if k, ok := e.key(i); ok && k == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Returns the index of the line after which to insert a new key, or -1 to append the key to
+// the end of the file.
+func (e *Editor) anchor(key string) int {
+	for i := len(e.lines) - 1; i >= 0; i-- {
+		if match := commentLine.FindStringSubmatch(e.lines[i]); match != nil && match[1] == key {
+			return i
+		}
+	}
+
+	index := -1
+	longest := 0
+	for i := range e.lines {
+		if k, ok := e.key(i); ok {
+			if n := prefix(key, k); n > 0 && n >= longest {
+				index = i
+				longest = n
+			}
+		}
+	}
+
+	return index
+}
+
+// Inserts lines after the line at index, or at the end of the file (before the final newline)
+// if index is -1.
+func (e *Editor) insert(index int, lines ...string) {
+	if index >= 0 {
+		e.lines = slices.Insert(e.lines, index+1, lines...)
+		return
+	}
+
+	N := len(e.lines)
+	if N > 0 && e.lines[N-1] == "" {
+		e.lines = slices.Insert(e.lines, N-1, lines...)
+	} else {
+		e.lines = append(e.lines, append(lines, "")...)
+	}
+}
+
+// Returns the number of leading dotted components common to both keys.
+func prefix(p, q string) int {
+	a := strings.Split(p, ".")
+	b := strings.Split(q, ".")
+	n := 0
+
+	for n < len(a)-1 && n < len(b)-1 && a[n] == b[n] {
+		n++
+	}
+
+	return n
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
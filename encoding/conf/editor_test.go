@@ -0,0 +1,135 @@
+package conf
+
+import (
+	"strings"
+	"testing"
+)
+
+const edit = `# SYSTEM
+bind.address = 192.168.1.100:54321
+; timeout = 2.5s
+
+# MQTT
+mqtt.connection.broker   =   tls://127.0.0.1:8883
+mqtt.connection.username = me
+mqtt.unknown.key = ignored
+
+# DEVICES
+UT0311-L0x.405419896.name = Alpha
+UT0311-L0x.405419896.door.1 = Front Door
+
+UT0311-L0x.303986753.name = Beta
+UT0311-L0x.303986753.door.1 = Back Door
+`
+
+func TestEditorWithoutChanges(t *testing.T) {
+	for _, s := range []string{edit, strings.ReplaceAll(edit, "\n", "\r\n"), "", "timeout = 5s"} {
+		e, err := NewEditor(strings.NewReader(s))
+		if err != nil {
+			t.Fatalf("Unexpected error (%v)", err)
+		}
+
+		if string(e.Bytes()) != s {
+			t.Errorf("Unedited file not preserved\n   expected:%q\n   got:     %q", s, string(e.Bytes()))
+		}
+	}
+}
+
+func TestEditorSet(t *testing.T) {
+	expected := `# SYSTEM
+bind.address = 192.168.1.100:54321
+; timeout = 2.5s
+timeout = 5s
+
+# MQTT
+mqtt.connection.broker   =   tls://127.0.0.1:1883
+mqtt.connection.username = me
+mqtt.connection.password = pickme
+mqtt.unknown.key = ignored
+
+# DEVICES
+UT0311-L0x.405419896.name = Alpha
+UT0311-L0x.405419896.door.1 = Front Door
+UT0311-L0x.405419896.door.2 = Side Door
+
+UT0311-L0x.303986753.name = Beta
+UT0311-L0x.303986753.door.1 = Back Door
+wild-apricot.http.client-timeout = 10s
+`
+
+	e, _ := NewEditor(strings.NewReader(edit))
+
+	e.Set("timeout", "5s")
+	e.Set("mqtt.connection.broker", "tls://127.0.0.1:1883")
+	e.Set("mqtt.connection.password", "pickme")
+	e.Set("UT0311-L0x.405419896.door.2", "Side Door")
+	e.Set("wild-apricot.http.client-timeout", "10s")
+
+	if s := string(e.Bytes()); s != expected {
+		t.Errorf("Incorrect edited file\n   expected:%v\n   got:     %v", expected, s)
+	}
+
+	if v, ok := e.Get("mqtt.connection.broker"); !ok || v != "tls://127.0.0.1:1883" {
+		t.Errorf("Incorrect value - expected:%v, got:%v", "tls://127.0.0.1:1883", v)
+	}
+}
+
+func TestEditorSetWithCRLF(t *testing.T) {
+	e, _ := NewEditor(strings.NewReader("timeout = 2.5s\r\nbind.address = 192.168.1.100\r\n"))
+
+	e.Set("timeout", "5s")
+
+	if s := string(e.Bytes()); s != "timeout = 5s\r\nbind.address = 192.168.1.100\r\n" {
+		t.Errorf("Incorrect edited file %q", s)
+	}
+}
+
+func TestEditorDelete(t *testing.T) {
+	expected := `# SYSTEM
+bind.address = 192.168.1.100:54321
+; timeout = 2.5s
+
+# MQTT
+mqtt.connection.broker   =   tls://127.0.0.1:8883
+mqtt.unknown.key = ignored
+
+# DEVICES
+UT0311-L0x.303986753.name = Beta
+UT0311-L0x.303986753.door.1 = Back Door
+`
+
+	e, _ := NewEditor(strings.NewReader(edit))
+
+	if !e.Delete("mqtt.connection.username") {
+		t.Errorf("Expected Delete to return true for existing key")
+	}
+
+	if e.Delete("mqtt.connection.password") {
+		t.Errorf("Expected Delete to return false for missing key")
+	}
+
+	if N := e.DeleteFunc(func(key string) bool { return strings.HasPrefix(key, "UT0311-L0x.405419896.") }); N != 2 {
+		t.Errorf("Incorrect number of deleted keys - expected:%v, got:%v", 2, N)
+	}
+
+	if s := string(e.Bytes()); s != expected {
+		t.Errorf("Incorrect edited file\n   expected:%v\n   got:     %v", expected, s)
+	}
+}
+
+func TestEditorInsertBlock(t *testing.T) {
+	expected := edit + `
+UT0311-L0x.201020304.name = Gamma
+UT0311-L0x.201020304.door.1 = Gate
+`
+
+	e, _ := NewEditor(strings.NewReader(edit))
+
+	e.InsertBlock(func(key string) bool { return strings.HasPrefix(key, "UT0311-L0x.") },
+		"UT0311-L0x.201020304.name = Gamma",
+		"UT0311-L0x.201020304.door.1 = Gate")
+
+	if s := string(e.Bytes()); s != expected {
+		t.Errorf("Incorrect edited file\n   expected:%v\n   got:     %v", expected, s)
+	}
+}
@@ -0,0 +1,89 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Prefix for values that are read from a file (e.g. a Docker or Kubernetes secret) rather than
+// defined in the conf file e.g. mqtt.connection.password = file:/run/secrets/mqtt-password.
+const FILE_PREFIX = "file:"
+
+var envVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Overrides the conf values with the environment variables named <PREFIX>_<KEY>, where KEY is
+// the conf key in upper case with '.' and '-' replaced by '_' e.g. UHPPOTED_MQTT_CONNECTION_PASSWORD
+// for mqtt.connection.password. The keys are the keys defined in the struct (which is not
+// modified) along with any keys already in the document.
+func (d *Document) Environment(prefix string, m any) {
+	keys := map[string]string{}
+
+	Range(m, func(key string, v any) bool {
+		if !strings.HasPrefix(key, "/") {
+			keys[envName(prefix, key)] = key
+		}
+
+		return true
+	})
+
+	for key := range d.values {
+		keys[envName(prefix, key)] = key
+	}
+
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			if key, ok := keys[name]; ok {
+				d.values[key] = value
+				d.positions[key] = Position{File: "$" + name}
+			}
+		}
+	}
+}
+
+func envName(prefix, key string) string {
+	name := strings.ToUpper(key)
+	name = strings.ReplaceAll(name, ".", "_")
+	name = strings.ReplaceAll(name, "-", "_")
+
+	return prefix + "_" + name
+}
+
+// Expands any ${ENV_VAR} references in the conf values and replaces file:<path> values with
+// the (trimmed) contents of the file.
+func resolve(values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(values))
+
+	for key, value := range values {
+		var err error
+
+		v := envVar.ReplaceAllStringFunc(value, func(s string) string {
+			name := envVar.FindStringSubmatch(s)[1]
+			if v, ok := os.LookupEnv(name); ok {
+				return v
+			} else if err == nil {
+				err = fmt.Errorf("environment variable %v is not defined", name)
+			}
+
+			return ""
+		})
+
+		if err != nil {
+			return nil, &Error{Key: key, Err: err}
+		}
+
+		if path, ok := strings.CutPrefix(v, FILE_PREFIX); ok {
+			bytes, err := os.ReadFile(strings.TrimSpace(path))
+			if err != nil {
+				return nil, &Error{Key: key, Err: err}
+			}
+
+			v = strings.TrimRight(string(bytes), "\r\n")
+		}
+
+		resolved[key] = v
+	}
+
+	return resolved, nil
+}
@@ -0,0 +1,80 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvironmentExpansion(t *testing.T) {
+	t.Setenv("UHPPOTED_TEST_BROKER", "127.0.0.1")
+
+	secret := filepath.Join(t.TempDir(), "mqtt-client")
+	if err := os.WriteFile(secret, []byte("qwerty\n"), 0600); err != nil {
+		t.Fatalf("Error creating secret file (%v)", err)
+	}
+
+	configuration := []byte("mqtt.broker = tcp://${UHPPOTED_TEST_BROKER}:1883\nmqtt.client = file:" + secret + "\n# name = ${UNDEFINED}\n")
+
+	var v testDocument
+	if err := Unmarshal(configuration, &v); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if v.MQTT.Broker != "tcp://127.0.0.1:1883" {
+		t.Errorf("Incorrect broker - expected:%v, got:%v", "tcp://127.0.0.1:1883", v.MQTT.Broker)
+	}
+
+	if v.MQTT.Client != "qwerty" {
+		t.Errorf("Incorrect client - expected:%v, got:%v", "qwerty", v.MQTT.Client)
+	}
+}
+
+func TestEnvironmentExpansionErrors(t *testing.T) {
+	tests := map[string]string{
+		"name = ${UHPPOTED_TEST_UNDEFINED}\n":  "name",
+		"mqtt.client = file:/no/such/secret\n": "mqtt.client",
+	}
+
+	for configuration, key := range tests {
+		var v testDocument
+		var cerr *Error
+
+		if err := Unmarshal([]byte(configuration), &v); !errors.As(err, &cerr) || cerr.Key != key {
+			t.Errorf("Expected error for %v, got %v", key, err)
+		}
+	}
+}
+
+func TestEnvironmentOverrides(t *testing.T) {
+	t.Setenv("UHPPOTED_NAME", "environment")
+	t.Setenv("UHPPOTED_MQTT_CLIENT", "${UHPPOTED_TEST_CLIENT}")
+	t.Setenv("UHPPOTED_TEST_CLIENT", "uhppoted-test")
+
+	dir := t.TempDir()
+	write(t, dir, map[string]string{
+		"uhppoted.conf": "name = main\nmqtt.broker = tcp://127.0.0.1:1883\n",
+	})
+
+	var v testDocument
+
+	d := NewDocument()
+	if err := d.Load(filepath.Join(dir, "uhppoted.conf")); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	d.Environment("UHPPOTED", &v)
+
+	if err := d.Unmarshal(&v); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if v.Name != "environment" || v.MQTT.Client != "uhppoted-test" || v.MQTT.Broker != "tcp://127.0.0.1:1883" {
+		t.Errorf("Incorrect values %+v", v)
+	}
+
+	if p, _ := d.Position("name"); p.String() != "$UHPPOTED_NAME" {
+		t.Errorf("Incorrect position - expected:%v, got:%v", "$UHPPOTED_NAME", p)
+	}
+}
@@ -0,0 +1,116 @@
+package conf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Format is the file format of a configuration file.
+type Format string
+
+const (
+	CONF Format = "conf"
+	JSON Format = "json"
+	TOML Format = "toml"
+)
+
+var tomlish = regexp.MustCompile(`(?m)^\s*(\[.*\]|[^=#;]+=\s*["'\[])`)
+
+// Returns the format of a configuration file from the file extension (.conf, .json or .toml) or,
+// if the extension is not one of the known formats, from the file contents.
+func FormatOf(path string, b []byte) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".conf":
+		return CONF
+
+	case ".json":
+		return JSON
+
+	case ".toml":
+		return TOML
+	}
+
+	if text := bytes.TrimSpace(b); len(text) > 0 && text[0] == '{' {
+		return JSON
+	}
+
+	if tomlish.Match(b) && scanTOML(bytes.NewReader(b), func(string, string, int) error { return nil }) == nil {
+		return TOML
+	}
+
+	return CONF
+}
+
+// Writes a set of key/value pairs in the requested format. Dotted keys are written as nested
+// JSON objects or TOML tables, except where a key is both a value and the parent of other keys
+// (e.g. mqtt.connection.broker and mqtt.connection.broker.certificate), in which case the
+// child keys are written as dotted (quoted) keys.
+//
+// Values are written as is for bool, integer and float values and as strings otherwise.
+func Encode(w io.Writer, format Format, values map[string]any) error {
+	switch format {
+	case JSON:
+		return encodeJSON(w, nest(values))
+
+	case TOML:
+		return encodeTOML(w, nest(values), "")
+
+	case CONF, "":
+		for _, k := range slices.Sorted(maps.Keys(values)) {
+			if _, err := fmt.Fprintf(w, "%v = %v\n", k, values[k]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported configuration file format '%v'", format)
+	}
+}
+
+// Invokes f for each key/value pair in a configuration file in the given format.
+func decode(format Format, r io.Reader, f func(key, value string, line int) error, malformed func(line int, text string)) error {
+	switch format {
+	case JSON:
+		return scanJSON(r, f)
+
+	case TOML:
+		return scanTOML(r, f)
+
+	default:
+		return scan(r, f, malformed)
+	}
+}
+
+// Converts a set of dotted key/value pairs to a tree of nested maps.
+func nest(values map[string]any) map[string]any {
+	tree := map[string]any{}
+	groups := map[string]map[string]any{}
+
+	for key, value := range values {
+		head, rest, ok := strings.Cut(key, ".")
+		if _, leaf := values[head]; !ok || leaf {
+			tree[key] = value
+			continue
+		}
+
+		if groups[head] == nil {
+			groups[head] = map[string]any{}
+		}
+
+		groups[head][rest] = value
+	}
+
+	for head, group := range groups {
+		tree[head] = nest(group)
+	}
+
+	return tree
+}
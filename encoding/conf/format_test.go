@@ -0,0 +1,255 @@
+package conf
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type line struct {
+	value string
+	line  int
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		path     string
+		content  string
+		expected Format
+	}{
+		{"uhppoted.json", "", JSON},
+		{"uhppoted.TOML", "", TOML},
+		{"uhppoted.conf", "timeout = 5s\n", CONF},
+		{"uhppoted.conf", "# SYSTEM\ntimeout = \"5s\"\n", CONF},
+		{"uhppoted.conf", "[mqtt]\nconnection.broker = 'tls://127.0.0.1:8883'\n", CONF},
+		{"uhppoted.cfg", "# SYSTEM\ntimeout = \"5s\"\n", TOML},
+		{"", "  \n{\"timeout\": \"5s\"}", JSON},
+		{"", "# SYSTEM\ntimeout = \"5s\"\n", TOML},
+		{"", "[mqtt]\nconnection.broker = 'tls://127.0.0.1:8883'\n", TOML},
+		{"", "# SYSTEM\ntimeout = 5s\n; monitoring.watchdog.interval = 5s\n", CONF},
+		{"", "UT0311-L0x.405419896.door.1 = \"Front\" Door\n", CONF},
+	}
+
+	for _, test := range tests {
+		if f := FormatOf(test.path, []byte(test.content)); f != test.expected {
+			t.Errorf("Incorrect format for %q %q - expected:%v, got:%v", test.path, test.content, test.expected, f)
+		}
+	}
+}
+
+func TestScanJSON(t *testing.T) {
+	s := `{
+  "timeout": "5s",
+  "scheduler": { "concurrency": 2 },
+  "mqtt": {
+    "connection": {
+      "broker": "tls://127.0.0.1:8883",
+      "broker.certificate": "broker.cert"
+    },
+    "disabled": false
+  },
+  "zone": { "lobby": ["Front Door", "Side Door"] },
+  "openapi.directory": null
+}`
+
+	expected := map[string]line{
+		"timeout":                            {"5s", 2},
+		"scheduler.concurrency":              {"2", 3},
+		"mqtt.connection.broker":             {"tls://127.0.0.1:8883", 6},
+		"mqtt.connection.broker.certificate": {"broker.cert", 7},
+		"mqtt.disabled":                      {"false", 9},
+		"zone.lobby":                         {"Front Door, Side Door", 11},
+		"openapi.directory":                  {"", 12},
+	}
+
+	values := map[string]line{}
+	if err := scanJSON(strings.NewReader(s), func(key, value string, l int) error {
+		values[key] = line{value, l}
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Incorrect values\n   expected:%v\n   got:     %v", expected, values)
+	}
+}
+
+func TestScanTOML(t *testing.T) {
+	s := `# SYSTEM
+timeout = "5s"   # comment
+scheduler.concurrency = 2
+
+[mqtt.connection]
+broker = 'tls://127.0.0.1:8883'
+"broker.certificate" = "C:\\certs\\broker.cert"
+
+[mqtt]
+disabled = false
+
+[zone]
+lobby = [ "Front Door", "Side Door" ]
+
+[UT0311-L0x.405419896]
+door.1 = "Front Door"
+`
+
+	expected := map[string]line{
+		"timeout":                            {"5s", 2},
+		"scheduler.concurrency":              {"2", 3},
+		"mqtt.connection.broker":             {"tls://127.0.0.1:8883", 6},
+		"mqtt.connection.broker.certificate": {`C:\certs\broker.cert`, 7},
+		"mqtt.disabled":                      {"false", 10},
+		"zone.lobby":                         {"Front Door, Side Door", 13},
+		"UT0311-L0x.405419896.door.1":        {"Front Door", 16},
+	}
+
+	values := map[string]line{}
+	if err := scanTOML(strings.NewReader(s), func(key, value string, l int) error {
+		values[key] = line{value, l}
+		return nil
+	}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Incorrect values\n   expected:%v\n   got:     %v", expected, values)
+	}
+}
+
+func TestEncodeTOMLWithHTMLCharacters(t *testing.T) {
+	values := map[string]any{
+		"timeout":                        "5s",
+		"UT0311-L0x.405419896.interlock": "1&2",
+		"UT0311-L0x.405419896.door.1":    "<Front> & <Back>",
+	}
+
+	expected := `timeout = "5s"
+
+[UT0311-L0x.405419896]
+interlock = "1&2"
+
+[UT0311-L0x.405419896.door]
+1 = "<Front> & <Back>"
+`
+
+	var b bytes.Buffer
+	if err := Encode(&b, TOML, values); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if b.String() != expected {
+		t.Errorf("Incorrect TOML\n   expected:%v\n   got:     %v", expected, b.String())
+	}
+
+	// ... round trip
+	decoded := map[string]string{}
+	if err := decode(TOML, &b, func(key, value string, line int) error {
+		decoded[key] = value
+		return nil
+	}, nil); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	for k, v := range values {
+		if decoded[k] != v {
+			t.Errorf("Incorrect TOML round trip for %v - expected:%q, got:%q", k, v, decoded[k])
+		}
+	}
+}
+
+func TestScanWithSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		format Format
+		text   string
+		line   int
+	}{
+		{JSON, "{\n  \"timeout\": \"5s\",\n  \"mqtt\": [{}]\n}", 3},
+		{JSON, "[]", 1},
+		{TOML, "timeout = \"5s\"\n[[devices]]\n", 2},
+		{TOML, "timeout = \"5s\"\nmqtt = { broker = \"x\" }\n", 2},
+		{TOML, "timeout = \"5s\"\n\nname = \"unterminated\n", 3},
+		{TOML, "timeout = 5 seconds\n", 1},
+	}
+
+	for _, test := range tests {
+		err := decode(test.format, strings.NewReader(test.text), func(key, value string, line int) error { return nil }, nil)
+
+		var cerr *Error
+		if !errors.As(err, &cerr) {
+			t.Errorf("Expected *Error for %q, got %v", test.text, err)
+		} else if cerr.Line != test.line {
+			t.Errorf("Incorrect error line for %q - expected:%v, got:%v (%v)", test.text, test.line, cerr.Line, err)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	values := map[string]any{
+		"timeout":                            "5s",
+		"scheduler.concurrency":              int64(2),
+		"mqtt.connection.broker":             "tls://127.0.0.1:8883",
+		"mqtt.connection.broker.certificate": "broker.cert",
+		"mqtt.disabled":                      false,
+		"UT0311-L0x.405419896.door.1":        "Front \"Door\"",
+	}
+
+	expected := map[Format]string{
+		JSON: `{
+  "UT0311-L0x": {
+    "405419896": {
+      "door": {
+        "1": "Front \"Door\""
+      }
+    }
+  },
+  "mqtt": {
+    "connection": {
+      "broker": "tls://127.0.0.1:8883",
+      "broker.certificate": "broker.cert"
+    },
+    "disabled": false
+  },
+  "scheduler": {
+    "concurrency": 2
+  },
+  "timeout": "5s"
+}
+`,
+		TOML: `timeout = "5s"
+
+[UT0311-L0x.405419896.door]
+1 = "Front \"Door\""
+
+[mqtt]
+disabled = false
+
+[mqtt.connection]
+broker = "tls://127.0.0.1:8883"
+"broker.certificate" = "broker.cert"
+
+[scheduler]
+concurrency = 2
+`,
+	}
+
+	for format, s := range expected {
+		var b bytes.Buffer
+		if err := Encode(&b, format, values); err != nil {
+			t.Fatalf("Unexpected error (%v)", err)
+		} else if b.String() != s {
+			t.Errorf("Incorrect %v\n   expected:%v\n   got:     %v", format, s, b.String())
+		}
+
+		// ... round trip
+		decoded := map[string]string{}
+		decode(format, &b, func(key, value string, line int) error {
+			decoded[key] = value
+			return nil
+		}, nil)
+
+		if len(decoded) != len(values) || decoded["UT0311-L0x.405419896.door.1"] != `Front "Door"` || decoded["mqtt.connection.broker.certificate"] != "broker.cert" {
+			t.Errorf("Incorrect %v round trip %v", format, decoded)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type jsonScanner struct {
+	*json.Decoder
+	b []byte
+	f func(key, value string, line int) error
+}
+
+// Invokes f for each value in a JSON configuration file. Nested objects are flattened to dotted
+// keys and arrays are joined into a comma separated list i.e. the JSON equivalent of
+//
+//	mqtt.connection.broker = tls://127.0.0.1:8883
+//	zone.lobby = Front Door, Side Door
+//
+// is {"mqtt":{"connection":{"broker":"tls://127.0.0.1:8883"}},"zone":{"lobby":["Front Door","Side Door"]}}.
+func scanJSON(r io.Reader, f func(key, value string, line int) error) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s := jsonScanner{
+		Decoder: json.NewDecoder(bytes.NewReader(b)),
+		b:       b,
+		f:       f,
+	}
+
+	s.UseNumber()
+
+	if t, err := s.Token(); err != nil {
+		return s.error("", err)
+	} else if t != json.Delim('{') {
+		return s.error("", fmt.Errorf("expected JSON object"))
+	}
+
+	return s.object("")
+}
+
+func (s *jsonScanner) object(prefix string) error {
+	for s.More() {
+		t, err := s.Token()
+		if err != nil {
+			return s.error(prefix, err)
+		}
+
+		key := t.(string)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		line := s.line()
+		if t, err = s.Token(); err != nil {
+			return s.error(key, err)
+		}
+
+		if t == json.Delim('{') {
+			if err := s.object(key); err != nil {
+				return err
+			}
+		} else if t == json.Delim('[') {
+			items := []string{}
+			for s.More() {
+				t, err := s.Token()
+				if err != nil {
+					return s.error(key, err)
+				} else if v, ok := scalar(t); !ok {
+					return s.error(key, fmt.Errorf("invalid array item (expected string, number or boolean)"))
+				} else {
+					items = append(items, v)
+				}
+			}
+
+			if _, err := s.Token(); err != nil {
+				return s.error(key, err)
+			} else if err := s.f(key, strings.Join(items, ", "), line); err != nil {
+				return err
+			}
+		} else if v, ok := scalar(t); ok {
+			if err := s.f(key, v, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := s.Token(); err != nil {
+		return s.error(prefix, err)
+	}
+
+	return nil
+}
+
+func (s *jsonScanner) line() int {
+	return 1 + bytes.Count(s.b[:s.InputOffset()], []byte("\n"))
+}
+
+func (s *jsonScanner) error(key string, err error) error {
+	return &Error{Position: Position{Line: s.line()}, Key: key, Err: err}
+}
+
+func scalar(t any) (string, bool) {
+	switch v := t.(type) {
+	case string:
+		return v, true
+
+	case json.Number:
+		return v.String(), true
+
+	case bool:
+		return strconv.FormatBool(v), true
+
+	case nil:
+		return "", true
+
+	default:
+		return "", false
+	}
+}
+
+func encodeJSON(w io.Writer, tree map[string]any) error {
+	e := json.NewEncoder(w)
+
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+
+	return e.Encode(tree)
+}
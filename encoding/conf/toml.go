@@ -0,0 +1,270 @@
+package conf
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+	tomlScalar  = regexp.MustCompile(`^(true|false|[+-]?(inf|nan)|[0-9A-Fa-f+\-_.:xoTZ]+(\s[0-9:.+\-Z]+)?)`)
+)
+
+// Invokes f for each value in a TOML configuration file. Tables and dotted keys are flattened to
+// dotted conf keys and arrays are joined into a comma separated list. Only the subset of TOML
+// needed for configuration files is supported i.e. tables, single line strings, numbers,
+// booleans, dates and single line arrays of those values. Arrays of tables, inline tables and
+// multi-line strings are rejected.
+func scanTOML(r io.Reader, f func(key, value string, line int) error) error {
+	s := bufio.NewScanner(r)
+	prefix := ""
+	line := 0
+
+	for s.Scan() {
+		line++
+		text := strings.TrimSpace(s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fail := func(key string, err error) error {
+			return &Error{Position: Position{Line: line}, Key: key, Err: err}
+		}
+
+		// ... table header
+		if strings.HasPrefix(text, "[") {
+			if strings.HasPrefix(text, "[[") {
+				return fail("", fmt.Errorf("arrays of tables are not supported"))
+			}
+
+			keys, rest, err := tomlKey(text[1:])
+			if err != nil {
+				return fail("", err)
+			} else if rest, ok := strings.CutPrefix(rest, "]"); !ok || !tomlEOL(rest) {
+				return fail("", fmt.Errorf("invalid table header"))
+			}
+
+			prefix = strings.Join(keys, ".")
+			continue
+		}
+
+		// ... key = value
+		keys, rest, err := tomlKey(text)
+		if err != nil {
+			return fail("", err)
+		}
+
+		key := strings.Join(keys, ".")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		rest, ok := strings.CutPrefix(rest, "=")
+		if !ok {
+			return fail(key, fmt.Errorf("expected 'key = value'"))
+		}
+
+		value, rest, err := tomlValue(strings.TrimSpace(rest))
+		if err != nil {
+			return fail(key, err)
+		} else if !tomlEOL(rest) {
+			return fail(key, fmt.Errorf("unexpected '%v' after value", strings.TrimSpace(rest)))
+		}
+
+		if err := f(key, value, line); err != nil {
+			return err
+		}
+	}
+
+	return s.Err()
+}
+
+// Parses a (dotted) TOML key, returning the key components and the remaining text.
+func tomlKey(s string) ([]string, string, error) {
+	keys := []string{}
+
+	for {
+		s = strings.TrimSpace(s)
+
+		var key string
+		var err error
+
+		switch {
+		case strings.HasPrefix(s, `"`), strings.HasPrefix(s, `'`):
+			if key, s, err = tomlString(s); err != nil {
+				return nil, s, err
+			}
+
+		default:
+			if key = tomlBareKey.FindString(s); key == "" {
+				return nil, s, fmt.Errorf("invalid key")
+			}
+
+			s = s[len(key):]
+		}
+
+		keys = append(keys, key)
+
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(s), "."); ok {
+			s = rest
+		} else {
+			return keys, strings.TrimSpace(s), nil
+		}
+	}
+}
+
+// Parses a TOML value, returning the value formatted as a conf value and the remaining text.
+func tomlValue(s string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(s, `"""`), strings.HasPrefix(s, `'''`):
+		return "", s, fmt.Errorf("multi-line strings are not supported")
+
+	case strings.HasPrefix(s, `"`), strings.HasPrefix(s, `'`):
+		return tomlString(s)
+
+	case strings.HasPrefix(s, "{"):
+		return "", s, fmt.Errorf("inline tables are not supported")
+
+	case strings.HasPrefix(s, "["):
+		items := []string{}
+		s = strings.TrimSpace(s[1:])
+
+		for !strings.HasPrefix(s, "]") {
+			item, rest, err := tomlValue(s)
+			if err != nil {
+				return "", s, err
+			}
+
+			items = append(items, item)
+			s = strings.TrimSpace(rest)
+
+			if rest, ok := strings.CutPrefix(s, ","); ok {
+				s = strings.TrimSpace(rest)
+			} else if !strings.HasPrefix(s, "]") {
+				return "", s, fmt.Errorf("invalid array")
+			}
+		}
+
+		return strings.Join(items, ", "), s[1:], nil
+
+	default:
+		if v := tomlScalar.FindString(s); v != "" {
+			return strings.ReplaceAll(v, "_", ""), s[len(v):], nil
+		}
+
+		return "", s, fmt.Errorf("invalid value '%v'", s)
+	}
+}
+
+// Parses a TOML basic ("...") or literal ('...') string.
+func tomlString(s string) (string, string, error) {
+	if strings.HasPrefix(s, "'") {
+		if end := strings.Index(s[1:], "'"); end >= 0 {
+			return s[1 : end+1], s[end+2:], nil
+		}
+
+		return "", s, fmt.Errorf("unterminated string")
+	}
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+
+		case '"':
+			v, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", s, fmt.Errorf("invalid string %v", s[:i+1])
+			}
+
+			return v, s[i+1:], nil
+		}
+	}
+
+	return "", s, fmt.Errorf("unterminated string")
+}
+
+func tomlEOL(s string) bool {
+	s = strings.TrimSpace(s)
+
+	return s == "" || strings.HasPrefix(s, "#")
+}
+
+func encodeTOML(w io.Writer, tree map[string]any, path string) error {
+	keys := slices.Sorted(maps.Keys(tree))
+	tables := []string{}
+	values := []string{}
+
+	for _, k := range keys {
+		if _, ok := tree[k].(map[string]any); ok {
+			tables = append(tables, k)
+		} else {
+			values = append(values, k)
+		}
+	}
+
+	if len(values) > 0 && path != "" {
+		if _, err := fmt.Fprintf(w, "\n[%v]\n", path); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range values {
+		if _, err := fmt.Fprintf(w, "%v = %v\n", tomlQuote(k), tomlFormat(tree[k])); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range tables {
+		subpath := tomlQuote(k)
+		if path != "" {
+			subpath = path + "." + subpath
+		}
+
+		if err := encodeTOML(w, tree[k].(map[string]any), subpath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func tomlQuote(key string) string {
+	if tomlBareKey.FindString(key) == key {
+		return key
+	}
+
+	return tomlFormat(key)
+}
+
+func tomlFormat(v any) string {
+	switch v := v.(type) {
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%v", v)
+
+	case float32, float64:
+		s := fmt.Sprintf("%v", v)
+		if !strings.ContainsAny(s, ".eEnN") {
+			s += ".0"
+		}
+
+		return s
+
+	default:
+		// ... JSON strings are valid TOML basic strings (without the HTML escapes, for readability)
+		var b strings.Builder
+
+		e := json.NewEncoder(&b)
+		e.SetEscapeHTML(false)
+		e.Encode(fmt.Sprintf("%v", v))
+
+		return strings.TrimSuffix(b.String(), "\n")
+	}
+}
@@ -0,0 +1,488 @@
+package conf
+
+import (
+	"cmp"
+	"encoding"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+var (
+	tTextUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+	pLocation        = reflect.TypeFor[*time.Location]()
+)
+
+// Returned from flatten to stop iterating when a Range function returns false.
+var errStop = errors.New("stop")
+
+// Returns true if the type is marshaled as a single value (rather than e.g. as a struct, map or
+// slice) i.e. the bool, integer, float and string types, time.Duration, *time.Location, the
+// address types, types that implement encoding.TextUnmarshaler (e.g. netip.Addr and
+// netip.AddrPort) and (nested) pointers to any of these.
+func isValue(t reflect.Type) bool {
+	switch t {
+	case tDuration, pBindAddr, pBroadcastAddr, pListenAddr, pUDPAddr, pLocation:
+		return true
+	}
+
+	if reflect.PointerTo(t).Implements(tTextUnmarshaler) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+
+	case reflect.Pointer:
+		return isValue(t.Elem())
+	}
+
+	return false
+}
+
+// Returns true if the type is a struct (or pointer to a struct) that is marshaled as a set of
+// key/value pairs rather than as a single value.
+func isStruct(t reflect.Type) bool {
+	if isValue(t) {
+		return false
+	} else if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Struct && !isValue(t)
+}
+
+// Parses a conf value as a value of the type.
+func parseValue(t reflect.Type, s string) (reflect.Value, error) {
+	switch t {
+	case tDuration:
+		d, err := time.ParseDuration(s)
+		return reflect.ValueOf(d), err
+
+	case pBindAddr:
+		v, err := types.ParseBindAddr(s)
+		return reflect.ValueOf(&v), err
+
+	case pBroadcastAddr:
+		v, err := types.ParseBroadcastAddr(s)
+		return reflect.ValueOf(&v), err
+
+	case pListenAddr:
+		v, err := types.ParseListenAddr(s)
+		return reflect.ValueOf(&v), err
+
+	case pUDPAddr:
+		address, err := net.ResolveUDPAddr("udp", s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		addr := net.UDPAddr{
+			IP:   make(net.IP, net.IPv4len),
+			Port: address.Port,
+			Zone: "",
+		}
+
+		copy(addr.IP, address.IP.To4())
+
+		return reflect.ValueOf(&addr), nil
+
+	case pLocation:
+		location, err := time.LoadLocation(s)
+		return reflect.ValueOf(location), err
+	}
+
+	// ... (nested) pointers
+	if t.Kind() == reflect.Pointer {
+		v, err := parseValue(t.Elem(), s)
+		if err != nil {
+			return v, err
+		}
+
+		p := reflect.New(t.Elem())
+		p.Elem().Set(v)
+
+		return p, nil
+	}
+
+	v := reflect.New(t)
+
+	if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+		return v.Elem(), u.UnmarshalText([]byte(s))
+	}
+
+	v = v.Elem()
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if s == "true" {
+			v.SetBool(true)
+		} else if s == "false" {
+			v.SetBool(false)
+		} else {
+			return v, fmt.Errorf("invalid boolean value: %s", s)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return v, err
+		}
+
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return v, err
+		}
+
+		v.SetUint(i)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return v, err
+		}
+
+		v.SetFloat(f)
+
+	case reflect.String:
+		v.SetString(s)
+
+	default:
+		return v, fmt.Errorf("cannot unmarshal field with type '%v'", t)
+	}
+
+	return v, nil
+}
+
+// Formats a value as a conf value, returning false for nil pointers.
+func formatValue(v reflect.Value) (string, bool) {
+	switch v.Type() {
+	case pBindAddr, pBroadcastAddr, pListenAddr, pUDPAddr, pLocation:
+		if v.IsNil() {
+			return "", false
+		}
+
+		return fmt.Sprintf("%v", v), true
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+
+		return formatValue(v.Elem())
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b), true
+		}
+	}
+
+	return fmt.Sprintf("%v", v), true
+}
+
+// Returns the value passed to a Range function i.e. the value of a field (or the value pointed
+// to, for pointer fields) or the text for types that implement encoding.TextMarshaler.
+func rangeValue(v reflect.Value) any {
+	switch v.Type() {
+	case pLocation:
+		return v.Interface()
+	}
+
+	if v.Kind() == reflect.Pointer {
+		return rangeValue(v.Elem())
+	}
+
+	if _, ok := v.Interface().(encoding.TextMarshaler); ok {
+		s, _ := formatValue(v)
+		return s
+	}
+
+	return v.Interface()
+}
+
+// Unmarshals a map, slice or value field.
+func unmarshalField(f reflect.Value, tag string, values map[string]string) error {
+	t := f.Type()
+
+	switch {
+	case t.Kind() == reflect.Map && isValue(t.Key()):
+		return unmarshalMap(f, tag, values)
+
+	case t.Kind() == reflect.Slice:
+		return unmarshalSlice(f, tag, values)
+
+	case isValue(t):
+		if value, ok := values[tag]; ok {
+			v, err := parseValue(t, value)
+			if err != nil {
+				return &Error{Key: tag, Err: err}
+			}
+
+			f.Set(v)
+		}
+
+	default:
+		if _, ok := values[tag]; ok {
+			return &Error{Key: tag, Err: fmt.Errorf("cannot unmarshal field with type '%v'", t)}
+		}
+	}
+
+	return nil
+}
+
+// Unmarshals the tag.<key> values into a map, merged with any existing map entries. For maps
+// of structs the values are tag.<key>.<field>.
+func unmarshalMap(f reflect.Value, tag string, values map[string]string) error {
+	t := f.Type()
+	keys := []string{}
+
+	for key := range values {
+		if rest, ok := strings.CutPrefix(key, tag+"."); ok && rest != "" {
+			if isStruct(t.Elem()) {
+				rest, _, _ = strings.Cut(rest, ".")
+			}
+
+			if !slices.Contains(keys, rest) {
+				keys = append(keys, rest)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	m := f
+	if m.IsNil() {
+		m = reflect.MakeMap(t)
+	}
+
+	slices.Sort(keys)
+	for _, key := range keys {
+		k, err := parseValue(t.Key(), key)
+		if err != nil {
+			return &Error{Key: tag + "." + key, Err: fmt.Errorf("invalid key '%v' (%v)", key, err)}
+		}
+
+		v, err := unmarshalElement(t.Elem(), tag+"."+key, values)
+		if err != nil {
+			return err
+		}
+
+		m.SetMapIndex(k, v)
+	}
+
+	f.Set(m)
+
+	return nil
+}
+
+// Unmarshals a slice from either a comma separated list (tag = a, b, c) or from indexed values
+// (tag.1 = a, tag.2 = b, ...). Slices of structs are always indexed (tag.<index>.<field>).
+func unmarshalSlice(f reflect.Value, tag string, values map[string]string) error {
+	t := f.Type()
+
+	if !isValue(t.Elem()) && !isStruct(t.Elem()) {
+		if _, ok := values[tag]; ok {
+			return &Error{Key: tag, Err: fmt.Errorf("cannot unmarshal field with type '%v'", t)}
+		}
+
+		return nil
+	}
+
+	if value, ok := values[tag]; ok && isValue(t.Elem()) {
+		s := reflect.MakeSlice(t, 0, 0)
+
+		if strings.TrimSpace(value) != "" {
+			for item := range strings.SplitSeq(value, ",") {
+				v, err := parseValue(t.Elem(), strings.TrimSpace(item))
+				if err != nil {
+					return &Error{Key: tag, Err: err}
+				}
+
+				s = reflect.Append(s, v)
+			}
+		}
+
+		f.Set(s)
+
+		return nil
+	}
+
+	indices := []int{}
+	for key := range values {
+		if rest, ok := strings.CutPrefix(key, tag+"."); ok {
+			if isStruct(t.Elem()) {
+				rest, _, _ = strings.Cut(rest, ".")
+			}
+
+			if index, err := strconv.Atoi(rest); err != nil {
+				continue
+			} else if index < 1 {
+				return &Error{Key: key, Err: fmt.Errorf("invalid index %v (must be 1 or more)", index)}
+			} else if !slices.Contains(indices, index) {
+				indices = append(indices, index)
+			}
+		}
+	}
+
+	if len(indices) == 0 {
+		return nil
+	}
+
+	slices.Sort(indices)
+
+	N := indices[len(indices)-1]
+	s := reflect.MakeSlice(t, N, N)
+
+	for _, index := range indices {
+		v, err := unmarshalElement(t.Elem(), fmt.Sprintf("%v.%v", tag, index), values)
+		if err != nil {
+			return err
+		}
+
+		s.Index(index - 1).Set(v)
+	}
+
+	f.Set(s)
+
+	return nil
+}
+
+// Unmarshals a map or slice element from the key (for values) or key.<field> (for structs).
+func unmarshalElement(t reflect.Type, key string, values map[string]string) (reflect.Value, error) {
+	if isStruct(t) {
+		if t.Kind() == reflect.Pointer {
+			v := reflect.New(t.Elem())
+			return v, unmarshal(v.Elem(), key+".", values)
+		}
+
+		v := reflect.New(t).Elem()
+		return v, unmarshal(v, key+".", values)
+	}
+
+	v, err := parseValue(t, values[key])
+	if err != nil {
+		return v, &Error{Key: key, Err: err}
+	}
+
+	return v, nil
+}
+
+// Marshals a map, slice or value field as a list of 'key = value' lines.
+func marshalField(tag string, f reflect.Value) ([]string, error) {
+	lines := []string{}
+
+	err := flatten(tag, f, func(key string, v reflect.Value) error {
+		if isStruct(v.Type()) {
+			b, err := marshal(reflect.Indirect(v))
+			if err != nil {
+				return err
+			}
+
+			for line := range strings.SplitSeq(string(b), "\n") {
+				if line != "" {
+					lines = append(lines, key+"."+line)
+				}
+			}
+		} else if s, ok := formatValue(v); ok {
+			lines = append(lines, fmt.Sprintf("%s = %s", key, s))
+		}
+
+		return nil
+	})
+
+	return lines, err
+}
+
+// Invokes g for each of the values of a map, slice or value field. Map entries are keyed as
+// tag.<key>, slices of values as a single comma separated list (or as tag.<index> if any of the
+// values contains a comma) and slices of structs as tag.<index>. Nil pointers and nil slices
+// and maps are skipped.
+func flatten(tag string, f reflect.Value, g func(key string, v reflect.Value) error) error {
+	t := f.Type()
+
+	element := func(key string, v reflect.Value) error {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil
+		}
+
+		return g(key, v)
+	}
+
+	switch {
+	case t.Kind() == reflect.Map && isValue(t.Key()) && (isValue(t.Elem()) || isStruct(t.Elem())):
+		type entry struct {
+			key   string
+			value reflect.Value
+		}
+
+		entries := []entry{}
+		for _, k := range f.MapKeys() {
+			if key, ok := formatValue(k); ok {
+				entries = append(entries, entry{key, f.MapIndex(k)})
+			}
+		}
+
+		slices.SortFunc(entries, func(p, q entry) int {
+			return cmp.Compare(p.key, q.key)
+		})
+
+		for _, e := range entries {
+			if err := element(tag+"."+e.key, e.value); err != nil {
+				return err
+			}
+		}
+
+	case t.Kind() == reflect.Slice && isValue(t.Elem()):
+		if f.IsNil() {
+			return nil
+		}
+
+		items := []string{}
+		for i := range f.Len() {
+			s, _ := formatValue(f.Index(i))
+			items = append(items, s)
+		}
+
+		if !slices.ContainsFunc(items, func(s string) bool { return strings.Contains(s, ",") }) {
+			return g(tag, reflect.ValueOf(strings.Join(items, ", ")))
+		}
+
+		for i := range f.Len() {
+			if err := element(fmt.Sprintf("%v.%v", tag, i+1), f.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	case t.Kind() == reflect.Slice && isStruct(t.Elem()):
+		for i := range f.Len() {
+			if err := element(fmt.Sprintf("%v.%v", tag, i+1), f.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	case isValue(t):
+		return element(tag, f)
+
+	default:
+		return fmt.Errorf("cannot marshal field with type '%v'", t)
+	}
+
+	return nil
+}
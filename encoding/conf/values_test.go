@@ -0,0 +1,226 @@
+package conf
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte([]string{"debug", "info", "warn"}[l]), nil
+}
+
+func (l *level) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "warn":
+		*l = 2
+	default:
+		return fmt.Errorf("invalid level '%s'", b)
+	}
+
+	return nil
+}
+
+type profile struct {
+	Address  netip.AddrPort `conf:"address"`
+	Timeout  time.Duration  `conf:"timeout"`
+	Disabled bool           `conf:"disabled"`
+}
+
+type generic struct {
+	Ratio    float64             `conf:"ratio"`
+	Scale    float32             `conf:"scale"`
+	Offset   int8                `conf:"offset"`
+	Delta    int64               `conf:"delta"`
+	Address  netip.Addr          `conf:"address"`
+	Gateway  *netip.Addr         `conf:"gateway"`
+	Endpoint netip.AddrPort      `conf:"endpoint"`
+	Timezone *time.Location      `conf:"timezone"`
+	Level    level               `conf:"level"`
+	Retries  **int               `conf:"retries"`
+	Missing  *int                `conf:"missing"`
+	Labels   map[string]string   `conf:"labels"`
+	Limits   map[string]int      `conf:"limits"`
+	Profiles map[string]*profile `conf:"profiles"`
+	Ports    map[uint16]string   `conf:"ports"`
+	Doors    []string            `conf:"doors"`
+	Notes    []string            `conf:"notes"`
+	Levels   []level             `conf:"levels"`
+	Hosts    []profile           `conf:"hosts"`
+}
+
+func TestGenericRoundTrip(t *testing.T) {
+	retries := 3
+	pretries := &retries
+	gateway := netip.MustParseAddr("192.168.1.1")
+	tz, _ := time.LoadLocation("Europe/Paris")
+
+	g := generic{
+		Ratio:    0.125,
+		Scale:    1.5,
+		Offset:   -7,
+		Delta:    -1234567890123,
+		Address:  netip.MustParseAddr("192.168.1.100"),
+		Gateway:  &gateway,
+		Endpoint: netip.MustParseAddrPort("192.168.1.100:60000"),
+		Timezone: tz,
+		Level:    2,
+		Retries:  &pretries,
+		Labels:   map[string]string{"site": "HQ", "building.wing": "East"},
+		Limits:   map[string]int{"cards": 20000, "events": -1},
+		Profiles: map[string]*profile{
+			"lan": {Address: netip.MustParseAddrPort("192.168.1.255:60000"), Timeout: 2500 * time.Millisecond},
+			"vpn": {Address: netip.MustParseAddrPort("10.0.0.255:60000"), Timeout: 5 * time.Second, Disabled: true},
+		},
+		Ports:  map[uint16]string{60000: "controllers", 60001: "events"},
+		Doors:  []string{"Front Door", "Side Door", "", "Garage"},
+		Notes:  []string{"one, two", "three"},
+		Levels: []level{0, 2},
+		Hosts: []profile{
+			{Address: netip.MustParseAddrPort("192.168.1.101:60000")},
+			{Address: netip.MustParseAddrPort("192.168.1.102:60000"), Disabled: true},
+		},
+	}
+
+	expected := `ratio = 0.125
+scale = 1.5
+offset = -7
+delta = -1234567890123
+address = 192.168.1.100
+gateway = 192.168.1.1
+endpoint = 192.168.1.100:60000
+timezone = Europe/Paris
+level = warn
+retries = 3
+labels.building.wing = East
+labels.site = HQ
+limits.cards = 20000
+limits.events = -1
+profiles.lan.address = 192.168.1.255:60000
+profiles.lan.timeout = 2.5s
+profiles.lan.disabled = false
+profiles.vpn.address = 10.0.0.255:60000
+profiles.vpn.timeout = 5s
+profiles.vpn.disabled = true
+ports.60000 = controllers
+ports.60001 = events
+doors = Front Door, Side Door, , Garage
+notes.1 = one, two
+notes.2 = three
+levels = debug, warn
+hosts.1.address = 192.168.1.101:60000
+hosts.1.timeout = 0s
+hosts.1.disabled = false
+hosts.2.address = 192.168.1.102:60000
+hosts.2.timeout = 0s
+hosts.2.disabled = true
+`
+
+	b, err := Marshal(g)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if string(b) != expected {
+		t.Errorf("Incorrectly marshaled\n   expected:%v\n   got:     %v", expected, string(b))
+	}
+
+	var u generic
+	if err := Unmarshal(b, &u); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if u.Timezone == nil || u.Timezone.String() != "Europe/Paris" {
+		t.Errorf("Incorrectly unmarshaled timezone - expected:%v, got:%v", tz, u.Timezone)
+	}
+
+	if u.Retries == nil || *u.Retries == nil || **u.Retries != 3 {
+		t.Errorf("Incorrectly unmarshaled nested pointer - expected:%v, got:%v", 3, u.Retries)
+	}
+
+	g.Timezone, u.Timezone = nil, nil
+	g.Retries, u.Retries = nil, nil
+
+	if !reflect.DeepEqual(u, g) {
+		t.Errorf("Incorrectly unmarshaled\n   expected:%+v\n   got:     %+v", g, u)
+	}
+}
+
+func TestUnmarshalIndexedSlice(t *testing.T) {
+	s := `doors.1 = Front Door
+doors.2 = Side Door
+doors.4 = Garage
+`
+
+	var g generic
+	if err := Unmarshal([]byte(s), &g); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if expected := []string{"Front Door", "Side Door", "", "Garage"}; !reflect.DeepEqual(g.Doors, expected) {
+		t.Errorf("Incorrect slice - expected:%q, got:%q", expected, g.Doors)
+	}
+}
+
+func TestUnmarshalGenericWithInvalidValues(t *testing.T) {
+	tests := map[string]string{
+		"offset = 128":                   "offset",
+		"scale = 1.5x":                   "scale",
+		"address = 192.168.1.300":        "address",
+		"timezone = Nowhere/Atlantis":    "timezone",
+		"level = verbose":                "level",
+		"limits.cards = lots":            "limits.cards",
+		"ports.http = web":               "ports.http",
+		"profiles.lan.timeout = 5 years": "profiles.lan.timeout",
+		"levels = debug, loud":           "levels",
+		"doors.0 = Front Door":           "doors.0",
+	}
+
+	for s, key := range tests {
+		var g generic
+		if err := Unmarshal([]byte(s), &g); err == nil {
+			t.Errorf("Expected error unmarshaling %q", s)
+		} else if !strings.HasPrefix(err.Error(), key+": ") {
+			t.Errorf("Expected error for key '%v', got %v", key, err)
+		}
+	}
+}
+
+func TestRangeGeneric(t *testing.T) {
+	g := generic{
+		Address:  netip.MustParseAddr("192.168.1.100"),
+		Limits:   map[string]int{"cards": 20000},
+		Profiles: map[string]*profile{"lan": {Timeout: 5 * time.Second}},
+		Doors:    []string{"Front Door", "Side Door"},
+	}
+
+	values := map[string]any{}
+	Range(g, func(key string, v any) bool {
+		values[key] = v
+		return true
+	})
+
+	expected := map[string]any{
+		"address":              "192.168.1.100",
+		"limits.cards":         20000,
+		"profiles.lan.timeout": 5 * time.Second,
+		"doors":                "Front Door, Side Door",
+	}
+
+	for k, v := range expected {
+		if values[k] != v {
+			t.Errorf("Incorrect Range value for %v - expected:%v, got:%v", k, v, values[k])
+		}
+	}
+
+	if _, ok := values["gateway"]; ok {
+		t.Errorf("Expected nil pointer to be skipped by Range")
+	}
+}
@@ -21,6 +21,7 @@ var (
 	tBool    = reflect.TypeFor[bool]()
 	tUint8   = reflect.TypeFor[uint8]()
 	tInt     = reflect.TypeFor[int]()
+	tString  = reflect.TypeFor[string]()
 	tDate    = reflect.TypeFor[types.Date]()
 	tDatePtr = reflect.TypeFor[*types.Date]()
 	tHHmm    = reflect.TypeFor[types.HHmm]()
@@ -146,6 +147,9 @@ func unmarshal(rid int, record []string, index map[string]int, s reflect.Value)
 					}
 				}
 
+			case tString:
+				f.SetString(value)
+
 			case tDate:
 				if v, err := types.ParseDate(value); err != nil {
 					return fmt.Errorf("record %v: invalid value '%s' for field '%s'", rid, value, tag)
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/uhppoted/uhppoted-lib/acl"
+	"github.com/uhppoted/uhppoted-lib/monitoring"
+)
+
+// MonitoringHandler wraps a monitoring.MonitoringHandler to count the alerts raised by each
+// monitor (e.g. the health-check and watchdog).
+type MonitoringHandler struct {
+	monitoring.MonitoringHandler
+	alerts *Counter
+}
+
+// Adds the card counts for each outcome in the ACL update reports returned by acl.PutACL to the
+// uhppoted_acl_cards_total metric.
+func RecordACLReport(r *Registry, reports map[uint32]acl.Report) {
+	counter := r.Counter("uhppoted_acl_cards_total", "Number of cards processed by ACL updates, by outcome.")
+
+	for controller, report := range reports {
+		outcomes := map[string]int{
+			"unchanged": len(report.Unchanged),
+			"updated":   len(report.Updated),
+			"added":     len(report.Added),
+			"deleted":   len(report.Deleted),
+			"failed":    len(report.Failed),
+			"errored":   len(report.Errored),
+		}
+
+		for outcome, N := range outcomes {
+			counter.Add(float64(N), Labels{
+				"controller": fmt.Sprintf("%v", controller),
+				"outcome":    outcome,
+			})
+		}
+	}
+}
+
+func NewMonitoringHandler(h monitoring.MonitoringHandler, r *Registry) *MonitoringHandler {
+	return &MonitoringHandler{
+		MonitoringHandler: h,
+		alerts:            r.Counter("uhppoted_monitoring_alerts_total", "Number of alerts raised by the monitors."),
+	}
+}
+
+func (h *MonitoringHandler) Alert(m monitoring.Monitor, message string) error {
+	h.alerts.Inc(Labels{"monitor": m.ID()})
+
+	return h.MonitoringHandler.Alert(m, message)
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Content type of the Prometheus text exposition format.
+const CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+// Writes the registered metrics in the Prometheus text exposition format, ordered by metric
+// name and labels.
+func (r *Registry) Write(w io.Writer) error {
+	r.guard.Lock()
+	families := slices.SortedFunc(maps.Values(r.families), func(p, q *family) int {
+		return strings.Compare(p.name, q.name)
+	})
+	r.guard.Unlock()
+
+	b := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(b)
+	}
+
+	return b.Flush()
+}
+
+// Returns an HTTP handler that serves the registered metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, rq *http.Request) {
+		w.Header().Set("Content-Type", CONTENT_TYPE)
+
+		if err := r.Write(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func (f *family) write(w io.Writer) {
+	f.guard.Lock()
+	collector := f.collector
+	list := []series{}
+	for _, s := range f.series {
+		v := *s
+		v.counts = slices.Clone(s.counts)
+		list = append(list, v)
+	}
+	f.guard.Unlock()
+
+	if collector != nil {
+		for _, sample := range collector() {
+			list = append(list, series{labels: sample.Labels, value: sample.Value})
+		}
+	}
+
+	if len(list) == 0 {
+		return
+	}
+
+	slices.SortFunc(list, func(p, q series) int {
+		return strings.Compare(key(p.labels), key(q.labels))
+	})
+
+	fmt.Fprintf(w, "# HELP %v %v\n", f.name, escape(f.help, false))
+	fmt.Fprintf(w, "# TYPE %v %v\n", f.name, f.kind)
+
+	for _, s := range list {
+		if f.kind == "histogram" {
+			for i, le := range f.buckets {
+				fmt.Fprintf(w, "%v_bucket%v %v\n", f.name, labels(s.labels, "le", format(le)), s.counts[i])
+			}
+
+			fmt.Fprintf(w, "%v_bucket%v %v\n", f.name, labels(s.labels, "le", "+Inf"), s.samples)
+			fmt.Fprintf(w, "%v_sum%v %v\n", f.name, labels(s.labels), format(s.sum))
+			fmt.Fprintf(w, "%v_count%v %v\n", f.name, labels(s.labels), s.samples)
+		} else {
+			fmt.Fprintf(w, "%v%v %v\n", f.name, labels(s.labels), format(s.value))
+		}
+	}
+}
+
+// Formats the labels (and any additional name/value pairs) as {name="value",...}.
+func labels(l Labels, extra ...string) string {
+	list := []string{}
+	for _, k := range slices.Sorted(maps.Keys(l)) {
+		list = append(list, fmt.Sprintf(`%v="%v"`, k, escape(l[k], true)))
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		list = append(list, fmt.Sprintf(`%v="%v"`, extra[i], extra[i+1]))
+	}
+
+	if len(list) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(list, ",") + "}"
+}
+
+func format(v float64) string {
+	switch {
+	case math.IsInf(v, +1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+func escape(s string, quotes bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	if quotes {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+
+	return s
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/uhppote"
+
+	lib "github.com/uhppoted/uhppoted-lib/os"
+)
+
+// UHPPOTE wraps a uhppote.IUHPPOTE to record the number of requests, request latency, timeouts
+// and errors for each controller and operation, along with the event 'backlog' (the number of
+// events after the controller event index) for each controller.
+type UHPPOTE struct {
+	uhppote.IUHPPOTE
+	requests *Counter
+	latency  *Histogram
+	timeouts *Counter
+	errors   *Counter
+	events   *Gauge
+	indices  map[uint32]*indices
+	guard    sync.Mutex
+}
+
+type indices struct {
+	last    uint32
+	current uint32
+	known   bool
+}
+
+func NewUHPPOTE(u uhppote.IUHPPOTE, r *Registry) *UHPPOTE {
+	return &UHPPOTE{
+		IUHPPOTE: u,
+		requests: r.Counter("uhppoted_controller_requests_total", "Number of controller requests."),
+		latency:  r.Histogram("uhppoted_controller_request_duration_seconds", "Controller request latency.", LatencyBuckets),
+		timeouts: r.Counter("uhppoted_controller_timeouts_total", "Number of controller requests that timed out."),
+		errors:   r.Counter("uhppoted_controller_errors_total", "Number of controller requests that failed (other than timeouts)."),
+		events:   r.Gauge("uhppoted_controller_event_backlog", "Number of controller events after the controller event index."),
+		indices:  map[uint32]*indices{},
+	}
+}
+
+func call[T any](m *UHPPOTE, controller uint32, operation string, f func() (T, error)) (T, error) {
+	labels := Labels{
+		"controller": fmt.Sprintf("%v", controller),
+		"operation":  operation,
+	}
+
+	start := time.Now()
+	v, err := f()
+
+	m.requests.Inc(labels)
+	m.latency.Observe(time.Since(start).Seconds(), Labels{"operation": operation})
+
+	if err != nil && lib.IsTimeout(err) {
+		m.timeouts.Inc(labels)
+	} else if err != nil {
+		m.errors.Inc(labels)
+	}
+
+	return v, err
+}
+
+// Updates the last known event index and/or the controller event index and recalculates
+// the controller event backlog.
+func (m *UHPPOTE) backlog(controller uint32, last uint32, current *uint32) {
+	m.guard.Lock()
+	defer m.guard.Unlock()
+
+	v, ok := m.indices[controller]
+	if !ok {
+		v = &indices{}
+		m.indices[controller] = v
+	}
+
+	v.last = max(v.last, last)
+	if current != nil {
+		v.current = *current
+		v.known = true
+	}
+
+	if v.known && v.last > 0 {
+		backlog := 0.0
+		if v.last > v.current {
+			backlog = float64(v.last - v.current)
+		}
+
+		m.events.Set(backlog, Labels{"controller": fmt.Sprintf("%v", controller)})
+	}
+}
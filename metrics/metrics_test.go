@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+
+	"github.com/uhppoted/uhppoted-lib/acl"
+	"github.com/uhppoted/uhppoted-lib/simulator"
+)
+
+func TestWrite(t *testing.T) {
+	expected := `# HELP test_latency_seconds Request latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{operation="get-status",le="0.1"} 1
+test_latency_seconds_bucket{operation="get-status",le="1"} 2
+test_latency_seconds_bucket{operation="get-status",le="+Inf"} 3
+test_latency_seconds_sum{operation="get-status"} 2.75
+test_latency_seconds_count{operation="get-status"} 3
+# HELP test_queued Queued "requests".
+# TYPE test_queued gauge
+test_queued{controller="405419896"} 3
+# HELP test_requests_total Number of requests.
+# TYPE test_requests_total counter
+test_requests_total{controller="303986753",operation="get-status"} 1
+test_requests_total{controller="405419896",operation="put-card"} 2.5
+`
+
+	r := NewRegistry()
+
+	requests := r.Counter("test_requests_total", "Number of requests.")
+	requests.Inc(Labels{"operation": "put-card", "controller": "405419896"})
+	requests.Add(1.5, Labels{"operation": "put-card", "controller": "405419896"})
+	requests.Add(-1, Labels{"operation": "put-card", "controller": "405419896"})
+	requests.Inc(Labels{"operation": "get-status", "controller": "303986753"})
+
+	latency := r.Histogram("test_latency_seconds", "Request latency.", []float64{1, 0.1})
+	latency.Observe(0.05, Labels{"operation": "get-status"})
+	latency.Observe(0.7, Labels{"operation": "get-status"})
+	latency.Observe(2, Labels{"operation": "get-status"})
+
+	r.Gauge("test_unused", "Not exported because it has no series.")
+	r.Collect("test_queued", `Queued "requests".`, func() []Sample {
+		return []Sample{{Labels: Labels{"controller": "405419896"}, Value: 3}}
+	})
+
+	var b bytes.Buffer
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if b.String() != expected {
+		t.Errorf("Incorrect exposition text\n   expected:\n%v\n   got:\n%v", expected, b.String())
+	}
+}
+
+func TestEscape(t *testing.T) {
+	r := NewRegistry()
+	r.Gauge("test_gauge", "Multi-line\nhelp").Set(1, Labels{"name": "a \"quoted\"\\value"})
+
+	var b bytes.Buffer
+	r.Write(&b)
+
+	for _, line := range []string{
+		`# HELP test_gauge Multi-line\nhelp`,
+		`test_gauge{name="a \"quoted\"\\value"} 1`,
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected %q in exposition text, got:\n%v", line, b.String())
+		}
+	}
+}
+
+func TestUHPPOTE(t *testing.T) {
+	s := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	s.Inject(simulator.Fault{Operation: "get-time", Type: simulator.Timeout, Count: 1})
+
+	r := NewRegistry()
+	u := NewUHPPOTE(s, r)
+
+	for range 5 {
+		s.AddEvent(405419896, types.Event{Type: 2, Timestamp: types.DateTime(time.Now())})
+	}
+
+	u.GetTime(405419896)
+	u.GetTime(405419896)
+	u.SetEventIndex(405419896, 2)
+	u.GetStatus(405419896)
+
+	var b bytes.Buffer
+	r.Write(&b)
+
+	for _, line := range []string{
+		`uhppoted_controller_requests_total{controller="405419896",operation="get-time"} 2`,
+		`uhppoted_controller_requests_total{controller="405419896",operation="get-status"} 1`,
+		`uhppoted_controller_timeouts_total{controller="405419896",operation="get-time"} 1`,
+		`uhppoted_controller_request_duration_seconds_count{operation="get-time"} 2`,
+		`uhppoted_controller_event_backlog{controller="405419896"} 3`,
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected %q in exposition text, got:\n%v", line, b.String())
+		}
+	}
+
+	if strings.Contains(b.String(), "uhppoted_controller_errors_total") {
+		t.Errorf("Unexpected errors metric in exposition text:\n%v", b.String())
+	}
+}
+
+func TestRecordACLReport(t *testing.T) {
+	r := NewRegistry()
+
+	RecordACLReport(r, map[uint32]acl.Report{
+		405419896: {
+			Unchanged: []uint32{10058400},
+			Added:     []uint32{10058401, 10058402},
+			Failed:    []uint32{10058403},
+		},
+	})
+
+	var b bytes.Buffer
+	r.Write(&b)
+
+	for _, line := range []string{
+		`uhppoted_acl_cards_total{controller="405419896",outcome="added"} 2`,
+		`uhppoted_acl_cards_total{controller="405419896",outcome="failed"} 1`,
+		`uhppoted_acl_cards_total{controller="405419896",outcome="unchanged"} 1`,
+		`uhppoted_acl_cards_total{controller="405419896",outcome="deleted"} 0`,
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected %q in exposition text, got:\n%v", line, b.String())
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package metrics implements a minimal metrics registry (counters, gauges and histograms with
+// labels) and an exporter for the Prometheus text exposition format, along with the IUHPPOTE
+// decorator and helper functions that record the library metrics.
+package metrics
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Labels is the set of label name/value pairs that identify a time series in a metric family.
+type Labels map[string]string
+
+// Sample is a single value reported by a collector function.
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+// Registry holds the registered metric families.
+type Registry struct {
+	families map[string]*family
+	guard    sync.Mutex
+}
+
+type Counter struct {
+	family *family
+}
+
+type Gauge struct {
+	family *family
+}
+
+type Histogram struct {
+	family *family
+}
+
+type family struct {
+	name      string
+	help      string
+	kind      string
+	buckets   []float64
+	series    map[string]*series
+	collector func() []Sample
+	guard     sync.Mutex
+}
+
+type series struct {
+	labels  Labels
+	value   float64
+	counts  []uint64
+	sum     float64
+	samples uint64
+}
+
+// Default histogram buckets (in seconds) for controller request latencies.
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		families: map[string]*family{},
+	}
+}
+
+// Returns the counter with the name, registering it if it does not exist.
+func (r *Registry) Counter(name, help string) *Counter {
+	return &Counter{r.register(name, help, "counter", nil)}
+}
+
+// Returns the gauge with the name, registering it if it does not exist.
+func (r *Registry) Gauge(name, help string) *Gauge {
+	return &Gauge{r.register(name, help, "gauge", nil)}
+}
+
+// Returns the histogram with the name, registering it with the (sorted) buckets if it does
+// not exist.
+func (r *Registry) Histogram(name, help string, buckets []float64) *Histogram {
+	b := slices.Clone(buckets)
+	slices.Sort(b)
+
+	return &Histogram{r.register(name, help, "histogram", b)}
+}
+
+// Registers a gauge family for which the values are obtained from the collector function
+// when the metrics are exported, e.g. for values maintained elsewhere such as queue lengths.
+func (r *Registry) Collect(name, help string, collector func() []Sample) {
+	f := r.register(name, help, "gauge", nil)
+
+	f.guard.Lock()
+	defer f.guard.Unlock()
+
+	f.collector = collector
+}
+
+func (c *Counter) Inc(labels Labels) {
+	c.Add(1, labels)
+}
+
+// Increments the counter. Negative values are ignored.
+func (c *Counter) Add(v float64, labels Labels) {
+	if v >= 0 {
+		c.family.update(labels, func(s *series) { s.value += v })
+	}
+}
+
+func (g *Gauge) Set(v float64, labels Labels) {
+	g.family.update(labels, func(s *series) { s.value = v })
+}
+
+func (g *Gauge) Add(v float64, labels Labels) {
+	g.family.update(labels, func(s *series) { s.value += v })
+}
+
+func (h *Histogram) Observe(v float64, labels Labels) {
+	h.family.update(labels, func(s *series) {
+		for i, le := range h.family.buckets {
+			if v <= le {
+				s.counts[i]++
+			}
+		}
+
+		s.sum += v
+		s.samples++
+	})
+}
+
+func (r *Registry) register(name, help, kind string, buckets []float64) *family {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		if f.kind != kind {
+			panic(fmt.Sprintf("metric %v already registered as a %v", name, f.kind))
+		}
+
+		return f
+	}
+
+	f := family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+
+	r.families[name] = &f
+
+	return &f
+}
+
+func (f *family) update(labels Labels, fn func(s *series)) {
+	f.guard.Lock()
+	defer f.guard.Unlock()
+
+	k := key(labels)
+	s, ok := f.series[k]
+	if !ok {
+		s = &series{
+			labels: maps.Clone(labels),
+			counts: make([]uint64, len(f.buckets)),
+		}
+
+		f.series[k] = s
+	}
+
+	fn(s)
+}
+
+func key(labels Labels) string {
+	var b strings.Builder
+
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		fmt.Fprintf(&b, "%s=%q,", k, labels[k])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+var _ uhppote.IUHPPOTE = (*UHPPOTE)(nil)
+
+func (m *UHPPOTE) GetDevices() ([]types.Device, error) {
+	return call(m, 0, "get-devices", func() ([]types.Device, error) {
+		return m.IUHPPOTE.GetDevices()
+	})
+}
+
+func (m *UHPPOTE) GetDevice(controller uint32) (*types.Device, error) {
+	return call(m, controller, "get-device", func() (*types.Device, error) {
+		return m.IUHPPOTE.GetDevice(controller)
+	})
+}
+
+func (m *UHPPOTE) SetAddress(controller uint32, address, mask, gateway net.IP) (*types.Result, error) {
+	return call(m, controller, "set-address", func() (*types.Result, error) {
+		return m.IUHPPOTE.SetAddress(controller, address, mask, gateway)
+	})
+}
+
+func (m *UHPPOTE) GetListener(controller uint32) (netip.AddrPort, uint8, error) {
+	type listener struct {
+		address  netip.AddrPort
+		interval uint8
+	}
+
+	v, err := call(m, controller, "get-listener", func() (listener, error) {
+		address, interval, err := m.IUHPPOTE.GetListener(controller)
+
+		return listener{address, interval}, err
+	})
+
+	return v.address, v.interval, err
+}
+
+func (m *UHPPOTE) SetListener(controller uint32, address netip.AddrPort, interval uint8) (bool, error) {
+	return call(m, controller, "set-listener", func() (bool, error) {
+		return m.IUHPPOTE.SetListener(controller, address, interval)
+	})
+}
+
+func (m *UHPPOTE) GetTime(controller uint32) (*types.Time, error) {
+	return call(m, controller, "get-time", func() (*types.Time, error) {
+		return m.IUHPPOTE.GetTime(controller)
+	})
+}
+
+func (m *UHPPOTE) SetTime(controller uint32, datetime time.Time) (*types.Time, error) {
+	return call(m, controller, "set-time", func() (*types.Time, error) {
+		return m.IUHPPOTE.SetTime(controller, datetime)
+	})
+}
+
+func (m *UHPPOTE) GetDoorControlState(controller uint32, door byte) (*types.DoorControlState, error) {
+	return call(m, controller, "get-door-control-state", func() (*types.DoorControlState, error) {
+		return m.IUHPPOTE.GetDoorControlState(controller, door)
+	})
+}
+
+func (m *UHPPOTE) SetDoorControlState(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+	return call(m, controller, "set-door-control-state", func() (*types.DoorControlState, error) {
+		return m.IUHPPOTE.SetDoorControlState(controller, door, state, delay)
+	})
+}
+
+func (m *UHPPOTE) GetStatus(controller uint32) (*types.Status, error) {
+	status, err := call(m, controller, "get-status", func() (*types.Status, error) {
+		return m.IUHPPOTE.GetStatus(controller)
+	})
+
+	if err == nil && status != nil {
+		m.backlog(controller, status.Event.Index, nil)
+	}
+
+	return status, err
+}
+
+func (m *UHPPOTE) GetCards(controller uint32) (uint32, error) {
+	return call(m, controller, "get-cards", func() (uint32, error) {
+		return m.IUHPPOTE.GetCards(controller)
+	})
+}
+
+func (m *UHPPOTE) GetCardByIndex(controller, index uint32) (*types.Card, error) {
+	return call(m, controller, "get-card-by-index", func() (*types.Card, error) {
+		return m.IUHPPOTE.GetCardByIndex(controller, index)
+	})
+}
+
+func (m *UHPPOTE) GetCardByID(controller, cardNumber uint32) (*types.Card, error) {
+	return call(m, controller, "get-card-by-id", func() (*types.Card, error) {
+		return m.IUHPPOTE.GetCardByID(controller, cardNumber)
+	})
+}
+
+func (m *UHPPOTE) PutCard(controller uint32, card types.Card, formats ...types.CardFormat) (bool, error) {
+	return call(m, controller, "put-card", func() (bool, error) {
+		return m.IUHPPOTE.PutCard(controller, card, formats...)
+	})
+}
+
+func (m *UHPPOTE) DeleteCard(controller uint32, cardNumber uint32) (bool, error) {
+	return call(m, controller, "delete-card", func() (bool, error) {
+		return m.IUHPPOTE.DeleteCard(controller, cardNumber)
+	})
+}
+
+func (m *UHPPOTE) DeleteCards(controller uint32) (bool, error) {
+	return call(m, controller, "delete-cards", func() (bool, error) {
+		return m.IUHPPOTE.DeleteCards(controller)
+	})
+}
+
+func (m *UHPPOTE) GetTimeProfile(controller uint32, profileID uint8) (*types.TimeProfile, error) {
+	return call(m, controller, "get-time-profile", func() (*types.TimeProfile, error) {
+		return m.IUHPPOTE.GetTimeProfile(controller, profileID)
+	})
+}
+
+func (m *UHPPOTE) SetTimeProfile(controller uint32, profile types.TimeProfile) (bool, error) {
+	return call(m, controller, "set-time-profile", func() (bool, error) {
+		return m.IUHPPOTE.SetTimeProfile(controller, profile)
+	})
+}
+
+func (m *UHPPOTE) ClearTimeProfiles(controller uint32) (bool, error) {
+	return call(m, controller, "clear-time-profiles", func() (bool, error) {
+		return m.IUHPPOTE.ClearTimeProfiles(controller)
+	})
+}
+
+func (m *UHPPOTE) ClearTaskList(controller uint32) (bool, error) {
+	return call(m, controller, "clear-task-list", func() (bool, error) {
+		return m.IUHPPOTE.ClearTaskList(controller)
+	})
+}
+
+func (m *UHPPOTE) AddTask(controller uint32, task types.Task) (bool, error) {
+	return call(m, controller, "add-task", func() (bool, error) {
+		return m.IUHPPOTE.AddTask(controller, task)
+	})
+}
+
+func (m *UHPPOTE) RefreshTaskList(controller uint32) (bool, error) {
+	return call(m, controller, "refresh-task-list", func() (bool, error) {
+		return m.IUHPPOTE.RefreshTaskList(controller)
+	})
+}
+
+func (m *UHPPOTE) RecordSpecialEvents(controller uint32, enable bool) (bool, error) {
+	return call(m, controller, "record-special-events", func() (bool, error) {
+		return m.IUHPPOTE.RecordSpecialEvents(controller, enable)
+	})
+}
+
+func (m *UHPPOTE) GetEvent(controller, index uint32) (*types.Event, error) {
+	event, err := call(m, controller, "get-event", func() (*types.Event, error) {
+		return m.IUHPPOTE.GetEvent(controller, index)
+	})
+
+	if err == nil && event != nil {
+		m.backlog(controller, event.Index, nil)
+	}
+
+	return event, err
+}
+
+func (m *UHPPOTE) GetEventIndex(controller uint32) (*types.EventIndex, error) {
+	index, err := call(m, controller, "get-event-index", func() (*types.EventIndex, error) {
+		return m.IUHPPOTE.GetEventIndex(controller)
+	})
+
+	if err == nil && index != nil {
+		m.backlog(controller, 0, &index.Index)
+	}
+
+	return index, err
+}
+
+func (m *UHPPOTE) SetEventIndex(controller, index uint32) (*types.EventIndexResult, error) {
+	result, err := call(m, controller, "set-event-index", func() (*types.EventIndexResult, error) {
+		return m.IUHPPOTE.SetEventIndex(controller, index)
+	})
+
+	if err == nil && result != nil {
+		m.backlog(controller, 0, &result.Index)
+	}
+
+	return result, err
+}
+
+func (m *UHPPOTE) SetDoorPasscodes(controller uint32, door uint8, passcodes ...uint32) (bool, error) {
+	return call(m, controller, "set-door-passcodes", func() (bool, error) {
+		return m.IUHPPOTE.SetDoorPasscodes(controller, door, passcodes...)
+	})
+}
+
+func (m *UHPPOTE) OpenDoor(controller uint32, door uint8) (*types.Result, error) {
+	return call(m, controller, "open-door", func() (*types.Result, error) {
+		return m.IUHPPOTE.OpenDoor(controller, door)
+	})
+}
+
+func (m *UHPPOTE) SetPCControl(controller uint32, enable bool) (bool, error) {
+	return call(m, controller, "set-pc-control", func() (bool, error) {
+		return m.IUHPPOTE.SetPCControl(controller, enable)
+	})
+}
+
+func (m *UHPPOTE) SetInterlock(controller uint32, interlock types.Interlock) (bool, error) {
+	return call(m, controller, "set-interlock", func() (bool, error) {
+		return m.IUHPPOTE.SetInterlock(controller, interlock)
+	})
+}
+
+func (m *UHPPOTE) ActivateKeypads(controller uint32, readers map[uint8]bool) (bool, error) {
+	return call(m, controller, "activate-keypads", func() (bool, error) {
+		return m.IUHPPOTE.ActivateKeypads(controller, readers)
+	})
+}
+
+func (m *UHPPOTE) GetAntiPassback(controller uint32) (types.AntiPassback, error) {
+	return call(m, controller, "get-antipassback", func() (types.AntiPassback, error) {
+		return m.IUHPPOTE.GetAntiPassback(controller)
+	})
+}
+
+func (m *UHPPOTE) SetAntiPassback(controller uint32, antipassback types.AntiPassback) (bool, error) {
+	return call(m, controller, "set-antipassback", func() (bool, error) {
+		return m.IUHPPOTE.SetAntiPassback(controller, antipassback)
+	})
+}
+
+func (m *UHPPOTE) SetFirstCard(controller uint32, door uint8, firstcard types.FirstCard) (bool, error) {
+	return call(m, controller, "set-first-card", func() (bool, error) {
+		return m.IUHPPOTE.SetFirstCard(controller, door, firstcard)
+	})
+}
+
+func (m *UHPPOTE) RestoreDefaultParameters(controller uint32) (bool, error) {
+	return call(m, controller, "restore-default-parameters", func() (bool, error) {
+		return m.IUHPPOTE.RestoreDefaultParameters(controller)
+	})
+}
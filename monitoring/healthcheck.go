@@ -441,22 +441,22 @@ func (h *HealthCheck) resolve() {
 	cache.touched = time.Now()
 }
 
-func info(h *HealthCheck, handler MonitoringHandler, deviceID uint32, message string) bool {
+func info(m Monitor, handler MonitoringHandler, deviceID uint32, message string) bool {
 	msg := fmt.Sprintf("UTC0311-L0x %s %s", types.SerialNumber(deviceID), message)
 
-	infof("health-check", msg)
-	if err := handler.Alert(h, msg); err != nil {
+	infof(m.ID(), msg)
+	if err := handler.Alert(m, msg); err != nil {
 		return false
 	}
 
 	return true
 }
 
-func warn(h *HealthCheck, handler MonitoringHandler, deviceID uint32, message string) bool {
+func warn(m Monitor, handler MonitoringHandler, deviceID uint32, message string) bool {
 	msg := fmt.Sprintf("UTC0311-L0x %s %s", types.SerialNumber(deviceID), message)
 
-	warnf("health-check", msg)
-	if err := handler.Alert(h, msg); err != nil {
+	warnf(m.ID(), msg)
+	if err := handler.Alert(m, msg); err != nil {
 		return false
 	}
 
@@ -41,6 +41,12 @@ const (
 	DELTA     = 60
 	MIN_DELAY = 30
 	PADDING   = 15
+
+	SYNC_THRESHOLD = time.Duration(5 * time.Second)
+	SYNC_INTERVAL  = time.Duration(5 * time.Minute)
+	SYNC_HOLDOFF   = time.Duration(60 * time.Minute)
+	SYNC_HISTORY   = 96
+	DST_GUARD      = time.Duration(5 * time.Minute)
 )
 
 func debugf(subsystem string, format string, args ...any) {
@@ -0,0 +1,265 @@
+package monitoring
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+// TimeSync periodically compares the controller system time with the host time (adjusted
+// for the controller timezone) and resets the controller time if the drift exceeds the
+// threshold. Corrections are rate limited to one per 'holdoff' interval for each controller,
+// except for drift caused by a daylight savings transition (the controllers are not DST
+// aware), which is corrected immediately.
+type TimeSync struct {
+	uhppote   uhppote.IUHPPOTE
+	threshold time.Duration
+	holdoff   time.Duration
+	history   int
+	now       func() time.Time
+	state     struct {
+		Devices map[uint32]*timesync
+		sync.RWMutex
+	}
+}
+
+// Drift is a single controller time drift measurement.
+type Drift struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Drift     time.Duration `json:"drift"`
+	Corrected bool          `json:"corrected"`
+	DST       bool          `json:"dst,omitempty"`
+}
+
+type timesync struct {
+	corrected time.Time
+	history   []Drift
+	alerted   struct {
+		drift  bool
+		failed bool
+	}
+}
+
+func NewTimeSync(u uhppote.IUHPPOTE, threshold, holdoff time.Duration) TimeSync {
+	return TimeSync{
+		uhppote:   u,
+		threshold: threshold,
+		holdoff:   holdoff,
+		history:   SYNC_HISTORY,
+		now:       time.Now,
+		state: struct {
+			Devices map[uint32]*timesync
+			sync.RWMutex
+		}{
+			Devices: map[uint32]*timesync{},
+		},
+	}
+}
+
+func (s *TimeSync) ID() string {
+	return "time-sync"
+}
+
+func (s *TimeSync) Exec(handler MonitoringHandler) {
+	debugf("time-sync", "exec")
+
+	errors := uint(0)
+	warnings := uint(0)
+
+	for id, device := range s.uhppote.DeviceList() {
+		e, w := s.sync(id, device.TimeZone, handler)
+		errors += e
+		warnings += w
+	}
+
+	// 'k, done
+	if errors > 0 && warnings > 0 {
+		warnf("time-sync", "%v, %v", Errors(errors), Warnings(warnings))
+	} else if errors > 0 {
+		warnf("time-sync", "%v", Errors(errors))
+	} else if warnings > 0 {
+		warnf("time-sync", "%v", Warnings(warnings))
+	} else {
+		infof("time-sync", "OK")
+	}
+
+	if errors > 0 && warnings > 0 {
+		handler.Alive(s, fmt.Sprintf("%v, %v", Errors(errors), Warnings(warnings)))
+	} else if errors > 0 {
+		handler.Alive(s, fmt.Sprintf("%v", Errors(errors)))
+	} else if warnings > 0 {
+		handler.Alive(s, fmt.Sprintf("%v", Warnings(warnings)))
+	} else {
+		handler.Alive(s, "OK")
+	}
+}
+
+// Returns the recorded drift history for a controller, oldest first.
+func (s *TimeSync) History(controller uint32) []Drift {
+	s.state.RLock()
+	defer s.state.RUnlock()
+
+	if v, ok := s.state.Devices[controller]; ok {
+		return append([]Drift{}, v.history...)
+	}
+
+	return []Drift{}
+}
+
+func (s *TimeSync) sync(id uint32, tz *time.Location, handler MonitoringHandler) (uint, uint) {
+	if tz == nil {
+		tz = time.Local
+	}
+
+	now := s.now().In(tz)
+
+	// ... skip controllers that are close to a DST transition (drift is ambiguous)
+	if start, end := now.ZoneBounds(); (!start.IsZero() && now.Sub(start) < DST_GUARD) || (!end.IsZero() && end.Sub(now) < DST_GUARD) {
+		debugf("time-sync", "%v  DST transition - skipping", id)
+		return 0, 0
+	}
+
+	status, err := s.uhppote.GetStatus(id)
+	if err != nil {
+		warnf("time-sync", "%v  error retrieving controller status (%v)", id, err)
+		return 0, 1
+	} else if status == nil || status.SystemDateTime.IsZero() {
+		warnf("time-sync", "%v  invalid controller system date/time", id)
+		return 0, 1
+	}
+
+	// ... the state is not locked during the controller and handler calls so that History() is
+	//     not blocked for the controller timeout and handlers can call back into TimeSync
+	state := s.snapshot(id)
+	drift := wallclock(time.Time(status.SystemDateTime)).Sub(wallclock(now))
+	record := Drift{
+		Timestamp: now,
+		Drift:     drift,
+	}
+
+	if abs(drift) <= s.threshold {
+		if state.alerted.drift || state.alerted.failed {
+			if info(s, handler, id, fmt.Sprintf("system time synchronized (%v)", drift)) {
+				state.alerted.drift = false
+				state.alerted.failed = false
+			}
+		}
+
+		s.update(id, record, state)
+
+		return 0, 0
+	}
+
+	record.DST = isDST(tz, now, state.corrected, drift, s.threshold)
+
+	if !record.DST && !state.corrected.IsZero() && now.Sub(state.corrected) < s.holdoff {
+		if !state.alerted.drift {
+			msg := fmt.Sprintf("system time drift %v exceeds %v (next correction after %v)", drift, s.threshold, types.DateTime(state.corrected.Add(s.holdoff)))
+			if warn(s, handler, id, msg) {
+				state.alerted.drift = true
+			}
+		}
+
+		s.update(id, record, state)
+
+		return 0, 1
+	}
+
+	if _, err := s.uhppote.SetTime(id, s.now().In(tz)); err != nil {
+		if !state.alerted.failed {
+			msg := fmt.Sprintf("UTC0311-L0x %s error correcting system time drift %v (%v)", types.SerialNumber(id), drift, err)
+
+			errorf("time-sync", msg)
+			if err := handler.Alert(s, msg); err == nil {
+				state.alerted.failed = true
+			}
+		}
+
+		s.update(id, record, state)
+
+		return 1, 0
+	}
+
+	record.Corrected = true
+	state.corrected = now
+	state.alerted.drift = false
+	state.alerted.failed = false
+
+	s.update(id, record, state)
+
+	if record.DST {
+		info(s, handler, id, fmt.Sprintf("system time adjusted for DST transition (%v)", drift))
+	} else {
+		info(s, handler, id, fmt.Sprintf("system time corrected (%v)", drift))
+	}
+
+	return 0, 0
+}
+
+// Returns a copy of the correction and alert state for a controller.
+func (s *TimeSync) snapshot(id uint32) timesync {
+	s.state.RLock()
+	defer s.state.RUnlock()
+
+	if v, ok := s.state.Devices[id]; ok {
+		return timesync{
+			corrected: v.corrected,
+			alerted:   v.alerted,
+		}
+	}
+
+	return timesync{}
+}
+
+// Updates the correction and alert state for a controller and appends the drift measurement to
+// the controller history.
+func (s *TimeSync) update(id uint32, record Drift, state timesync) {
+	s.state.Lock()
+	defer s.state.Unlock()
+
+	v, ok := s.state.Devices[id]
+	if !ok {
+		v = &timesync{}
+		s.state.Devices[id] = v
+	}
+
+	v.corrected = state.corrected
+	v.alerted = state.alerted
+	v.history = append(v.history, record)
+
+	if N := len(v.history); N > s.history {
+		v.history = v.history[N-s.history:]
+	}
+}
+
+// Returns true if the drift matches a DST transition since the last correction i.e. the
+// controller time is out by (approximately) the UTC offset change.
+func isDST(tz *time.Location, now, corrected time.Time, drift, threshold time.Duration) bool {
+	start, _ := now.ZoneBounds()
+	if start.IsZero() || start.Before(corrected) {
+		return false
+	}
+
+	_, before := start.Add(-time.Second).In(tz).Zone()
+	_, after := now.Zone()
+	delta := time.Duration(after-before) * time.Second
+
+	return delta != 0 && abs(drift+delta) <= threshold
+}
+
+// Discards the timezone, returning the 'wall clock' time as UTC. The controllers have no
+// notion of timezone so drift is measured between wall clock times.
+func wallclock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+func abs(dt time.Duration) time.Duration {
+	if dt < 0 {
+		return -dt
+	}
+
+	return dt
+}
@@ -0,0 +1,204 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+type stub struct {
+	uhppote.IUHPPOTE
+	devices  map[uint32]uhppote.Device
+	datetime time.Time
+	set      []time.Time
+	polled   int
+}
+
+func (s *stub) DeviceList() map[uint32]uhppote.Device {
+	return s.devices
+}
+
+func (s *stub) GetStatus(controller uint32) (*types.Status, error) {
+	s.polled++
+
+	return &types.Status{
+		SerialNumber:   types.SerialNumber(controller),
+		SystemDateTime: types.DateTime(s.datetime),
+	}, nil
+}
+
+func (s *stub) SetTime(controller uint32, datetime time.Time) (*types.Time, error) {
+	s.set = append(s.set, datetime)
+	s.datetime = datetime
+
+	return &types.Time{
+		SerialNumber: types.SerialNumber(controller),
+		DateTime:     types.DateTime(datetime),
+	}, nil
+}
+
+type handler struct {
+	alerts []string
+}
+
+func (h *handler) Alive(m Monitor, msg string) error {
+	return nil
+}
+
+func (h *handler) Alert(m Monitor, msg string) error {
+	h.alerts = append(h.alerts, msg)
+	return nil
+}
+
+func TestTimeSync(t *testing.T) {
+	now := time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC)
+	tz := time.FixedZone("UTC+2", 2*3600)
+
+	u := stub{
+		devices: map[uint32]uhppote.Device{
+			405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{}, tz),
+		},
+	}
+
+	s := NewTimeSync(&u, 5*time.Second, time.Hour)
+	s.now = func() time.Time { return now }
+
+	// ... within threshold
+	u.datetime = now.In(tz).Add(3 * time.Second)
+	s.Exec(&handler{})
+
+	if len(u.set) != 0 {
+		t.Errorf("Unexpected time correction for drift within threshold")
+	}
+
+	// ... corrected
+	u.datetime = now.In(tz).Add(-30 * time.Second)
+	s.Exec(&handler{})
+
+	if len(u.set) != 1 {
+		t.Fatalf("Expected time correction, got %v", len(u.set))
+	} else if wallclock(u.set[0]) != wallclock(now.In(tz)) {
+		t.Errorf("Incorrect corrected time - expected:%v, got:%v", now.In(tz), u.set[0])
+	}
+
+	// ... rate limited
+	now = now.Add(10 * time.Minute)
+	u.datetime = now.In(tz).Add(30 * time.Second)
+	s.Exec(&handler{})
+
+	if len(u.set) != 1 {
+		t.Errorf("Expected rate limited time correction, got %v corrections", len(u.set))
+	}
+
+	history := s.History(405419896)
+	if len(history) != 3 {
+		t.Fatalf("Incorrect drift history - expected:%v records, got:%v", 3, len(history))
+	}
+
+	expected := []Drift{
+		{Timestamp: time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC), Drift: 3 * time.Second},
+		{Timestamp: time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC), Drift: -30 * time.Second, Corrected: true},
+		{Timestamp: time.Date(2026, time.June, 15, 12, 10, 0, 0, time.UTC), Drift: 30 * time.Second},
+	}
+
+	for i := range expected {
+		if !history[i].Timestamp.Equal(expected[i].Timestamp) || history[i].Drift != expected[i].Drift || history[i].Corrected != expected[i].Corrected {
+			t.Errorf("Incorrect drift record %v - expected:%+v, got:%+v", i+1, expected[i], history[i])
+		}
+	}
+}
+
+func TestTimeSyncWithDSTTransition(t *testing.T) {
+	tz, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("Europe/Paris timezone not available (%v)", err)
+	}
+
+	now := time.Date(2026, time.March, 28, 12, 0, 0, 0, time.UTC)
+
+	u := stub{
+		devices: map[uint32]uhppote.Device{
+			405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{}, tz),
+		},
+	}
+
+	s := NewTimeSync(&u, 5*time.Second, 48*time.Hour)
+	s.now = func() time.Time { return now }
+
+	u.datetime = now.In(tz).Add(-30 * time.Second)
+	s.Exec(&handler{})
+
+	if len(u.set) != 1 {
+		t.Fatalf("Expected time correction, got %v", len(u.set))
+	}
+
+	// ... skipped close to DST transition (2026-03-29 01:00 UTC)
+	now = time.Date(2026, time.March, 29, 0, 58, 0, 0, time.UTC)
+	polled := u.polled
+	s.Exec(&handler{})
+
+	if u.polled != polled {
+		t.Errorf("Expected time sync to be skipped close to DST transition")
+	}
+
+	// ... controller is still on CET after the transition
+	now = time.Date(2026, time.March, 29, 12, 0, 0, 0, time.UTC)
+	u.datetime = now.Add(1 * time.Hour)
+	s.Exec(&handler{})
+
+	history := s.History(405419896)
+	if len(u.set) != 2 {
+		t.Fatalf("Expected DST time correction, got %v corrections", len(u.set))
+	} else if last := history[len(history)-1]; !last.DST || !last.Corrected || last.Drift != -time.Hour {
+		t.Errorf("Incorrect DST drift record %+v", last)
+	}
+}
+
+type reentrant struct {
+	handler
+	sync    *TimeSync
+	history []Drift
+}
+
+func (h *reentrant) Alert(m Monitor, msg string) error {
+	h.history = h.sync.History(405419896)
+	return h.handler.Alert(m, msg)
+}
+
+func TestTimeSyncWithReentrantHandler(t *testing.T) {
+	now := time.Date(2026, time.June, 15, 12, 0, 0, 0, time.UTC)
+
+	u := stub{
+		devices: map[uint32]uhppote.Device{
+			405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{}, time.UTC),
+		},
+		datetime: now.Add(-30 * time.Second),
+	}
+
+	s := NewTimeSync(&u, 5*time.Second, time.Hour)
+	s.now = func() time.Time { return now }
+
+	h := reentrant{sync: &s}
+	done := make(chan struct{})
+
+	go func() {
+		s.Exec(&h)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("time-sync handler deadlocked")
+	}
+
+	if len(h.alerts) != 1 {
+		t.Errorf("Incorrect alerts - expected:%v, got:%v", 1, len(h.alerts))
+	}
+
+	if len(h.history) != 1 || !h.history[0].Corrected {
+		t.Errorf("Incorrect drift history in handler - expected:%v corrected record, got:%+v", 1, h.history)
+	}
+}
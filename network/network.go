@@ -0,0 +1,217 @@
+// Package network combines the uhppote-core IUHPPOTE instances for controllers on multiple
+// networks (e.g. VLANs with their own bind, broadcast and listen addresses) into a single
+// IUHPPOTE that routes each controller request to the network of the controller, broadcasts
+// 'get-devices' requests on every network and listens for events on every network.
+package network
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/netip"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+// Network is the bind, broadcast and listen addresses for a network and the controllers on the
+// network. The default network has an empty name.
+type Network struct {
+	Name             string
+	BindAddress      types.BindAddr
+	BroadcastAddress types.BroadcastAddr
+	ListenAddress    types.ListenAddr
+	Controllers      []uhppote.Device
+}
+
+// UHPPOTE is an IUHPPOTE that dispatches requests to the IUHPPOTE for each network. Requests
+// for a controller that is not configured on any network are sent using the default network
+// (or the first network if there is no default network).
+type UHPPOTE struct {
+	networks []member
+}
+
+type member struct {
+	name string
+	uhppote.IUHPPOTE
+}
+
+// Creates a uhppote-core IUHPPOTE for each network and combines them into a single IUHPPOTE.
+// Returns an error if the network list is empty.
+func NewUHPPOTE(networks []Network, timeout time.Duration, debug bool) (*UHPPOTE, error) {
+	list := map[string]uhppote.IUHPPOTE{}
+
+	for _, n := range networks {
+		list[n.Name] = uhppote.NewUHPPOTE(n.BindAddress, n.BroadcastAddress, n.ListenAddress, timeout, n.Controllers, debug)
+	}
+
+	return Join(list)
+}
+
+// Combines a set of IUHPPOTE, keyed by network name, into a single IUHPPOTE. The default
+// network has an empty name. Returns an error if there are no networks (or a network has a
+// nil IUHPPOTE) since there would be no network to route controller requests to.
+func Join(networks map[string]uhppote.IUHPPOTE) (*UHPPOTE, error) {
+	if len(networks) == 0 {
+		return nil, errors.New("no networks")
+	}
+
+	u := UHPPOTE{
+		networks: []member{},
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(networks)) {
+		if networks[name] == nil {
+			return nil, fmt.Errorf("network '%v': missing IUHPPOTE", name)
+		}
+
+		u.networks = append(u.networks, member{name, networks[name]})
+	}
+
+	return &u, nil
+}
+
+// Returns the name of the network for a controller.
+func (u *UHPPOTE) Network(controller uint32) string {
+	return u.route(controller).name
+}
+
+// Broadcasts a 'get-devices' request on all networks concurrently and returns the merged list of
+// controllers, ordered by controller ID. Returns an error only if the request failed on
+// every network.
+func (u *UHPPOTE) GetDevices() ([]types.Device, error) {
+	replies := make([][]types.Device, len(u.networks))
+	errs := make([]error, len(u.networks))
+
+	var wg sync.WaitGroup
+
+	for i, n := range u.networks {
+		wg.Go(func() {
+			replies[i], errs[i] = n.GetDevices()
+		})
+	}
+
+	wg.Wait()
+
+	devices := map[types.SerialNumber]types.Device{}
+	for _, list := range replies {
+		for _, d := range list {
+			if _, ok := devices[d.SerialNumber]; !ok {
+				devices[d.SerialNumber] = d
+			}
+		}
+	}
+
+	errs = slices.DeleteFunc(errs, func(err error) bool { return err == nil })
+
+	if len(errs) > 0 && len(errs) == len(u.networks) {
+		return nil, errors.Join(errs...)
+	}
+
+	list := []types.Device{}
+	for _, k := range slices.Sorted(maps.Keys(devices)) {
+		list = append(list, devices[k])
+	}
+
+	return list, nil
+}
+
+// Listens for events on every network with a valid listen address, until a signal is received
+// on the q channel. If any network listener fails (e.g. the listen address could not be bound),
+// the other network listeners are stopped. Returns the errors from each network listener.
+func (u *UHPPOTE) Listen(listener uhppote.Listener, q chan os.Signal) error {
+	listeners := []member{}
+	for _, n := range u.networks {
+		if len(n.ListenAddrList()) > 0 {
+			listeners = append(listeners, n)
+		}
+	}
+
+	if len(listeners) == 0 {
+		return errors.New("no valid listen address")
+	}
+
+	var wg sync.WaitGroup
+	var guard sync.Mutex
+
+	errs := []error{}
+	signals := []chan os.Signal{}
+	done := make(chan struct{})
+
+	for range listeners {
+		signals = append(signals, make(chan os.Signal, 1))
+	}
+
+	// ... signals every network listener at most once, for either a q signal or a listener error
+	var once sync.Once
+	stop := func(sig os.Signal) {
+		once.Do(func() {
+			for _, ch := range signals {
+				ch <- sig
+			}
+		})
+	}
+
+	for i, n := range listeners {
+		wg.Go(func() {
+			if err := n.Listen(listener, signals[i]); err != nil {
+				guard.Lock()
+				errs = append(errs, err)
+				guard.Unlock()
+
+				stop(os.Interrupt)
+			}
+		})
+	}
+
+	go func() {
+		select {
+		case sig := <-q:
+			stop(sig)
+
+		case <-done:
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+
+	return errors.Join(errs...)
+}
+
+// Returns the configured controllers for all the networks.
+func (u *UHPPOTE) DeviceList() map[uint32]uhppote.Device {
+	devices := map[uint32]uhppote.Device{}
+
+	for _, n := range u.networks {
+		maps.Copy(devices, n.DeviceList())
+	}
+
+	return devices
+}
+
+// Returns the listen addresses for all the networks.
+func (u *UHPPOTE) ListenAddrList() []netip.AddrPort {
+	list := []netip.AddrPort{}
+
+	for _, n := range u.networks {
+		list = append(list, n.ListenAddrList()...)
+	}
+
+	return list
+}
+
+func (u *UHPPOTE) route(controller uint32) member {
+	for _, n := range u.networks {
+		if _, ok := n.DeviceList()[controller]; ok {
+			return n
+		}
+	}
+
+	// ... Join guarantees at least one network
+	return u.networks[0]
+}
@@ -0,0 +1,251 @@
+package network
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+
+	"github.com/uhppoted/uhppoted-lib/simulator"
+)
+
+type listener struct {
+	sync.Mutex
+	connected int
+}
+
+func (l *listener) OnConnected() {
+	l.Lock()
+	defer l.Unlock()
+
+	l.connected++
+}
+
+func (l *listener) OnEvent(status *types.Status) {
+}
+
+func (l *listener) OnError(err error) bool {
+	return true
+}
+
+type slow struct {
+	uhppote.IUHPPOTE
+	delay time.Duration
+}
+
+func (s *slow) GetDevices() ([]types.Device, error) {
+	time.Sleep(s.delay)
+
+	return s.IUHPPOTE.GetDevices()
+}
+
+type unbound struct {
+	uhppote.IUHPPOTE
+}
+
+func (u *unbound) Listen(listener uhppote.Listener, q chan os.Signal) error {
+	return errors.New("address already in use")
+}
+
+func TestJoinWithoutNetworks(t *testing.T) {
+	if _, err := Join(nil); err == nil {
+		t.Errorf("Expected error joining an empty network list")
+	}
+
+	if _, err := Join(map[string]uhppote.IUHPPOTE{"vlan2": nil}); err == nil {
+		t.Errorf("Expected error joining a nil network")
+	}
+
+	if _, err := NewUHPPOTE(nil, time.Second, false); err == nil {
+		t.Errorf("Expected error creating an IUHPPOTE without any networks")
+	}
+}
+
+func TestRoute(t *testing.T) {
+	lan := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	vlan2 := simulator.NewSimulator(simulator.NewController(303986753, "Beta"))
+
+	u, err := Join(map[string]uhppote.IUHPPOTE{
+		"":      lan,
+		"vlan2": vlan2,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if _, err := u.SetDoorControlState(303986753, 1, types.NormallyOpen, 7); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if state, err := vlan2.GetDoorControlState(303986753, 1); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if state.ControlState != types.NormallyOpen || state.Delay != 7 {
+		t.Errorf("Incorrect door control state - expected:%v %v, got:%v %v", types.NormallyOpen, 7, state.ControlState, state.Delay)
+	}
+
+	if _, err := u.GetStatus(405419896); err != nil {
+		t.Errorf("Unexpected error (%v)", err)
+	}
+
+	tests := map[uint32]string{
+		405419896: "",
+		303986753: "vlan2",
+		201020304: "",
+	}
+
+	for controller, expected := range tests {
+		if network := u.Network(controller); network != expected {
+			t.Errorf("Incorrect network for %v - expected:'%v', got:'%v'", controller, expected, network)
+		}
+	}
+}
+
+func TestGetDevices(t *testing.T) {
+	lan := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	vlan2 := simulator.NewSimulator(simulator.NewController(303986753, "Beta"), simulator.NewController(405419896, "Alpha"))
+
+	u, err := Join(map[string]uhppote.IUHPPOTE{
+		"":      lan,
+		"vlan2": vlan2,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	devices, err := u.GetDevices()
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	ids := []types.SerialNumber{}
+	for _, d := range devices {
+		ids = append(ids, d.SerialNumber)
+	}
+
+	if expected := []types.SerialNumber{303986753, 405419896}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Incorrect devices - expected:%v, got:%v", expected, ids)
+	}
+
+	if list := u.DeviceList(); len(list) != 2 {
+		t.Errorf("Incorrect device list - expected:%v controllers, got:%v", 2, len(list))
+	}
+}
+
+func TestGetDevicesConcurrently(t *testing.T) {
+	delay := 100 * time.Millisecond
+
+	u, err := Join(map[string]uhppote.IUHPPOTE{
+		"":      &slow{simulator.NewSimulator(simulator.NewController(405419896, "Alpha")), delay},
+		"vlan2": &slow{simulator.NewSimulator(simulator.NewController(303986753, "Beta")), delay},
+		"vlan3": &slow{simulator.NewSimulator(simulator.NewController(201020304, "Gamma")), delay},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	start := time.Now()
+	devices, err := u.GetDevices()
+	dt := time.Since(start)
+
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if len(devices) != 3 {
+		t.Errorf("Incorrect number of devices - expected:%v, got:%v", 3, len(devices))
+	}
+
+	if dt >= 2*delay {
+		t.Errorf("GetDevices did not broadcast concurrently - expected:<%v, got:%v", 2*delay, dt)
+	}
+}
+
+func TestListen(t *testing.T) {
+	lan := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	vlan2 := simulator.NewSimulator(simulator.NewController(303986753, "Beta"))
+	vlan3 := simulator.NewSimulator()
+
+	lan.SetListenAddrList(netip.MustParseAddrPort("192.168.1.100:60001"))
+	vlan2.SetListenAddrList(netip.MustParseAddrPort("192.168.2.100:60001"))
+
+	u, err := Join(map[string]uhppote.IUHPPOTE{
+		"":      lan,
+		"vlan2": vlan2,
+		"vlan3": vlan3,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	expected := []netip.AddrPort{
+		netip.MustParseAddrPort("192.168.1.100:60001"),
+		netip.MustParseAddrPort("192.168.2.100:60001"),
+	}
+
+	if list := u.ListenAddrList(); !reflect.DeepEqual(list, expected) {
+		t.Errorf("Incorrect listen address list - expected:%v, got:%v", expected, list)
+	}
+
+	l := listener{}
+	q := make(chan os.Signal, 1)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- u.Listen(&l, q)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	q <- os.Interrupt
+
+	select {
+	case err := <-errs:
+		if err != nil {
+			t.Errorf("Unexpected error (%v)", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatalf("Listen did not terminate")
+	}
+
+	if l.connected != 2 {
+		t.Errorf("Incorrect number of listeners - expected:%v, got:%v", 2, l.connected)
+	}
+}
+
+func TestListenWithFailedListener(t *testing.T) {
+	lan := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	vlan2 := simulator.NewSimulator(simulator.NewController(303986753, "Beta"))
+
+	lan.SetListenAddrList(netip.MustParseAddrPort("192.168.1.100:60001"))
+	vlan2.SetListenAddrList(netip.MustParseAddrPort("192.168.2.100:60001"))
+
+	u, err := Join(map[string]uhppote.IUHPPOTE{
+		"":      lan,
+		"vlan2": &unbound{vlan2},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- u.Listen(&listener{}, make(chan os.Signal, 1))
+	}()
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Errorf("Expected listen error, got:%v", err)
+		}
+
+	case <-time.After(time.Second):
+		t.Fatalf("Listen did not stop the other listeners after a listener error")
+	}
+}
@@ -0,0 +1,148 @@
+package network
+
+import (
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+var _ uhppote.IUHPPOTE = (*UHPPOTE)(nil)
+
+func (u *UHPPOTE) GetDevice(controller uint32) (*types.Device, error) {
+	return u.route(controller).GetDevice(controller)
+}
+
+func (u *UHPPOTE) SetAddress(controller uint32, address, mask, gateway net.IP) (*types.Result, error) {
+	return u.route(controller).SetAddress(controller, address, mask, gateway)
+}
+
+func (u *UHPPOTE) GetListener(controller uint32) (netip.AddrPort, uint8, error) {
+	return u.route(controller).GetListener(controller)
+}
+
+func (u *UHPPOTE) SetListener(controller uint32, address netip.AddrPort, interval uint8) (bool, error) {
+	return u.route(controller).SetListener(controller, address, interval)
+}
+
+func (u *UHPPOTE) GetTime(controller uint32) (*types.Time, error) {
+	return u.route(controller).GetTime(controller)
+}
+
+func (u *UHPPOTE) SetTime(controller uint32, datetime time.Time) (*types.Time, error) {
+	return u.route(controller).SetTime(controller, datetime)
+}
+
+func (u *UHPPOTE) GetDoorControlState(controller uint32, door byte) (*types.DoorControlState, error) {
+	return u.route(controller).GetDoorControlState(controller, door)
+}
+
+func (u *UHPPOTE) SetDoorControlState(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+	return u.route(controller).SetDoorControlState(controller, door, state, delay)
+}
+
+func (u *UHPPOTE) GetStatus(controller uint32) (*types.Status, error) {
+	return u.route(controller).GetStatus(controller)
+}
+
+func (u *UHPPOTE) GetCards(controller uint32) (uint32, error) {
+	return u.route(controller).GetCards(controller)
+}
+
+func (u *UHPPOTE) GetCardByIndex(controller, index uint32) (*types.Card, error) {
+	return u.route(controller).GetCardByIndex(controller, index)
+}
+
+func (u *UHPPOTE) GetCardByID(controller, cardNumber uint32) (*types.Card, error) {
+	return u.route(controller).GetCardByID(controller, cardNumber)
+}
+
+func (u *UHPPOTE) PutCard(controller uint32, card types.Card, formats ...types.CardFormat) (bool, error) {
+	return u.route(controller).PutCard(controller, card, formats...)
+}
+
+func (u *UHPPOTE) DeleteCard(controller uint32, cardNumber uint32) (bool, error) {
+	return u.route(controller).DeleteCard(controller, cardNumber)
+}
+
+func (u *UHPPOTE) DeleteCards(controller uint32) (bool, error) {
+	return u.route(controller).DeleteCards(controller)
+}
+
+func (u *UHPPOTE) GetTimeProfile(controller uint32, profileID uint8) (*types.TimeProfile, error) {
+	return u.route(controller).GetTimeProfile(controller, profileID)
+}
+
+func (u *UHPPOTE) SetTimeProfile(controller uint32, profile types.TimeProfile) (bool, error) {
+	return u.route(controller).SetTimeProfile(controller, profile)
+}
+
+func (u *UHPPOTE) ClearTimeProfiles(controller uint32) (bool, error) {
+	return u.route(controller).ClearTimeProfiles(controller)
+}
+
+func (u *UHPPOTE) ClearTaskList(controller uint32) (bool, error) {
+	return u.route(controller).ClearTaskList(controller)
+}
+
+func (u *UHPPOTE) AddTask(controller uint32, task types.Task) (bool, error) {
+	return u.route(controller).AddTask(controller, task)
+}
+
+func (u *UHPPOTE) RefreshTaskList(controller uint32) (bool, error) {
+	return u.route(controller).RefreshTaskList(controller)
+}
+
+func (u *UHPPOTE) RecordSpecialEvents(controller uint32, enable bool) (bool, error) {
+	return u.route(controller).RecordSpecialEvents(controller, enable)
+}
+
+func (u *UHPPOTE) GetEvent(controller, index uint32) (*types.Event, error) {
+	return u.route(controller).GetEvent(controller, index)
+}
+
+func (u *UHPPOTE) GetEventIndex(controller uint32) (*types.EventIndex, error) {
+	return u.route(controller).GetEventIndex(controller)
+}
+
+func (u *UHPPOTE) SetEventIndex(controller, index uint32) (*types.EventIndexResult, error) {
+	return u.route(controller).SetEventIndex(controller, index)
+}
+
+func (u *UHPPOTE) SetDoorPasscodes(controller uint32, door uint8, passcodes ...uint32) (bool, error) {
+	return u.route(controller).SetDoorPasscodes(controller, door, passcodes...)
+}
+
+func (u *UHPPOTE) OpenDoor(controller uint32, door uint8) (*types.Result, error) {
+	return u.route(controller).OpenDoor(controller, door)
+}
+
+func (u *UHPPOTE) SetPCControl(controller uint32, enable bool) (bool, error) {
+	return u.route(controller).SetPCControl(controller, enable)
+}
+
+func (u *UHPPOTE) SetInterlock(controller uint32, interlock types.Interlock) (bool, error) {
+	return u.route(controller).SetInterlock(controller, interlock)
+}
+
+func (u *UHPPOTE) ActivateKeypads(controller uint32, readers map[uint8]bool) (bool, error) {
+	return u.route(controller).ActivateKeypads(controller, readers)
+}
+
+func (u *UHPPOTE) GetAntiPassback(controller uint32) (types.AntiPassback, error) {
+	return u.route(controller).GetAntiPassback(controller)
+}
+
+func (u *UHPPOTE) SetAntiPassback(controller uint32, antipassback types.AntiPassback) (bool, error) {
+	return u.route(controller).SetAntiPassback(controller, antipassback)
+}
+
+func (u *UHPPOTE) SetFirstCard(controller uint32, door uint8, firstcard types.FirstCard) (bool, error) {
+	return u.route(controller).SetFirstCard(controller, door, firstcard)
+}
+
+func (u *UHPPOTE) RestoreDefaultParameters(controller uint32) (bool, error) {
+	return u.route(controller).RestoreDefaultParameters(controller)
+}
@@ -9,5 +9,6 @@ It currently implements:
   - a replacement function for os.Rename to workaround the 'invalid cross-device link' error
     when renaming across file-systems (cf. https://github.com/uhppoted/uhppoted-httpd/issues/20)
   - IsDevNull function to check if a filepath corresponds to the system /dev/null device
+  - a replacement function for os.IsTimeout that also handles wrapped, context and network timeouts
 */
 package os
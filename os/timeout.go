@@ -0,0 +1,20 @@
+package os
+
+import (
+	"context"
+	"errors"
+	"net"
+	sys "os"
+)
+
+// Replacement for os.IsTimeout that also unwraps the error and returns true for context and
+// network timeouts.
+func IsTimeout(err error) bool {
+	var nerr net.Error
+
+	if errors.Is(err, sys.ErrDeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	return errors.As(err, &nerr) && nerr.Timeout()
+}
@@ -0,0 +1,199 @@
+// Package retry implements a uhppote.IUHPPOTE decorator that retries idempotent requests that
+// time out, with exponential backoff, and 'trips' a per-controller circuit breaker after repeated
+// failures so that requests to an unreachable controller fail fast.
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/uhppote"
+
+	lib "github.com/uhppoted/uhppoted-lib/os"
+)
+
+// Policy is the retry and circuit breaker configuration.
+type Policy struct {
+	Attempts   int           // maximum number of attempts for an idempotent request (including the first)
+	Backoff    time.Duration // delay before the first retry
+	MaxBackoff time.Duration // maximum delay between retries
+	Multiplier float64       // backoff multiplier for successive retries
+	Threshold  int           // consecutive failed requests that 'trip' the circuit breaker, 0 to disable
+	Cooldown   time.Duration // interval after which a tripped circuit breaker allows a trial request
+}
+
+// Metrics is the retry and circuit breaker activity for a controller.
+type Metrics struct {
+	Requests uint64 `json:"requests"`
+	Retries  uint64 `json:"retries"`
+	Failures uint64 `json:"failures"`
+	Rejected uint64 `json:"rejected"` // requests rejected by an open circuit breaker
+	Open     bool   `json:"open"`
+}
+
+// ErrCircuitOpen is returned for requests to a controller with a tripped circuit breaker.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// UHPPOTE wraps a uhppote.IUHPPOTE with the retry policy. Operations that are not overridden
+// are passed through as is.
+type UHPPOTE struct {
+	uhppote.IUHPPOTE
+	policy   Policy
+	breakers map[uint32]*breaker
+	sleep    func(time.Duration)
+	now      func() time.Time
+	guard    sync.Mutex
+}
+
+type breaker struct {
+	failures int
+	opened   time.Time
+	metrics  Metrics
+}
+
+// Returns the default policy, i.e. up to 3 attempts with a 250ms initial backoff and a circuit
+// breaker that opens for 30 seconds after 5 consecutive failures.
+func DefaultPolicy() Policy {
+	return Policy{
+		Attempts:   3,
+		Backoff:    250 * time.Millisecond,
+		MaxBackoff: 2 * time.Second,
+		Multiplier: 2.0,
+		Threshold:  5,
+		Cooldown:   30 * time.Second,
+	}
+}
+
+func NewUHPPOTE(u uhppote.IUHPPOTE, policy Policy) *UHPPOTE {
+	return &UHPPOTE{
+		IUHPPOTE: u,
+		policy:   policy,
+		breakers: map[uint32]*breaker{},
+		sleep:    time.Sleep,
+		now:      time.Now,
+	}
+}
+
+// Returns a snapshot of the retry metrics for each controller.
+func (r *UHPPOTE) Metrics() map[uint32]Metrics {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+
+	metrics := map[uint32]Metrics{}
+	for id, b := range r.breakers {
+		m := b.metrics
+		m.Open = r.isOpen(b)
+		metrics[id] = m
+	}
+
+	return metrics
+}
+
+// Invokes f, retrying on timeout if the operation is idempotent. The circuit breaker is only
+// applied to requests for a specific controller and only counts timeouts and network errors -
+// any other error (e.g. an invalid request or a request rejected by the controller) does not
+// indicate an unreachable controller and resets the breaker as for a successful request.
+func call[T any](r *UHPPOTE, controller uint32, operation string, idempotent bool, f func() (T, error)) (T, error) {
+	var zero T
+
+	if err := r.allow(controller); err != nil {
+		return zero, fmt.Errorf("%v %v: %w", controller, operation, err)
+	}
+
+	attempts := 1
+	if idempotent {
+		attempts = max(1, r.policy.Attempts)
+	}
+
+	delay := r.policy.Backoff
+	for attempt := 1; ; attempt++ {
+		v, err := f()
+		if err == nil || !lib.IsTimeout(err) {
+			r.record(controller, attempt-1, err == nil || !isTransportError(err))
+			return v, err
+		}
+
+		if attempt >= attempts {
+			r.record(controller, attempt-1, false)
+			return v, err
+		}
+
+		r.sleep(delay)
+
+		delay = time.Duration(float64(delay) * r.policy.Multiplier)
+		if r.policy.MaxBackoff > 0 {
+			delay = min(delay, r.policy.MaxBackoff)
+		}
+	}
+}
+
+func (r *UHPPOTE) allow(controller uint32) error {
+	if controller == 0 {
+		return nil
+	}
+
+	r.guard.Lock()
+	defer r.guard.Unlock()
+
+	b := r.breaker(controller)
+	b.metrics.Requests++
+
+	if r.isOpen(b) {
+		b.metrics.Rejected++
+		return ErrCircuitOpen
+	}
+
+	return nil
+}
+
+func (r *UHPPOTE) record(controller uint32, retries int, reachable bool) {
+	if controller == 0 {
+		return
+	}
+
+	r.guard.Lock()
+	defer r.guard.Unlock()
+
+	b := r.breaker(controller)
+	b.metrics.Retries += uint64(retries)
+
+	if reachable {
+		b.failures = 0
+	} else {
+		b.metrics.Failures++
+		if b.failures++; r.policy.Threshold > 0 && b.failures >= r.policy.Threshold {
+			b.opened = r.now()
+		}
+	}
+}
+
+func (r *UHPPOTE) breaker(controller uint32) *breaker {
+	b, ok := r.breakers[controller]
+	if !ok {
+		b = &breaker{}
+		r.breakers[controller] = b
+	}
+
+	return b
+}
+
+// A tripped breaker stays open for the cooldown interval, after which requests are allowed
+// through again. A failed 'trial' request re-opens the breaker immediately because the
+// consecutive failure count is only reset by a successful request.
+func (r *UHPPOTE) isOpen(b *breaker) bool {
+	if r.policy.Threshold <= 0 || b.failures < r.policy.Threshold {
+		return false
+	}
+
+	return r.now().Before(b.opened.Add(r.policy.Cooldown))
+}
+
+// Returns true for network errors e.g. 'connection refused' or 'no route to host'.
+func isTransportError(err error) bool {
+	var nerr net.Error
+
+	return errors.As(err, &nerr) || errors.Is(err, net.ErrClosed)
+}
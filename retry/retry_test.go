@@ -0,0 +1,139 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+
+	"github.com/uhppoted/uhppoted-lib/simulator"
+)
+
+func TestRetry(t *testing.T) {
+	s := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	s.Inject(simulator.Fault{Operation: "get-status", Type: simulator.Timeout, Count: 2})
+
+	delays := []time.Duration{}
+	r := NewUHPPOTE(s, DefaultPolicy())
+	r.sleep = func(dt time.Duration) { delays = append(delays, dt) }
+
+	if _, err := r.GetStatus(405419896); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if expected := []time.Duration{250 * time.Millisecond, 500 * time.Millisecond}; !reflect.DeepEqual(delays, expected) {
+		t.Errorf("Incorrect backoff - expected:%v, got:%v", expected, delays)
+	}
+
+	expected := Metrics{Requests: 1, Retries: 2}
+	if metrics := r.Metrics()[405419896]; metrics != expected {
+		t.Errorf("Incorrect metrics - expected:%+v, got:%+v", expected, metrics)
+	}
+}
+
+func TestRetryWithNonIdempotentOperation(t *testing.T) {
+	s := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	s.Inject(simulator.Fault{Operation: "add-task", Type: simulator.Dropped, Count: 1})
+
+	r := NewUHPPOTE(s, DefaultPolicy())
+	r.sleep = func(dt time.Duration) {}
+
+	if _, err := r.AddTask(405419896, types.Task{Task: types.EnableTimeProfile, Door: 1}); !errors.Is(err, simulator.ErrTimeout) {
+		t.Errorf("Expected timeout, got %v", err)
+	}
+
+	if metrics := r.Metrics()[405419896]; metrics.Retries != 0 || metrics.Failures != 1 {
+		t.Errorf("Expected no retries for non-idempotent operation, got %+v", metrics)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 12, 30, 0, 0, time.UTC)
+
+	s := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	s.Inject(simulator.Fault{Controller: 405419896, Type: simulator.Timeout})
+
+	policy := DefaultPolicy()
+	policy.Attempts = 2
+	policy.Threshold = 2
+	policy.Cooldown = 30 * time.Second
+
+	r := NewUHPPOTE(s, policy)
+	r.sleep = func(dt time.Duration) {}
+	r.now = func() time.Time { return now }
+
+	for range 2 {
+		if _, err := r.GetStatus(405419896); !errors.Is(err, simulator.ErrTimeout) {
+			t.Errorf("Expected timeout, got %v", err)
+		}
+	}
+
+	if _, err := r.GetStatus(405419896); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected 'circuit open' error, got %v", err)
+	}
+
+	expected := Metrics{Requests: 3, Retries: 2, Failures: 2, Rejected: 1, Open: true}
+	if metrics := r.Metrics()[405419896]; metrics != expected {
+		t.Errorf("Incorrect metrics - expected:%+v, got:%+v", expected, metrics)
+	}
+
+	// ... trial request after cooldown
+	s.ClearFaults()
+	now = now.Add(31 * time.Second)
+
+	if _, err := r.GetStatus(405419896); err != nil {
+		t.Errorf("Unexpected error after cooldown (%v)", err)
+	}
+
+	if metrics := r.Metrics()[405419896]; metrics.Open {
+		t.Errorf("Expected circuit breaker to be closed after successful request")
+	}
+}
+
+type invalid struct {
+	*simulator.Simulator
+}
+
+func (s invalid) GetStatus(controller uint32) (*types.Status, error) {
+	return nil, fmt.Errorf("%v: invalid request", controller)
+}
+
+func TestCircuitBreakerWithNonTransportErrors(t *testing.T) {
+	s := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+
+	policy := DefaultPolicy()
+	policy.Attempts = 1
+	policy.Threshold = 2
+
+	r := NewUHPPOTE(invalid{s}, policy)
+	r.sleep = func(dt time.Duration) {}
+
+	for range 5 {
+		if _, err := r.GetStatus(405419896); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Expected 'invalid request' error, got %v", err)
+		}
+	}
+
+	if _, err := r.GetTime(405419896); err != nil {
+		t.Errorf("Unexpected error (%v)", err)
+	}
+
+	expected := Metrics{Requests: 6}
+	if metrics := r.Metrics()[405419896]; metrics != expected {
+		t.Errorf("Incorrect metrics - expected:%+v, got:%+v", expected, metrics)
+	}
+
+	// ... a reply resets the consecutive failure count
+	s.Inject(simulator.Fault{Operation: "get-time", Type: simulator.Timeout, Count: 2})
+
+	r.GetTime(405419896)
+	r.GetStatus(405419896)
+	r.GetTime(405419896)
+
+	if _, err := r.GetTime(405419896); err != nil {
+		t.Errorf("Unexpected error (%v)", err)
+	}
+}
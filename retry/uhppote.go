@@ -0,0 +1,234 @@
+package retry
+
+import (
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+var _ uhppote.IUHPPOTE = (*UHPPOTE)(nil)
+
+// Operations that are not idempotent (AddTask, RefreshTaskList, OpenDoor and
+// RestoreDefaultParameters) are not retried but are still subject to the circuit breaker.
+
+func (r *UHPPOTE) GetDevices() ([]types.Device, error) {
+	return call(r, 0, "get-devices", true, func() ([]types.Device, error) {
+		return r.IUHPPOTE.GetDevices()
+	})
+}
+
+func (r *UHPPOTE) GetDevice(controller uint32) (*types.Device, error) {
+	return call(r, controller, "get-device", true, func() (*types.Device, error) {
+		return r.IUHPPOTE.GetDevice(controller)
+	})
+}
+
+func (r *UHPPOTE) SetAddress(controller uint32, address, mask, gateway net.IP) (*types.Result, error) {
+	return call(r, controller, "set-address", true, func() (*types.Result, error) {
+		return r.IUHPPOTE.SetAddress(controller, address, mask, gateway)
+	})
+}
+
+func (r *UHPPOTE) GetListener(controller uint32) (netip.AddrPort, uint8, error) {
+	type listener struct {
+		address  netip.AddrPort
+		interval uint8
+	}
+
+	v, err := call(r, controller, "get-listener", true, func() (listener, error) {
+		address, interval, err := r.IUHPPOTE.GetListener(controller)
+
+		return listener{address, interval}, err
+	})
+
+	return v.address, v.interval, err
+}
+
+func (r *UHPPOTE) SetListener(controller uint32, address netip.AddrPort, interval uint8) (bool, error) {
+	return call(r, controller, "set-listener", true, func() (bool, error) {
+		return r.IUHPPOTE.SetListener(controller, address, interval)
+	})
+}
+
+func (r *UHPPOTE) GetTime(controller uint32) (*types.Time, error) {
+	return call(r, controller, "get-time", true, func() (*types.Time, error) {
+		return r.IUHPPOTE.GetTime(controller)
+	})
+}
+
+func (r *UHPPOTE) SetTime(controller uint32, datetime time.Time) (*types.Time, error) {
+	return call(r, controller, "set-time", true, func() (*types.Time, error) {
+		return r.IUHPPOTE.SetTime(controller, datetime)
+	})
+}
+
+func (r *UHPPOTE) GetDoorControlState(controller uint32, door byte) (*types.DoorControlState, error) {
+	return call(r, controller, "get-door-control-state", true, func() (*types.DoorControlState, error) {
+		return r.IUHPPOTE.GetDoorControlState(controller, door)
+	})
+}
+
+func (r *UHPPOTE) SetDoorControlState(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+	return call(r, controller, "set-door-control-state", true, func() (*types.DoorControlState, error) {
+		return r.IUHPPOTE.SetDoorControlState(controller, door, state, delay)
+	})
+}
+
+func (r *UHPPOTE) GetStatus(controller uint32) (*types.Status, error) {
+	return call(r, controller, "get-status", true, func() (*types.Status, error) {
+		return r.IUHPPOTE.GetStatus(controller)
+	})
+}
+
+func (r *UHPPOTE) GetCards(controller uint32) (uint32, error) {
+	return call(r, controller, "get-cards", true, func() (uint32, error) {
+		return r.IUHPPOTE.GetCards(controller)
+	})
+}
+
+func (r *UHPPOTE) GetCardByIndex(controller, index uint32) (*types.Card, error) {
+	return call(r, controller, "get-card-by-index", true, func() (*types.Card, error) {
+		return r.IUHPPOTE.GetCardByIndex(controller, index)
+	})
+}
+
+func (r *UHPPOTE) GetCardByID(controller, cardNumber uint32) (*types.Card, error) {
+	return call(r, controller, "get-card-by-id", true, func() (*types.Card, error) {
+		return r.IUHPPOTE.GetCardByID(controller, cardNumber)
+	})
+}
+
+func (r *UHPPOTE) PutCard(controller uint32, card types.Card, formats ...types.CardFormat) (bool, error) {
+	return call(r, controller, "put-card", true, func() (bool, error) {
+		return r.IUHPPOTE.PutCard(controller, card, formats...)
+	})
+}
+
+func (r *UHPPOTE) DeleteCard(controller uint32, cardNumber uint32) (bool, error) {
+	return call(r, controller, "delete-card", true, func() (bool, error) {
+		return r.IUHPPOTE.DeleteCard(controller, cardNumber)
+	})
+}
+
+func (r *UHPPOTE) DeleteCards(controller uint32) (bool, error) {
+	return call(r, controller, "delete-cards", true, func() (bool, error) {
+		return r.IUHPPOTE.DeleteCards(controller)
+	})
+}
+
+func (r *UHPPOTE) GetTimeProfile(controller uint32, profileID uint8) (*types.TimeProfile, error) {
+	return call(r, controller, "get-time-profile", true, func() (*types.TimeProfile, error) {
+		return r.IUHPPOTE.GetTimeProfile(controller, profileID)
+	})
+}
+
+func (r *UHPPOTE) SetTimeProfile(controller uint32, profile types.TimeProfile) (bool, error) {
+	return call(r, controller, "set-time-profile", true, func() (bool, error) {
+		return r.IUHPPOTE.SetTimeProfile(controller, profile)
+	})
+}
+
+func (r *UHPPOTE) ClearTimeProfiles(controller uint32) (bool, error) {
+	return call(r, controller, "clear-time-profiles", true, func() (bool, error) {
+		return r.IUHPPOTE.ClearTimeProfiles(controller)
+	})
+}
+
+func (r *UHPPOTE) ClearTaskList(controller uint32) (bool, error) {
+	return call(r, controller, "clear-task-list", true, func() (bool, error) {
+		return r.IUHPPOTE.ClearTaskList(controller)
+	})
+}
+
+func (r *UHPPOTE) AddTask(controller uint32, task types.Task) (bool, error) {
+	return call(r, controller, "add-task", false, func() (bool, error) {
+		return r.IUHPPOTE.AddTask(controller, task)
+	})
+}
+
+func (r *UHPPOTE) RefreshTaskList(controller uint32) (bool, error) {
+	return call(r, controller, "refresh-task-list", false, func() (bool, error) {
+		return r.IUHPPOTE.RefreshTaskList(controller)
+	})
+}
+
+func (r *UHPPOTE) RecordSpecialEvents(controller uint32, enable bool) (bool, error) {
+	return call(r, controller, "record-special-events", true, func() (bool, error) {
+		return r.IUHPPOTE.RecordSpecialEvents(controller, enable)
+	})
+}
+
+func (r *UHPPOTE) GetEvent(controller, index uint32) (*types.Event, error) {
+	return call(r, controller, "get-event", true, func() (*types.Event, error) {
+		return r.IUHPPOTE.GetEvent(controller, index)
+	})
+}
+
+func (r *UHPPOTE) GetEventIndex(controller uint32) (*types.EventIndex, error) {
+	return call(r, controller, "get-event-index", true, func() (*types.EventIndex, error) {
+		return r.IUHPPOTE.GetEventIndex(controller)
+	})
+}
+
+func (r *UHPPOTE) SetEventIndex(controller, index uint32) (*types.EventIndexResult, error) {
+	return call(r, controller, "set-event-index", true, func() (*types.EventIndexResult, error) {
+		return r.IUHPPOTE.SetEventIndex(controller, index)
+	})
+}
+
+func (r *UHPPOTE) SetDoorPasscodes(controller uint32, door uint8, passcodes ...uint32) (bool, error) {
+	return call(r, controller, "set-door-passcodes", true, func() (bool, error) {
+		return r.IUHPPOTE.SetDoorPasscodes(controller, door, passcodes...)
+	})
+}
+
+func (r *UHPPOTE) OpenDoor(controller uint32, door uint8) (*types.Result, error) {
+	return call(r, controller, "open-door", false, func() (*types.Result, error) {
+		return r.IUHPPOTE.OpenDoor(controller, door)
+	})
+}
+
+func (r *UHPPOTE) SetPCControl(controller uint32, enable bool) (bool, error) {
+	return call(r, controller, "set-pc-control", true, func() (bool, error) {
+		return r.IUHPPOTE.SetPCControl(controller, enable)
+	})
+}
+
+func (r *UHPPOTE) SetInterlock(controller uint32, interlock types.Interlock) (bool, error) {
+	return call(r, controller, "set-interlock", true, func() (bool, error) {
+		return r.IUHPPOTE.SetInterlock(controller, interlock)
+	})
+}
+
+func (r *UHPPOTE) ActivateKeypads(controller uint32, readers map[uint8]bool) (bool, error) {
+	return call(r, controller, "activate-keypads", true, func() (bool, error) {
+		return r.IUHPPOTE.ActivateKeypads(controller, readers)
+	})
+}
+
+func (r *UHPPOTE) GetAntiPassback(controller uint32) (types.AntiPassback, error) {
+	return call(r, controller, "get-antipassback", true, func() (types.AntiPassback, error) {
+		return r.IUHPPOTE.GetAntiPassback(controller)
+	})
+}
+
+func (r *UHPPOTE) SetAntiPassback(controller uint32, antipassback types.AntiPassback) (bool, error) {
+	return call(r, controller, "set-antipassback", true, func() (bool, error) {
+		return r.IUHPPOTE.SetAntiPassback(controller, antipassback)
+	})
+}
+
+func (r *UHPPOTE) SetFirstCard(controller uint32, door uint8, firstcard types.FirstCard) (bool, error) {
+	return call(r, controller, "set-first-card", true, func() (bool, error) {
+		return r.IUHPPOTE.SetFirstCard(controller, door, firstcard)
+	})
+}
+
+func (r *UHPPOTE) RestoreDefaultParameters(controller uint32) (bool, error) {
+	return call(r, controller, "restore-default-parameters", false, func() (bool, error) {
+		return r.IUHPPOTE.RestoreDefaultParameters(controller)
+	})
+}
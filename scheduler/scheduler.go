@@ -0,0 +1,199 @@
+// Package scheduler implements a uhppote.IUHPPOTE decorator that limits the number of concurrent
+// requests to each controller (serializing them by default) and the rate at which requests are
+// sent, dispatching queued 'interactive' requests (e.g. OpenDoor) ahead of 'bulk' requests (e.g.
+// the PutCard requests of an ACL update).
+package scheduler
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+// Default number of concurrent requests per controller.
+const CONCURRENCY = 1
+
+// Default minimum interval between requests to a controller.
+const INTERVAL = 0 * time.Millisecond
+
+// Priority is the dispatch priority of an operation. Queued requests with a higher priority are
+// dispatched first, and requests with the same priority are dispatched in order.
+type Priority int
+
+const (
+	Bulk Priority = iota
+	Normal
+	Interactive
+)
+
+// Policy is the scheduler configuration.
+type Policy struct {
+	Concurrency int                 // maximum concurrent requests per controller
+	Interval    time.Duration       // minimum interval between requests to a controller
+	Priorities  map[string]Priority // per-operation priority overrides, e.g. "get-status": Interactive
+}
+
+// Scheduler wraps a uhppote.IUHPPOTE with per-controller request scheduling. Broadcast requests
+// (GetDevices) and the operations that are not overridden are passed through as is.
+type Scheduler struct {
+	uhppote.IUHPPOTE
+	policy     Policy
+	priorities map[string]Priority
+	lanes      map[uint32]*lane
+	now        func() time.Time
+	guard      sync.Mutex
+}
+
+type lane struct {
+	active  int
+	last    time.Time
+	waiting []*waiter
+	timer   *time.Timer
+}
+
+type waiter struct {
+	priority Priority
+	ready    chan struct{}
+}
+
+var priorities = map[string]Priority{
+	"open-door":              Interactive,
+	"get-status":             Interactive,
+	"get-door-control-state": Interactive,
+	"set-door-control-state": Interactive,
+	"set-door-passcodes":     Interactive,
+	"set-pc-control":         Interactive,
+	"set-interlock":          Interactive,
+	"get-cards":              Bulk,
+	"get-card-by-index":      Bulk,
+	"put-card":               Bulk,
+	"delete-card":            Bulk,
+	"delete-cards":           Bulk,
+	"get-event":              Bulk,
+	"get-time-profile":       Bulk,
+	"set-time-profile":       Bulk,
+	"clear-time-profiles":    Bulk,
+	"add-task":               Bulk,
+	"clear-task-list":        Bulk,
+	"refresh-task-list":      Bulk,
+}
+
+// Returns the default policy i.e. requests to a controller are serialized without any
+// additional rate limiting.
+func DefaultPolicy() Policy {
+	return Policy{
+		Concurrency: CONCURRENCY,
+		Interval:    INTERVAL,
+	}
+}
+
+func NewScheduler(u uhppote.IUHPPOTE, policy Policy) *Scheduler {
+	p := maps.Clone(priorities)
+	maps.Copy(p, policy.Priorities)
+
+	return &Scheduler{
+		IUHPPOTE:   u,
+		policy:     policy,
+		priorities: p,
+		lanes:      map[uint32]*lane{},
+		now:        time.Now,
+	}
+}
+
+// Returns the number of requests waiting to be dispatched to each controller.
+func (s *Scheduler) Queued() map[uint32]int {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	queued := map[uint32]int{}
+	for id, l := range s.lanes {
+		if N := len(l.waiting); N > 0 {
+			queued[id] = N
+		}
+	}
+
+	return queued
+}
+
+// Waits for a dispatch 'slot' for the controller and then invokes f.
+func call[T any](s *Scheduler, controller uint32, operation string, f func() (T, error)) (T, error) {
+	s.acquire(controller, s.priority(operation))
+	defer s.release(controller)
+
+	return f()
+}
+
+func (s *Scheduler) priority(operation string) Priority {
+	if p, ok := s.priorities[operation]; ok {
+		return p
+	}
+
+	return Normal
+}
+
+func (s *Scheduler) acquire(controller uint32, priority Priority) {
+	w := waiter{
+		priority: priority,
+		ready:    make(chan struct{}),
+	}
+
+	s.guard.Lock()
+	l, ok := s.lanes[controller]
+	if !ok {
+		l = &lane{}
+		s.lanes[controller] = l
+	}
+
+	l.waiting = append(l.waiting, &w)
+	s.dispatch(l)
+	s.guard.Unlock()
+
+	<-w.ready
+}
+
+func (s *Scheduler) release(controller uint32) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if l, ok := s.lanes[controller]; ok {
+		l.active--
+		s.dispatch(l)
+	}
+}
+
+// Dispatches the highest priority waiting requests while the lane has capacity. If the minimum
+// interval since the last request has not elapsed, a timer is started to retry the dispatch.
+// Must be called with the guard locked.
+func (s *Scheduler) dispatch(l *lane) {
+	concurrency := max(1, s.policy.Concurrency)
+
+	for l.active < concurrency && len(l.waiting) > 0 && l.timer == nil {
+		if dt := l.last.Add(s.policy.Interval).Sub(s.now()); dt > 0 {
+			l.timer = time.AfterFunc(dt, func() {
+				s.guard.Lock()
+				defer s.guard.Unlock()
+
+				l.timer = nil
+				s.dispatch(l)
+			})
+
+			return
+		}
+
+		next := 0
+		for i, w := range l.waiting {
+			if w.priority > l.waiting[next].priority {
+				next = i
+			}
+		}
+
+		w := l.waiting[next]
+		l.waiting = append(l.waiting[:next], l.waiting[next+1:]...)
+		l.active++
+		l.last = s.now()
+
+		close(w.ready)
+	}
+}
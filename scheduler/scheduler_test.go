@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+
+	"github.com/uhppoted/uhppoted-lib/simulator"
+)
+
+type blocking struct {
+	*simulator.Simulator
+	gate   chan struct{}
+	active int
+	peak   int
+	order  []string
+	sync.Mutex
+}
+
+func (b *blocking) exec(op string) {
+	b.Lock()
+	b.active++
+	b.peak = max(b.peak, b.active)
+	b.order = append(b.order, op)
+	b.Unlock()
+
+	<-b.gate
+
+	b.Lock()
+	b.active--
+	b.Unlock()
+}
+
+func (b *blocking) GetStatus(controller uint32) (*types.Status, error) {
+	b.exec("get-status")
+	return b.Simulator.GetStatus(controller)
+}
+
+func (b *blocking) PutCard(controller uint32, card types.Card, formats ...types.CardFormat) (bool, error) {
+	b.exec("put-card")
+	return b.Simulator.PutCard(controller, card, formats...)
+}
+
+func (b *blocking) OpenDoor(controller uint32, door uint8) (*types.Result, error) {
+	b.exec("open-door")
+	return b.Simulator.OpenDoor(controller, door)
+}
+
+func waitForQueue(t *testing.T, s *Scheduler, controller uint32, N int) {
+	for range 500 {
+		if s.Queued()[controller] == N {
+			return
+		}
+
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	t.Fatalf("timeout waiting for %v queued requests (%v)", N, s.Queued())
+}
+
+func TestSerialization(t *testing.T) {
+	u := blocking{
+		Simulator: simulator.NewSimulator(simulator.NewController(405419896, "Alpha")),
+		gate:      make(chan struct{}),
+	}
+
+	s := NewScheduler(&u, DefaultPolicy())
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Go(func() {
+			s.GetStatus(405419896)
+		})
+	}
+
+	waitForQueue(t, s, 405419896, 4)
+
+	for range 5 {
+		u.gate <- struct{}{}
+	}
+
+	wg.Wait()
+
+	if u.peak != 1 {
+		t.Errorf("Expected serialized requests, got %v concurrent requests", u.peak)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	u := blocking{
+		Simulator: simulator.NewSimulator(simulator.NewController(405419896, "Alpha")),
+		gate:      make(chan struct{}),
+	}
+
+	s := NewScheduler(&u, DefaultPolicy())
+	card := types.Card{CardNumber: 10058400, From: types.MustParseDate("2026-01-01"), To: types.MustParseDate("2026-12-31")}
+
+	var wg sync.WaitGroup
+	for i := range 3 {
+		wg.Go(func() {
+			s.PutCard(405419896, card)
+		})
+
+		waitForQueue(t, s, 405419896, i)
+	}
+
+	wg.Go(func() {
+		s.OpenDoor(405419896, 1)
+	})
+
+	waitForQueue(t, s, 405419896, 3)
+
+	for range 4 {
+		u.gate <- struct{}{}
+	}
+
+	wg.Wait()
+
+	if expected := []string{"put-card", "open-door", "put-card", "put-card"}; !reflect.DeepEqual(u.order, expected) {
+		t.Errorf("Incorrect dispatch order\n   expected:%v\n   got:     %v", expected, u.order)
+	}
+}
+
+func TestInterval(t *testing.T) {
+	sim := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	s := NewScheduler(sim, Policy{Concurrency: 1, Interval: 20 * time.Millisecond})
+
+	start := time.Now()
+	for range 3 {
+		if _, err := s.GetStatus(405419896); err != nil {
+			t.Fatalf("Unexpected error (%v)", err)
+		}
+	}
+
+	if dt := time.Since(start); dt < 40*time.Millisecond {
+		t.Errorf("Expected requests to be rate limited - 3 requests in %v", dt)
+	}
+}
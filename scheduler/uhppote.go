@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+var _ uhppote.IUHPPOTE = (*Scheduler)(nil)
+
+func (s *Scheduler) GetDevice(controller uint32) (*types.Device, error) {
+	return call(s, controller, "get-device", func() (*types.Device, error) {
+		return s.IUHPPOTE.GetDevice(controller)
+	})
+}
+
+func (s *Scheduler) SetAddress(controller uint32, address, mask, gateway net.IP) (*types.Result, error) {
+	return call(s, controller, "set-address", func() (*types.Result, error) {
+		return s.IUHPPOTE.SetAddress(controller, address, mask, gateway)
+	})
+}
+
+func (s *Scheduler) GetListener(controller uint32) (netip.AddrPort, uint8, error) {
+	type listener struct {
+		address  netip.AddrPort
+		interval uint8
+	}
+
+	v, err := call(s, controller, "get-listener", func() (listener, error) {
+		address, interval, err := s.IUHPPOTE.GetListener(controller)
+
+		return listener{address, interval}, err
+	})
+
+	return v.address, v.interval, err
+}
+
+func (s *Scheduler) SetListener(controller uint32, address netip.AddrPort, interval uint8) (bool, error) {
+	return call(s, controller, "set-listener", func() (bool, error) {
+		return s.IUHPPOTE.SetListener(controller, address, interval)
+	})
+}
+
+func (s *Scheduler) GetTime(controller uint32) (*types.Time, error) {
+	return call(s, controller, "get-time", func() (*types.Time, error) {
+		return s.IUHPPOTE.GetTime(controller)
+	})
+}
+
+func (s *Scheduler) SetTime(controller uint32, datetime time.Time) (*types.Time, error) {
+	return call(s, controller, "set-time", func() (*types.Time, error) {
+		return s.IUHPPOTE.SetTime(controller, datetime)
+	})
+}
+
+func (s *Scheduler) GetDoorControlState(controller uint32, door byte) (*types.DoorControlState, error) {
+	return call(s, controller, "get-door-control-state", func() (*types.DoorControlState, error) {
+		return s.IUHPPOTE.GetDoorControlState(controller, door)
+	})
+}
+
+func (s *Scheduler) SetDoorControlState(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+	return call(s, controller, "set-door-control-state", func() (*types.DoorControlState, error) {
+		return s.IUHPPOTE.SetDoorControlState(controller, door, state, delay)
+	})
+}
+
+func (s *Scheduler) GetStatus(controller uint32) (*types.Status, error) {
+	return call(s, controller, "get-status", func() (*types.Status, error) {
+		return s.IUHPPOTE.GetStatus(controller)
+	})
+}
+
+func (s *Scheduler) GetCards(controller uint32) (uint32, error) {
+	return call(s, controller, "get-cards", func() (uint32, error) {
+		return s.IUHPPOTE.GetCards(controller)
+	})
+}
+
+func (s *Scheduler) GetCardByIndex(controller, index uint32) (*types.Card, error) {
+	return call(s, controller, "get-card-by-index", func() (*types.Card, error) {
+		return s.IUHPPOTE.GetCardByIndex(controller, index)
+	})
+}
+
+func (s *Scheduler) GetCardByID(controller, cardNumber uint32) (*types.Card, error) {
+	return call(s, controller, "get-card-by-id", func() (*types.Card, error) {
+		return s.IUHPPOTE.GetCardByID(controller, cardNumber)
+	})
+}
+
+func (s *Scheduler) PutCard(controller uint32, card types.Card, formats ...types.CardFormat) (bool, error) {
+	return call(s, controller, "put-card", func() (bool, error) {
+		return s.IUHPPOTE.PutCard(controller, card, formats...)
+	})
+}
+
+func (s *Scheduler) DeleteCard(controller uint32, cardNumber uint32) (bool, error) {
+	return call(s, controller, "delete-card", func() (bool, error) {
+		return s.IUHPPOTE.DeleteCard(controller, cardNumber)
+	})
+}
+
+func (s *Scheduler) DeleteCards(controller uint32) (bool, error) {
+	return call(s, controller, "delete-cards", func() (bool, error) {
+		return s.IUHPPOTE.DeleteCards(controller)
+	})
+}
+
+func (s *Scheduler) GetTimeProfile(controller uint32, profileID uint8) (*types.TimeProfile, error) {
+	return call(s, controller, "get-time-profile", func() (*types.TimeProfile, error) {
+		return s.IUHPPOTE.GetTimeProfile(controller, profileID)
+	})
+}
+
+func (s *Scheduler) SetTimeProfile(controller uint32, profile types.TimeProfile) (bool, error) {
+	return call(s, controller, "set-time-profile", func() (bool, error) {
+		return s.IUHPPOTE.SetTimeProfile(controller, profile)
+	})
+}
+
+func (s *Scheduler) ClearTimeProfiles(controller uint32) (bool, error) {
+	return call(s, controller, "clear-time-profiles", func() (bool, error) {
+		return s.IUHPPOTE.ClearTimeProfiles(controller)
+	})
+}
+
+func (s *Scheduler) ClearTaskList(controller uint32) (bool, error) {
+	return call(s, controller, "clear-task-list", func() (bool, error) {
+		return s.IUHPPOTE.ClearTaskList(controller)
+	})
+}
+
+func (s *Scheduler) AddTask(controller uint32, task types.Task) (bool, error) {
+	return call(s, controller, "add-task", func() (bool, error) {
+		return s.IUHPPOTE.AddTask(controller, task)
+	})
+}
+
+func (s *Scheduler) RefreshTaskList(controller uint32) (bool, error) {
+	return call(s, controller, "refresh-task-list", func() (bool, error) {
+		return s.IUHPPOTE.RefreshTaskList(controller)
+	})
+}
+
+func (s *Scheduler) RecordSpecialEvents(controller uint32, enable bool) (bool, error) {
+	return call(s, controller, "record-special-events", func() (bool, error) {
+		return s.IUHPPOTE.RecordSpecialEvents(controller, enable)
+	})
+}
+
+func (s *Scheduler) GetEvent(controller, index uint32) (*types.Event, error) {
+	return call(s, controller, "get-event", func() (*types.Event, error) {
+		return s.IUHPPOTE.GetEvent(controller, index)
+	})
+}
+
+func (s *Scheduler) GetEventIndex(controller uint32) (*types.EventIndex, error) {
+	return call(s, controller, "get-event-index", func() (*types.EventIndex, error) {
+		return s.IUHPPOTE.GetEventIndex(controller)
+	})
+}
+
+func (s *Scheduler) SetEventIndex(controller, index uint32) (*types.EventIndexResult, error) {
+	return call(s, controller, "set-event-index", func() (*types.EventIndexResult, error) {
+		return s.IUHPPOTE.SetEventIndex(controller, index)
+	})
+}
+
+func (s *Scheduler) SetDoorPasscodes(controller uint32, door uint8, passcodes ...uint32) (bool, error) {
+	return call(s, controller, "set-door-passcodes", func() (bool, error) {
+		return s.IUHPPOTE.SetDoorPasscodes(controller, door, passcodes...)
+	})
+}
+
+func (s *Scheduler) OpenDoor(controller uint32, door uint8) (*types.Result, error) {
+	return call(s, controller, "open-door", func() (*types.Result, error) {
+		return s.IUHPPOTE.OpenDoor(controller, door)
+	})
+}
+
+func (s *Scheduler) SetPCControl(controller uint32, enable bool) (bool, error) {
+	return call(s, controller, "set-pc-control", func() (bool, error) {
+		return s.IUHPPOTE.SetPCControl(controller, enable)
+	})
+}
+
+func (s *Scheduler) SetInterlock(controller uint32, interlock types.Interlock) (bool, error) {
+	return call(s, controller, "set-interlock", func() (bool, error) {
+		return s.IUHPPOTE.SetInterlock(controller, interlock)
+	})
+}
+
+func (s *Scheduler) ActivateKeypads(controller uint32, readers map[uint8]bool) (bool, error) {
+	return call(s, controller, "activate-keypads", func() (bool, error) {
+		return s.IUHPPOTE.ActivateKeypads(controller, readers)
+	})
+}
+
+func (s *Scheduler) GetAntiPassback(controller uint32) (types.AntiPassback, error) {
+	return call(s, controller, "get-antipassback", func() (types.AntiPassback, error) {
+		return s.IUHPPOTE.GetAntiPassback(controller)
+	})
+}
+
+func (s *Scheduler) SetAntiPassback(controller uint32, antipassback types.AntiPassback) (bool, error) {
+	return call(s, controller, "set-antipassback", func() (bool, error) {
+		return s.IUHPPOTE.SetAntiPassback(controller, antipassback)
+	})
+}
+
+func (s *Scheduler) SetFirstCard(controller uint32, door uint8, firstcard types.FirstCard) (bool, error) {
+	return call(s, controller, "set-first-card", func() (bool, error) {
+		return s.IUHPPOTE.SetFirstCard(controller, door, firstcard)
+	})
+}
+
+func (s *Scheduler) RestoreDefaultParameters(controller uint32) (bool, error) {
+	return call(s, controller, "restore-default-parameters", func() (bool, error) {
+		return s.IUHPPOTE.RestoreDefaultParameters(controller)
+	})
+}
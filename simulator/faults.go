@@ -0,0 +1,124 @@
+package simulator
+
+import (
+	"fmt"
+	"os"
+)
+
+// FaultType is the failure simulated by a scripted fault.
+type FaultType int
+
+const (
+	// The controller does not respond to the request and the controller state is unchanged.
+	Timeout FaultType = iota + 1
+
+	// The controller responds to a 'set' request but does not apply it, e.g. PutCard returns
+	// false. 'Get' requests are unaffected.
+	Rejected
+
+	// The controller applies the request but the reply is lost, so the caller sees a timeout.
+	Dropped
+)
+
+// ErrTimeout is the error returned for requests that time out (or for which the reply was
+// dropped). It wraps os.ErrDeadlineExceeded so that it is treated the same as a network
+// timeout.
+var ErrTimeout = fmt.Errorf("simulated controller timeout (%w)", os.ErrDeadlineExceeded)
+
+// Fault is a scripted failure for the requests that match the controller and operation.
+type Fault struct {
+	Controller uint32    // controller ID, 0 for any controller
+	Operation  string    // operation e.g. "put-card", "get-status", blank for any operation
+	Type       FaultType // simulated failure
+	Count      int       // number of requests affected, 0 for all requests
+}
+
+// Adds a list of scripted faults. Faults are matched in the order in which they were added
+// and a fault with a Count is removed once it has been applied to Count requests.
+func (s *Simulator) Inject(faults ...Fault) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	for _, f := range faults {
+		s.faults = append(s.faults, &f)
+	}
+}
+
+// Removes all scripted faults.
+func (s *Simulator) ClearFaults() {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	s.faults = []*Fault{}
+}
+
+func (s *Simulator) fault(controller uint32, operation string, write bool) FaultType {
+	for i, f := range s.faults {
+		if f.Controller != 0 && f.Controller != controller {
+			continue
+		}
+
+		if f.Operation != "" && f.Operation != operation {
+			continue
+		}
+
+		if f.Type == Rejected && !write {
+			continue
+		}
+
+		if f.Count > 0 {
+			if f.Count--; f.Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+
+		return f.Type
+	}
+
+	return 0
+}
+
+// Looks up the controller and applies any scripted fault before invoking 'get' with the
+// controller state.
+func (s *Simulator) exec(controller uint32, operation string, get func(c *Controller)) error {
+	return s.execute(controller, operation, false, get, nil)
+}
+
+// As for exec, but for an operation that updates the controller. 'rejected' (if not nil) is
+// invoked instead of 'set' if the request is rejected.
+func (s *Simulator) write(controller uint32, operation string, set func(c *Controller), rejected func(c *Controller)) error {
+	return s.execute(controller, operation, true, set, rejected)
+}
+
+func (s *Simulator) execute(controller uint32, operation string, write bool, f func(c *Controller), rejected func(c *Controller)) error {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	c, ok := s.controllers[controller]
+	if !ok {
+		return timeout(controller, operation)
+	}
+
+	switch s.fault(controller, operation, write) {
+	case Timeout:
+		return timeout(controller, operation)
+
+	case Rejected:
+		if rejected != nil {
+			rejected(c)
+		}
+		return nil
+
+	case Dropped:
+		f(c)
+		return timeout(controller, operation)
+
+	default:
+		f(c)
+		return nil
+	}
+}
+
+func timeout(controller uint32, operation string) error {
+	return fmt.Errorf("%v %v: %w", controller, operation, ErrTimeout)
+}
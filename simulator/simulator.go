@@ -0,0 +1,348 @@
+// Package simulator implements an in-memory 'fleet' of simulated UHPPOTE access controllers
+// that can be used in place of a uhppote.IUHPPOTE for integration tests and demos.
+//
+// The simulated controllers hold cards, time profiles, tasks, events, door states, listener
+// address and system time, and faults (timeouts, rejected writes and dropped replies) can be
+// scripted per controller and operation.
+package simulator
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+// Default size of the controller event buffer. Real controllers store about 100000 events but
+// a smaller buffer makes it practical to test event overwrite handling.
+const EVENT_BUFFER = 1024
+
+// Simulator is an in-memory implementation of uhppote.IUHPPOTE.
+type Simulator struct {
+	controllers map[uint32]*Controller
+	listen      []netip.AddrPort
+	faults      []*Fault
+	listeners   []chan types.Status
+	now         func() time.Time
+	guard       sync.Mutex
+}
+
+// Controller is the configuration and state of a simulated controller. Use Simulator.Update
+// to change the state of a controller once it has been added to a simulator.
+type Controller struct {
+	DeviceID uint32
+	Name     string
+	Address  netip.AddrPort
+	Netmask  netip.Addr
+	Gateway  netip.Addr
+	MAC      types.MacAddress
+	Version  types.Version
+	Released types.Date
+	Doors    []string
+	TimeZone *time.Location
+
+	Unlisted bool          // excluded from DeviceList, e.g. to simulate an 'unexpected' controller
+	Offset   time.Duration // controller system time offset from the simulator clock
+	Listener netip.AddrPort
+	Interval uint8
+
+	DoorOpen      map[uint8]bool
+	DoorButton    map[uint8]bool
+	RelayState    uint8
+	InputState    uint8
+	SystemError   uint8
+	SpecialEvents bool
+	PCControl     bool
+	Interlock     types.Interlock
+	AntiPassback  types.AntiPassback
+	Keypads       map[uint8]bool
+	Passcodes     map[uint8][]uint32
+	FirstCard     map[uint8]types.FirstCard
+	EventBuffer   int
+
+	doors      map[uint8]types.DoorControlState
+	cards      []*types.Card
+	profiles   map[uint8]types.TimeProfile
+	tasks      []types.Task
+	pending    []types.Task
+	events     []types.Event
+	eventIndex uint32
+	sequence   uint32
+}
+
+// Creates a simulator for the list of controllers, with the simulator clock set to the
+// system clock.
+func NewSimulator(controllers ...*Controller) *Simulator {
+	s := Simulator{
+		controllers: map[uint32]*Controller{},
+		listen:      []netip.AddrPort{},
+		faults:      []*Fault{},
+		listeners:   []chan types.Status{},
+		now:         time.Now,
+	}
+
+	for _, c := range controllers {
+		s.Add(c)
+	}
+
+	return &s
+}
+
+// Creates a simulated controller with four doors in 'controlled' mode, an empty card list and
+// an empty event buffer. The controller address is derived from the controller ID.
+func NewController(controller uint32, name string, doors ...string) *Controller {
+	addr := netip.AddrFrom4([4]byte{192, 168, 1, byte(controller % 250)})
+
+	c := Controller{
+		DeviceID: controller,
+		Name:     name,
+		Address:  netip.AddrPortFrom(addr, 60000),
+		Netmask:  netip.AddrFrom4([4]byte{255, 255, 255, 0}),
+		Gateway:  netip.AddrFrom4([4]byte{192, 168, 1, 1}),
+		MAC:      types.MacAddress{0x00, 0x12, 0x23, 0x34, 0x45, 0x56},
+		Version:  0x0892,
+		Released: types.ToDate(2018, time.November, 5),
+		Doors:    doors,
+		TimeZone: time.Local,
+	}
+
+	return &c
+}
+
+// Adds (or replaces) a controller. Unset controller state is initialised to the defaults.
+func (s *Simulator) Add(c *Controller) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	c.initialise()
+
+	s.controllers[c.DeviceID] = c
+}
+
+// Sets the simulator clock, from which the controller system times are derived.
+func (s *Simulator) SetClock(now func() time.Time) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	s.now = now
+}
+
+// Sets the list of addresses returned by ListenAddrList.
+func (s *Simulator) SetListenAddrList(list ...netip.AddrPort) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	s.listen = slices.Clone(list)
+}
+
+// Invokes the function with the controller state, e.g. to open a door or change the
+// controller clock offset. Returns an error if the controller does not exist.
+func (s *Simulator) Update(controller uint32, f func(c *Controller)) error {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if c, ok := s.controllers[controller]; !ok {
+		return fmt.Errorf("unknown controller %v", controller)
+	} else {
+		f(c)
+	}
+
+	return nil
+}
+
+// Appends an event to the controller event buffer, overwriting the oldest event if the buffer
+// is full. The event index is assigned by the controller and the event timestamp defaults to
+// the controller system time. The controller status (including the event) is sent to any
+// listeners if the controller has a valid listener address.
+//
+// Returns the event index.
+func (s *Simulator) AddEvent(controller uint32, event types.Event) (uint32, error) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	c, ok := s.controllers[controller]
+	if !ok {
+		return 0, fmt.Errorf("unknown controller %v", controller)
+	}
+
+	event.SerialNumber = types.SerialNumber(controller)
+	event.Index = 1
+	if N := len(c.events); N > 0 {
+		event.Index = c.events[N-1].Index + 1
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = types.DateTime(c.now(s.now()))
+	}
+
+	c.events = append(c.events, event)
+	if N := len(c.events); N > c.EventBuffer {
+		c.events = slices.Delete(c.events, 0, N-c.EventBuffer)
+	}
+
+	if c.Listener.IsValid() {
+		status := c.status(s.now())
+		for _, l := range s.listeners {
+			select {
+			case l <- status:
+			default:
+			}
+		}
+	}
+
+	return event.Index, nil
+}
+
+// Returns the active (not deleted) cards stored on the controller.
+func (s *Simulator) Cards(controller uint32) []types.Card {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	cards := []types.Card{}
+	if c, ok := s.controllers[controller]; ok {
+		for _, card := range c.cards {
+			if card != nil {
+				cards = append(cards, card.Clone())
+			}
+		}
+	}
+
+	return cards
+}
+
+// Returns the time profiles stored on the controller, ordered by profile ID.
+func (s *Simulator) TimeProfiles(controller uint32) []types.TimeProfile {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	profiles := []types.TimeProfile{}
+	if c, ok := s.controllers[controller]; ok {
+		for _, p := range c.profiles {
+			profiles = append(profiles, p)
+		}
+	}
+
+	slices.SortFunc(profiles, func(p, q types.TimeProfile) int { return int(p.ID) - int(q.ID) })
+
+	return profiles
+}
+
+// Returns the active task list, i.e. the tasks added before the last RefreshTaskList.
+func (s *Simulator) Tasks(controller uint32) []types.Task {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if c, ok := s.controllers[controller]; ok {
+		return slices.Clone(c.tasks)
+	}
+
+	return []types.Task{}
+}
+
+// Returns the events currently held in the controller event buffer.
+func (s *Simulator) Events(controller uint32) []types.Event {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if c, ok := s.controllers[controller]; ok {
+		return slices.Clone(c.events)
+	}
+
+	return []types.Event{}
+}
+
+func (c *Controller) initialise() {
+	if c.TimeZone == nil {
+		c.TimeZone = time.Local
+	}
+
+	if c.EventBuffer <= 0 {
+		c.EventBuffer = EVENT_BUFFER
+	}
+
+	if c.DoorOpen == nil {
+		c.DoorOpen = map[uint8]bool{}
+	}
+
+	if c.DoorButton == nil {
+		c.DoorButton = map[uint8]bool{}
+	}
+
+	if c.Keypads == nil {
+		c.Keypads = map[uint8]bool{}
+	}
+
+	if c.Passcodes == nil {
+		c.Passcodes = map[uint8][]uint32{}
+	}
+
+	if c.FirstCard == nil {
+		c.FirstCard = map[uint8]types.FirstCard{}
+	}
+
+	if c.doors == nil {
+		c.doors = map[uint8]types.DoorControlState{}
+		for door := uint8(1); door <= 4; door++ {
+			c.doors[door] = types.DoorControlState{
+				SerialNumber: types.SerialNumber(c.DeviceID),
+				Door:         door,
+				ControlState: types.Controlled,
+				Delay:        5,
+			}
+		}
+	}
+
+	if c.profiles == nil {
+		c.profiles = map[uint8]types.TimeProfile{}
+	}
+}
+
+func (c *Controller) device() uhppote.Device {
+	addr := types.ControllerAddrFrom(c.Address.Addr(), c.Address.Port())
+
+	return uhppote.NewDevice(c.Name, c.DeviceID, addr, "udp", c.Doors, c.TimeZone)
+}
+
+func (c *Controller) now(t time.Time) time.Time {
+	return t.Add(c.Offset).In(c.TimeZone)
+}
+
+func (c *Controller) status(now time.Time) types.Status {
+	c.sequence++
+
+	status := types.Status{
+		SerialNumber:   types.SerialNumber(c.DeviceID),
+		DoorState:      map[uint8]bool{},
+		DoorButton:     map[uint8]bool{},
+		SystemError:    c.SystemError,
+		SystemDateTime: types.DateTime(c.now(now)),
+		SequenceId:     c.sequence,
+		RelayState:     c.RelayState,
+		InputState:     c.InputState,
+	}
+
+	for door := uint8(1); door <= 4; door++ {
+		status.DoorState[door] = c.DoorOpen[door]
+		status.DoorButton[door] = c.DoorButton[door]
+	}
+
+	if N := len(c.events); N > 0 {
+		e := c.events[N-1]
+		status.Event = types.StatusEvent{
+			Index:      e.Index,
+			Type:       e.Type,
+			Granted:    e.Granted,
+			Door:       e.Door,
+			Direction:  e.Direction,
+			CardNumber: e.CardNumber,
+			Timestamp:  e.Timestamp,
+			Reason:     e.Reason,
+		}
+	}
+
+	return status
+}
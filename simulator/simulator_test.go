@@ -0,0 +1,326 @@
+package simulator
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+
+	"github.com/uhppoted/uhppoted-lib/acl"
+	"github.com/uhppoted/uhppoted-lib/monitoring"
+	"github.com/uhppoted/uhppoted-lib/tracing"
+	"github.com/uhppoted/uhppoted-lib/uhppoted"
+)
+
+type handler struct {
+	alerts []string
+	sync.Mutex
+}
+
+func (h *handler) Alive(m monitoring.Monitor, msg string) error {
+	return nil
+}
+
+func (h *handler) Alert(m monitoring.Monitor, msg string) error {
+	h.Lock()
+	defer h.Unlock()
+
+	h.alerts = append(h.alerts, msg)
+
+	return nil
+}
+
+func card(cardNumber uint32, doors ...uint8) types.Card {
+	card := types.Card{
+		CardNumber: cardNumber,
+		From:       types.MustParseDate("2026-01-01"),
+		To:         types.MustParseDate("2026-12-31"),
+		Doors:      map[uint8]uint8{1: 0, 2: 0, 3: 0, 4: 0},
+	}
+
+	for _, door := range doors {
+		card.Doors[door] = 1
+	}
+
+	return card
+}
+
+func TestPutACL(t *testing.T) {
+	s := NewSimulator(NewController(405419896, "Alpha", "Front", "Back", "Garage", "Workshop"))
+
+	s.PutCard(405419896, card(10058399, 1))
+	s.PutCard(405419896, card(10058400, 1, 2))
+	s.PutCard(405419896, card(10058401, 1))
+
+	ACL := acl.ACL{
+		405419896: map[uint32]types.Card{
+			10058399: card(10058399, 1),
+			10058400: card(10058400, 1, 2, 3),
+			10058402: card(10058402, 4),
+		},
+	}
+
+	report, errs := acl.PutACL(s, ACL, false)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors (%v)", errs)
+	}
+
+	rpt := report[405419896]
+	if !reflect.DeepEqual(rpt.Unchanged, []uint32{10058399}) ||
+		!reflect.DeepEqual(rpt.Updated, []uint32{10058400}) ||
+		!reflect.DeepEqual(rpt.Added, []uint32{10058402}) ||
+		!reflect.DeepEqual(rpt.Deleted, []uint32{10058401}) {
+		t.Errorf("Incorrect report %+v", rpt)
+	}
+
+	cards := []uint32{}
+	for _, c := range s.Cards(405419896) {
+		cards = append(cards, c.CardNumber)
+	}
+
+	slices.Sort(cards)
+
+	if expected := []uint32{10058399, 10058400, 10058402}; !reflect.DeepEqual(cards, expected) {
+		t.Errorf("Incorrect cards - expected:%v, got:%v", expected, cards)
+	}
+
+	if N, _ := s.GetCards(405419896); N != 3 {
+		t.Errorf("Incorrect card count - expected:%v, got:%v", 3, N)
+	}
+}
+
+func TestPutACLWithFaults(t *testing.T) {
+	s := NewSimulator(NewController(405419896, "Alpha", "Front", "Back", "Garage", "Workshop"))
+
+	s.Inject(Fault{Controller: 405419896, Operation: "put-card", Type: Rejected, Count: 1})
+
+	ACL := acl.ACL{
+		405419896: map[uint32]types.Card{
+			10058399: card(10058399, 1),
+		},
+	}
+
+	report, _ := acl.PutACL(s, ACL, false)
+	if rpt := report[405419896]; !reflect.DeepEqual(rpt.Failed, []uint32{10058399}) {
+		t.Errorf("Expected 'failed' card for rejected put-card, got %+v", rpt)
+	}
+
+	if cards := s.Cards(405419896); len(cards) != 0 {
+		t.Errorf("Expected no cards after rejected put-card, got %v", cards)
+	}
+
+	// ... fault is cleared after one request
+	report, _ = acl.PutACL(s, ACL, false)
+	if rpt := report[405419896]; !reflect.DeepEqual(rpt.Added, []uint32{10058399}) {
+		t.Errorf("Expected 'added' card, got %+v", rpt)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	s := NewSimulator(NewController(405419896, "Alpha"))
+
+	s.Inject(Fault{Operation: "set-door-control-state", Type: Dropped, Count: 1})
+	s.Inject(Fault{Controller: 405419896, Operation: "get-status", Type: Timeout})
+
+	if _, err := s.SetDoorControlState(405419896, 3, types.NormallyOpen, 7); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Expected timeout for dropped reply, got %v", err)
+	}
+
+	if state, err := s.GetDoorControlState(405419896, 3); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if state.ControlState != types.NormallyOpen || state.Delay != 7 {
+		t.Errorf("Expected door control state to be updated for dropped reply, got %v", state)
+	}
+
+	for range 3 {
+		if _, err := s.GetStatus(405419896); !errors.Is(err, ErrTimeout) {
+			t.Errorf("Expected timeout, got %v", err)
+		}
+	}
+
+	s.ClearFaults()
+
+	if _, err := s.GetStatus(405419896); err != nil {
+		t.Errorf("Unexpected error after clearing faults (%v)", err)
+	}
+
+	if _, err := s.GetStatus(303986753); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected timeout for unknown controller, got %v", err)
+	}
+}
+
+func TestGetEventsWithOverwrite(t *testing.T) {
+	c := NewController(405419896, "Alpha")
+	c.EventBuffer = 5
+
+	s := NewSimulator(c)
+	u := uhppoted.UHPPOTED{
+		UHPPOTE: s,
+	}
+
+	for i := range 8 {
+		s.AddEvent(405419896, types.Event{Type: 1, Granted: true, Door: 1, CardNumber: 10058400 + uint32(i), Reason: 1})
+	}
+
+	first, last, current, err := u.GetEventIndices(405419896)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if first != 4 || last != 8 || current != 0 {
+		t.Errorf("Incorrect event indices - expected:%v,%v,%v, got:%v,%v,%v", 4, 8, 0, first, last, current)
+	}
+
+	events, err := u.GetEvents(405419896, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	indices := []uint32{}
+	for _, e := range events {
+		indices = append(indices, e.Index)
+	}
+
+	if expected := []uint32{4, 5, 6}; !reflect.DeepEqual(indices, expected) {
+		t.Errorf("Incorrect events - expected:%v, got:%v", expected, indices)
+	}
+
+	if index, _ := s.GetEventIndex(405419896); index.Index != 6 {
+		t.Errorf("Incorrect event index - expected:%v, got:%v", 6, index.Index)
+	}
+
+	if _, err := s.GetEvent(405419896, 2); err == nil || !strings.Contains(err.Error(), "overwritten") {
+		t.Errorf("Expected 'overwritten' error for event 2, got %v", err)
+	}
+
+	if event, err := s.GetEvent(405419896, 9); err != nil || event != nil {
+		t.Errorf("Expected no event for index 9, got %v, %v", event, err)
+	}
+
+	if status, _ := s.GetStatus(405419896); status.Event.Index != 8 {
+		t.Errorf("Incorrect status event - expected:%v, got:%v", 8, status.Event.Index)
+	}
+}
+
+func TestTracing(t *testing.T) {
+	s := NewSimulator(NewController(405419896, "Alpha"), NewController(303986753, "Beta"))
+	r := tracing.NewRecorder()
+	u := uhppoted.UHPPOTED{
+		UHPPOTE: s,
+		Tracer:  r,
+	}
+
+	acl.SetTracer(r)
+	defer acl.SetTracer(nil)
+
+	for i := range 3 {
+		s.AddEvent(405419896, types.Event{Type: 1, Granted: true, Door: 1, CardNumber: 10058400 + uint32(i), Reason: 1})
+	}
+
+	if _, err := u.GetEvents(405419896, 2); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if _, errs := acl.PutACL(s, acl.ACL{405419896: {10058400: card(10058400, 1)}, 303986753: {}}, false); len(errs) > 0 {
+		t.Fatalf("Unexpected errors (%v)", errs)
+	}
+
+	// ... format spans as 'parent > child' paths
+	spans := map[uint64]tracing.Record{}
+	for _, s := range r.Spans() {
+		spans[s.ID] = s
+	}
+
+	paths := []string{}
+	for _, s := range r.Spans() {
+		path := s.Name
+		for p := s.Parent; p != 0; p = spans[p].Parent {
+			path = spans[p].Name + " > " + path
+		}
+
+		paths = append(paths, path)
+	}
+
+	slices.Sort(paths)
+
+	expected := []string{
+		"get-events",
+		"get-events > get-event",
+		"get-events > get-event",
+		"get-events > get-event",
+		"get-events > get-event-index",
+		"get-events > set-event-index",
+		"put-acl",
+		"put-acl > put-controller-acl",
+		"put-acl > put-controller-acl",
+		"put-acl > put-controller-acl > get-card-by-id",
+		"put-acl > put-controller-acl > get-cards",
+		"put-acl > put-controller-acl > get-cards",
+		"put-acl > put-controller-acl > put-card",
+	}
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Incorrect spans\n   expected:%q\n   got:     %q", expected, paths)
+	}
+}
+
+func TestTime(t *testing.T) {
+	tz, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone not available (%v)", err)
+	}
+
+	now := time.Date(2026, time.March, 10, 12, 30, 0, 0, time.UTC)
+
+	c := NewController(405419896, "Alpha")
+	c.TimeZone = tz
+
+	s := NewSimulator(c)
+	s.SetClock(func() time.Time { return now })
+
+	if v, err := s.SetTime(405419896, time.Date(2026, time.March, 10, 21, 35, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if expected := "2026-03-10 21:35:00"; v.DateTime.String() != expected {
+		t.Errorf("Incorrect controller time - expected:%v, got:%v", expected, v.DateTime)
+	}
+
+	s.Update(405419896, func(c *Controller) {
+		if c.Offset != 5*time.Minute {
+			t.Errorf("Incorrect controller clock offset - expected:%v, got:%v", 5*time.Minute, c.Offset)
+		}
+	})
+}
+
+func TestHealthCheck(t *testing.T) {
+	alpha := NewController(405419896, "Alpha")
+	beta := NewController(303986753, "Beta")
+	gamma := NewController(201020304, "Gamma")
+
+	beta.Offset = 15 * time.Minute
+	gamma.Unlisted = true
+
+	s := NewSimulator(alpha, beta, gamma)
+	h := handler{}
+
+	healthcheck := monitoring.NewHealthCheck(s, 15*time.Second, 60*time.Second, 300*time.Second)
+	healthcheck.Exec(&h)
+
+	expected := map[string]string{
+		"303986753": "system time not synchronized",
+		"201020304": "unexpected device",
+	}
+
+	for id, msg := range expected {
+		if !slices.ContainsFunc(h.alerts, func(alert string) bool { return strings.Contains(alert, id) && strings.Contains(alert, msg) }) {
+			t.Errorf("Missing alert '%v %v' (%v)", id, msg, h.alerts)
+		}
+	}
+
+	if slices.ContainsFunc(h.alerts, func(alert string) bool { return strings.Contains(alert, "405419896") }) {
+		t.Errorf("Unexpected alert for 405419896 (%v)", h.alerts)
+	}
+}
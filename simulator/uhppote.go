@@ -0,0 +1,647 @@
+package simulator
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+var _ uhppote.IUHPPOTE = (*Simulator)(nil)
+
+// Returns the (sorted) list of all simulated controllers, including 'unlisted' controllers.
+func (s *Simulator) GetDevices() ([]types.Device, error) {
+	s.guard.Lock()
+	ids := slices.Sorted(maps.Keys(s.controllers))
+	s.guard.Unlock()
+
+	devices := []types.Device{}
+	for _, id := range ids {
+		if device, err := s.GetDevice(id); err == nil && device != nil {
+			devices = append(devices, *device)
+		}
+	}
+
+	return devices, nil
+}
+
+func (s *Simulator) GetDevice(controller uint32) (*types.Device, error) {
+	var device *types.Device
+
+	err := s.exec(controller, "get-device", func(c *Controller) {
+		device = &types.Device{
+			Name:         c.Name,
+			SerialNumber: types.SerialNumber(c.DeviceID),
+			IpAddress:    net.IP(c.Address.Addr().AsSlice()),
+			SubnetMask:   net.IP(c.Netmask.AsSlice()),
+			Gateway:      net.IP(c.Gateway.AsSlice()),
+			MacAddress:   slices.Clone(c.MAC),
+			Version:      c.Version,
+			Date:         c.Released,
+			Address:      c.Address,
+			TimeZone:     c.TimeZone,
+		}
+	})
+
+	return device, err
+}
+
+func (s *Simulator) SetAddress(controller uint32, address, mask, gateway net.IP) (*types.Result, error) {
+	result := types.Result{
+		SerialNumber: types.SerialNumber(controller),
+	}
+
+	addr, ok1 := netip.AddrFromSlice(address.To4())
+	netmask, ok2 := netip.AddrFromSlice(mask.To4())
+	gw, ok3 := netip.AddrFromSlice(gateway.To4())
+	if !ok1 || !ok2 || !ok3 {
+		return nil, fmt.Errorf("invalid address (%v %v %v)", address, mask, gateway)
+	}
+
+	err := s.write(controller, "set-address", func(c *Controller) {
+		c.Address = netip.AddrPortFrom(addr, c.Address.Port())
+		c.Netmask = netmask
+		c.Gateway = gw
+		result.Succeeded = true
+	}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (s *Simulator) GetListener(controller uint32) (netip.AddrPort, uint8, error) {
+	var address netip.AddrPort
+	var interval uint8
+
+	err := s.exec(controller, "get-listener", func(c *Controller) {
+		address = c.Listener
+		interval = c.Interval
+	})
+
+	return address, interval, err
+}
+
+func (s *Simulator) SetListener(controller uint32, address netip.AddrPort, interval uint8) (bool, error) {
+	if address != netip.AddrPortFrom(netip.IPv4Unspecified(), 0) && (!address.Addr().Is4() || address.Port() == 0) {
+		return false, uhppote.ErrInvalidListenerAddress
+	}
+
+	ok := false
+	err := s.write(controller, "set-listener", func(c *Controller) {
+		c.Listener = address
+		c.Interval = interval
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) GetTime(controller uint32) (*types.Time, error) {
+	var t *types.Time
+
+	err := s.exec(controller, "get-time", func(c *Controller) {
+		t = &types.Time{
+			SerialNumber: types.SerialNumber(controller),
+			DateTime:     types.DateTime(c.now(s.now())),
+		}
+	})
+
+	return t, err
+}
+
+// Sets the controller system time, which is the 'wall clock' time in the controller time zone
+// i.e. the time zone of the datetime is ignored (as for a real controller).
+func (s *Simulator) SetTime(controller uint32, datetime time.Time) (*types.Time, error) {
+	var t *types.Time
+
+	update := func(c *Controller) {
+		t = &types.Time{
+			SerialNumber: types.SerialNumber(controller),
+			DateTime:     types.DateTime(c.now(s.now())),
+		}
+	}
+
+	err := s.write(controller, "set-time", func(c *Controller) {
+		wallclock := time.Date(datetime.Year(), datetime.Month(), datetime.Day(), datetime.Hour(), datetime.Minute(), datetime.Second(), 0, c.TimeZone)
+
+		c.Offset = wallclock.Sub(s.now())
+		update(c)
+	}, update)
+
+	return t, err
+}
+
+func (s *Simulator) GetDoorControlState(controller uint32, door byte) (*types.DoorControlState, error) {
+	if door < 1 || door > 4 {
+		return nil, fmt.Errorf("invalid door (%v)", door)
+	}
+
+	var state *types.DoorControlState
+
+	err := s.exec(controller, "get-door-control-state", func(c *Controller) {
+		v := c.doors[door]
+		state = &v
+	})
+
+	return state, err
+}
+
+func (s *Simulator) SetDoorControlState(controller uint32, door uint8, mode types.ControlState, delay uint8) (*types.DoorControlState, error) {
+	if door < 1 || door > 4 {
+		return nil, fmt.Errorf("invalid door (%v)", door)
+	}
+
+	if mode < types.NormallyOpen || mode > types.FirstCardOnly {
+		return nil, fmt.Errorf("invalid door control state (%v)", mode)
+	}
+
+	var state *types.DoorControlState
+
+	get := func(c *Controller) {
+		v := c.doors[door]
+		state = &v
+	}
+
+	err := s.write(controller, "set-door-control-state", func(c *Controller) {
+		c.doors[door] = types.DoorControlState{
+			SerialNumber: types.SerialNumber(controller),
+			Door:         door,
+			ControlState: mode,
+			Delay:        delay,
+		}
+
+		get(c)
+	}, get)
+
+	return state, err
+}
+
+func (s *Simulator) GetStatus(controller uint32) (*types.Status, error) {
+	var status *types.Status
+
+	err := s.exec(controller, "get-status", func(c *Controller) {
+		v := c.status(s.now())
+		status = &v
+	})
+
+	return status, err
+}
+
+// Returns the number of active (not deleted) cards.
+func (s *Simulator) GetCards(controller uint32) (uint32, error) {
+	var N uint32
+
+	err := s.exec(controller, "get-cards", func(c *Controller) {
+		for _, card := range c.cards {
+			if card != nil {
+				N++
+			}
+		}
+	})
+
+	return N, err
+}
+
+// Returns the card stored at the (1-based) index or nil if the index is out of range or
+// the card was deleted.
+func (s *Simulator) GetCardByIndex(controller, index uint32) (*types.Card, error) {
+	var card *types.Card
+
+	err := s.exec(controller, "get-card-by-index", func(c *Controller) {
+		if index > 0 && int(index) <= len(c.cards) && c.cards[index-1] != nil {
+			v := c.cards[index-1].Clone()
+			card = &v
+		}
+	})
+
+	return card, err
+}
+
+func (s *Simulator) GetCardByID(controller, cardNumber uint32) (*types.Card, error) {
+	var card *types.Card
+
+	err := s.exec(controller, "get-card-by-id", func(c *Controller) {
+		if ix := c.find(cardNumber); ix >= 0 {
+			v := c.cards[ix].Clone()
+			card = &v
+		}
+	})
+
+	return card, err
+}
+
+// Adds or updates a card. The card formats are ignored.
+func (s *Simulator) PutCard(controller uint32, card types.Card, formats ...types.CardFormat) (bool, error) {
+	if card.CardNumber == 0 || card.CardNumber == 0xffffffff {
+		return false, fmt.Errorf("%w (%v)", uhppote.ErrInvalidCard, card.CardNumber)
+	}
+
+	ok := false
+	err := s.write(controller, "put-card", func(c *Controller) {
+		v := card.Clone()
+		if ix := c.find(card.CardNumber); ix >= 0 {
+			c.cards[ix] = &v
+		} else {
+			c.cards = append(c.cards, &v)
+		}
+
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+// Deletes a card, leaving a 'deleted' record at the card index (as for a real controller).
+func (s *Simulator) DeleteCard(controller uint32, cardNumber uint32) (bool, error) {
+	ok := false
+	err := s.write(controller, "delete-card", func(c *Controller) {
+		if ix := c.find(cardNumber); ix >= 0 {
+			c.cards[ix] = nil
+			ok = true
+		}
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) DeleteCards(controller uint32) (bool, error) {
+	ok := false
+	err := s.write(controller, "delete-cards", func(c *Controller) {
+		c.cards = []*types.Card{}
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) GetTimeProfile(controller uint32, profileID uint8) (*types.TimeProfile, error) {
+	var profile *types.TimeProfile
+
+	err := s.exec(controller, "get-time-profile", func(c *Controller) {
+		if v, ok := c.profiles[profileID]; ok {
+			profile = &v
+		}
+	})
+
+	return profile, err
+}
+
+func (s *Simulator) SetTimeProfile(controller uint32, profile types.TimeProfile) (bool, error) {
+	if profile.ID < 2 || profile.ID > 254 {
+		return false, fmt.Errorf("invalid time profile ID (%v)", profile.ID)
+	}
+
+	ok := false
+	err := s.write(controller, "set-time-profile", func(c *Controller) {
+		c.profiles[profile.ID] = profile
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) ClearTimeProfiles(controller uint32) (bool, error) {
+	ok := false
+	err := s.write(controller, "clear-time-profiles", func(c *Controller) {
+		c.profiles = map[uint8]types.TimeProfile{}
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) ClearTaskList(controller uint32) (bool, error) {
+	ok := false
+	err := s.write(controller, "clear-task-list", func(c *Controller) {
+		c.tasks = []types.Task{}
+		c.pending = []types.Task{}
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+// Adds a task to the pending task list. The task is only 'active' after RefreshTaskList.
+func (s *Simulator) AddTask(controller uint32, task types.Task) (bool, error) {
+	ok := false
+	err := s.write(controller, "add-task", func(c *Controller) {
+		c.pending = append(c.pending, task)
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) RefreshTaskList(controller uint32) (bool, error) {
+	ok := false
+	err := s.write(controller, "refresh-task-list", func(c *Controller) {
+		c.tasks = append(c.tasks, c.pending...)
+		c.pending = []types.Task{}
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) RecordSpecialEvents(controller uint32, enable bool) (bool, error) {
+	ok := false
+	err := s.write(controller, "record-special-events", func(c *Controller) {
+		c.SpecialEvents = enable
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+// Returns the event at the index, with 0 returning the first (oldest) event and 0xffffffff
+// the last (most recent) event. Returns nil if there is no event at the index and an error
+// if the event has been overwritten.
+func (s *Simulator) GetEvent(controller, index uint32) (*types.Event, error) {
+	var event *types.Event
+	var overwritten bool
+
+	err := s.exec(controller, "get-event", func(c *Controller) {
+		N := len(c.events)
+		if N == 0 {
+			return
+		}
+
+		first := c.events[0].Index
+		last := c.events[N-1].Index
+
+		switch {
+		case index == 0:
+			event = &c.events[0]
+
+		case index == 0xffffffff:
+			event = &c.events[N-1]
+
+		case index < first:
+			overwritten = true
+
+		case index <= last:
+			event = &c.events[index-first]
+		}
+
+		if event != nil {
+			v := *event
+			event = &v
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	} else if overwritten {
+		return nil, fmt.Errorf("event at index %v has been overwritten", index)
+	}
+
+	return event, nil
+}
+
+func (s *Simulator) GetEventIndex(controller uint32) (*types.EventIndex, error) {
+	var index *types.EventIndex
+
+	err := s.exec(controller, "get-event-index", func(c *Controller) {
+		index = &types.EventIndex{
+			SerialNumber: types.SerialNumber(controller),
+			Index:        c.eventIndex,
+		}
+	})
+
+	return index, err
+}
+
+func (s *Simulator) SetEventIndex(controller, index uint32) (*types.EventIndexResult, error) {
+	var result *types.EventIndexResult
+
+	err := s.write(controller, "set-event-index", func(c *Controller) {
+		result = &types.EventIndexResult{
+			SerialNumber: types.SerialNumber(controller),
+			Index:        index,
+			Changed:      index != c.eventIndex,
+		}
+
+		c.eventIndex = index
+	}, func(c *Controller) {
+		result = &types.EventIndexResult{
+			SerialNumber: types.SerialNumber(controller),
+			Index:        c.eventIndex,
+			Changed:      false,
+		}
+	})
+
+	return result, err
+}
+
+// Invokes the listener with the controller status for each event added with AddEvent (for
+// controllers with a valid listener address) until a signal is received on q.
+func (s *Simulator) Listen(listener uhppote.Listener, q chan os.Signal) error {
+	ch := make(chan types.Status, 16)
+
+	s.guard.Lock()
+	s.listeners = append(s.listeners, ch)
+	s.guard.Unlock()
+
+	defer func() {
+		s.guard.Lock()
+		s.listeners = slices.DeleteFunc(s.listeners, func(l chan types.Status) bool { return l == ch })
+		s.guard.Unlock()
+	}()
+
+	listener.OnConnected()
+
+	for {
+		select {
+		case <-q:
+			return nil
+
+		case status := <-ch:
+			listener.OnEvent(&status)
+		}
+	}
+}
+
+// Sets up to four passcodes for a door. Codes outside the range [1..999999] are set to 0 and
+// the list is padded with 0 to four codes.
+func (s *Simulator) SetDoorPasscodes(controller uint32, door uint8, passcodes ...uint32) (bool, error) {
+	if door < 1 || door > 4 {
+		return false, fmt.Errorf("invalid door (%v)", door)
+	}
+
+	codes := []uint32{0, 0, 0, 0}
+	for i, code := range passcodes[:min(len(passcodes), 4)] {
+		if code >= 1 && code <= 999999 {
+			codes[i] = code
+		}
+	}
+
+	ok := false
+	err := s.write(controller, "set-door-passcodes", func(c *Controller) {
+		c.Passcodes[door] = codes
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) OpenDoor(controller uint32, door uint8) (*types.Result, error) {
+	if door < 1 || door > 4 {
+		return nil, fmt.Errorf("invalid door (%v)", door)
+	}
+
+	result := types.Result{
+		SerialNumber: types.SerialNumber(controller),
+	}
+
+	err := s.write(controller, "open-door", func(c *Controller) {
+		result.Succeeded = true
+	}, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (s *Simulator) SetPCControl(controller uint32, enable bool) (bool, error) {
+	ok := false
+	err := s.write(controller, "set-pc-control", func(c *Controller) {
+		c.PCControl = enable
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) SetInterlock(controller uint32, interlock types.Interlock) (bool, error) {
+	valid := []types.Interlock{
+		types.NoInterlock,
+		types.Interlock12,
+		types.Interlock34,
+		types.Interlock12_34,
+		types.Interlock123,
+		types.Interlock1234,
+	}
+
+	if !slices.Contains(valid, interlock) {
+		return false, fmt.Errorf("invalid interlock (%v)", interlock)
+	}
+
+	ok := false
+	err := s.write(controller, "set-interlock", func(c *Controller) {
+		c.Interlock = interlock
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) ActivateKeypads(controller uint32, readers map[uint8]bool) (bool, error) {
+	ok := false
+	err := s.write(controller, "activate-keypads", func(c *Controller) {
+		for reader := uint8(1); reader <= 4; reader++ {
+			c.Keypads[reader] = readers[reader]
+		}
+
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) GetAntiPassback(controller uint32) (types.AntiPassback, error) {
+	var antipassback types.AntiPassback
+
+	err := s.exec(controller, "get-antipassback", func(c *Controller) {
+		antipassback = c.AntiPassback
+	})
+
+	return antipassback, err
+}
+
+func (s *Simulator) SetAntiPassback(controller uint32, antipassback types.AntiPassback) (bool, error) {
+	if antipassback > types.Readers1_234 {
+		return false, fmt.Errorf("invalid anti-passback (%v)", antipassback)
+	}
+
+	ok := false
+	err := s.write(controller, "set-antipassback", func(c *Controller) {
+		c.AntiPassback = antipassback
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+func (s *Simulator) SetFirstCard(controller uint32, door uint8, firstcard types.FirstCard) (bool, error) {
+	if door < 1 || door > 4 {
+		return false, fmt.Errorf("invalid door (%v)", door)
+	}
+
+	ok := false
+	err := s.write(controller, "set-first-card", func(c *Controller) {
+		c.FirstCard[door] = firstcard
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+// Resets the controller door, interlock, anti-passback, keypad, passcode, first card, special
+// events and PC control settings. Cards, time profiles, tasks and events are unchanged.
+func (s *Simulator) RestoreDefaultParameters(controller uint32) (bool, error) {
+	ok := false
+	err := s.write(controller, "restore-default-parameters", func(c *Controller) {
+		c.doors = nil
+		c.Keypads = nil
+		c.Passcodes = nil
+		c.FirstCard = nil
+		c.Interlock = types.NoInterlock
+		c.AntiPassback = types.Disabled
+		c.SpecialEvents = false
+		c.PCControl = false
+		c.initialise()
+
+		ok = true
+	}, nil)
+
+	return ok, err
+}
+
+// Returns the controllers that are not 'unlisted', as configured devices.
+func (s *Simulator) DeviceList() map[uint32]uhppote.Device {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	devices := map[uint32]uhppote.Device{}
+	for id, c := range s.controllers {
+		if !c.Unlisted {
+			devices[id] = c.device()
+		}
+	}
+
+	return devices
+}
+
+func (s *Simulator) ListenAddrList() []netip.AddrPort {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	return slices.Clone(s.listen)
+}
+
+func (c *Controller) find(cardNumber uint32) int {
+	return slices.IndexFunc(c.cards, func(card *types.Card) bool {
+		return card != nil && card.CardNumber == cardNumber
+	})
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+)
+
+// Recorder is an in-memory Tracer that records the completed spans e.g. for tests or for
+// logging the steps of a slow operation.
+type Recorder struct {
+	spans []Record
+	next  uint64
+	now   func() time.Time
+	guard sync.Mutex
+}
+
+// Record is a completed span. Top-level spans have a Parent ID of 0.
+type Record struct {
+	ID         uint64
+	Parent     uint64
+	Name       string
+	Attributes []Attribute
+	Start      time.Time
+	End        time.Time
+	Error      error
+}
+
+type recording struct {
+	recorder *Recorder
+	record   Record
+	ended    bool
+	guard    sync.Mutex
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		spans: []Record{},
+		now:   time.Now,
+	}
+}
+
+func (r *Recorder) Start(name string, attributes ...Attribute) Span {
+	return r.start(0, name, attributes)
+}
+
+// Returns the completed spans, in the order in which they ended.
+func (r *Recorder) Spans() []Record {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+
+	return append([]Record{}, r.spans...)
+}
+
+func (r *Recorder) start(parent uint64, name string, attributes []Attribute) Span {
+	r.guard.Lock()
+	defer r.guard.Unlock()
+
+	r.next++
+
+	return &recording{
+		recorder: r,
+		record: Record{
+			ID:         r.next,
+			Parent:     parent,
+			Name:       name,
+			Attributes: append([]Attribute{}, attributes...),
+			Start:      r.now(),
+		},
+	}
+}
+
+func (s *recording) Start(name string, attributes ...Attribute) Span {
+	return s.recorder.start(s.record.ID, name, attributes)
+}
+
+func (s *recording) SetAttributes(attributes ...Attribute) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	s.record.Attributes = append(s.record.Attributes, attributes...)
+}
+
+func (s *recording) End(err error) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if !s.ended {
+		s.ended = true
+
+		r := s.recorder
+		r.guard.Lock()
+		defer r.guard.Unlock()
+
+		s.record.End = r.now()
+		s.record.Error = err
+		r.spans = append(r.spans, s.record)
+	}
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/uhppoted/uhppoted-lib/simulator"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+
+	span := r.Start("put-acl", Attr("controllers", 1))
+	child := span.Start("put-controller-acl", Attr("controller", 405419896))
+	child.SetAttributes(Attr("cards", 3))
+	child.End(errors.New("qwerty"))
+	child.End(nil)
+	span.End(nil)
+
+	expected := []Record{
+		{ID: 2, Parent: 1, Name: "put-controller-acl", Attributes: []Attribute{Attr("controller", 405419896), Attr("cards", 3)}},
+		{ID: 1, Parent: 0, Name: "put-acl", Attributes: []Attribute{Attr("controllers", 1)}},
+	}
+
+	spans := r.Spans()
+	if len(spans) != len(expected) {
+		t.Fatalf("Incorrect spans - expected:%v, got:%v", len(expected), len(spans))
+	}
+
+	for i := range expected {
+		spans[i].Start = expected[i].Start
+		spans[i].End = expected[i].End
+		spans[i].Error = nil
+		if !reflect.DeepEqual(spans[i], expected[i]) {
+			t.Errorf("Incorrect span %v\n   expected:%+v\n   got:     %+v", i, expected[i], spans[i])
+		}
+	}
+
+	if err := r.Spans()[0].Error; err == nil || err.Error() != "qwerty" {
+		t.Errorf("Incorrect span error - expected:%v, got:%v", "qwerty", err)
+	}
+}
+
+func TestUHPPOTE(t *testing.T) {
+	s := simulator.NewSimulator(simulator.NewController(405419896, "Alpha"))
+	s.Inject(simulator.Fault{Operation: "get-time", Type: simulator.Timeout, Count: 1})
+
+	r := NewRecorder()
+	span := r.Start("test")
+	u := NewUHPPOTE(s, span)
+
+	u.GetTime(405419896)
+	u.GetStatus(405419896)
+	span.End(nil)
+
+	spans := r.Spans()
+	names := []string{}
+	for _, s := range spans {
+		names = append(names, s.Name)
+	}
+
+	if expected := []string{"get-time", "get-status", "test"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Incorrect spans - expected:%v, got:%v", expected, names)
+	}
+
+	if spans[0].Parent != spans[2].ID || spans[1].Parent != spans[2].ID {
+		t.Errorf("Incorrect parent spans %+v", spans)
+	}
+
+	if !errors.Is(spans[0].Error, simulator.ErrTimeout) || spans[1].Error != nil {
+		t.Errorf("Incorrect span errors - expected:%v,%v, got:%v,%v", simulator.ErrTimeout, nil, spans[0].Error, spans[1].Error)
+	}
+
+	if expected := []Attribute{Attr("controller", uint32(405419896))}; !reflect.DeepEqual(spans[1].Attributes, expected) {
+		t.Errorf("Incorrect span attributes - expected:%v, got:%v", expected, spans[1].Attributes)
+	}
+}
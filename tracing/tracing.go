@@ -0,0 +1,96 @@
+// Package tracing defines the minimal tracer interface used by the uhppoted and acl packages to
+// report spans for multi-step operations (e.g. PutTimeProfiles, GetEvents and PutACL), with a
+// child span for each controller call. The interface is intended to be adapted to OpenTelemetry
+// (or some other tracing system) by the application - the package itself only provides a no-op
+// tracer and an in-memory Recorder for tests and diagnostics.
+package tracing
+
+import (
+	"fmt"
+
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+// Tracer starts the top-level span for an operation.
+type Tracer interface {
+	Start(name string, attributes ...Attribute) Span
+}
+
+// Span is a traced operation. Child spans are started from the parent span and a span
+// must be ended exactly once, with the error (if any) returned by the operation.
+type Span interface {
+	Start(name string, attributes ...Attribute) Span
+	SetAttributes(attributes ...Attribute)
+	End(err error)
+}
+
+// Attribute is a key/value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// UHPPOTE wraps a uhppote.IUHPPOTE to start a child span of the parent span for each
+// controller call. Broadcast and listen operations are passed through as is.
+type UHPPOTE struct {
+	uhppote.IUHPPOTE
+	parent Span
+}
+
+// NopTracer is a Tracer that discards all spans.
+type NopTracer struct{}
+
+type nop struct{}
+
+func Attr(key string, value any) Attribute {
+	return Attribute{
+		Key:   key,
+		Value: value,
+	}
+}
+
+func (a Attribute) String() string {
+	return fmt.Sprintf("%v=%v", a.Key, a.Value)
+}
+
+// Returns the tracer or a NopTracer if the tracer is nil.
+func OrNop(tracer Tracer) Tracer {
+	if tracer == nil {
+		return NopTracer{}
+	}
+
+	return tracer
+}
+
+// Wraps the IUHPPOTE so that each controller call is traced as a child span of the parent
+// span.
+func NewUHPPOTE(u uhppote.IUHPPOTE, parent Span) *UHPPOTE {
+	return &UHPPOTE{
+		IUHPPOTE: u,
+		parent:   parent,
+	}
+}
+
+func call[T any](t *UHPPOTE, controller uint32, operation string, f func() (T, error)) (T, error) {
+	span := t.parent.Start(operation, Attr("controller", controller))
+
+	v, err := f()
+
+	span.End(err)
+
+	return v, err
+}
+
+func (t NopTracer) Start(name string, attributes ...Attribute) Span {
+	return nop{}
+}
+
+func (s nop) Start(name string, attributes ...Attribute) Span {
+	return s
+}
+
+func (s nop) SetAttributes(attributes ...Attribute) {
+}
+
+func (s nop) End(err error) {
+}
@@ -0,0 +1,231 @@
+package tracing
+
+import (
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+var _ uhppote.IUHPPOTE = (*UHPPOTE)(nil)
+
+func (t *UHPPOTE) GetDevices() ([]types.Device, error) {
+	return call(t, 0, "get-devices", func() ([]types.Device, error) {
+		return t.IUHPPOTE.GetDevices()
+	})
+}
+
+func (t *UHPPOTE) GetDevice(controller uint32) (*types.Device, error) {
+	return call(t, controller, "get-device", func() (*types.Device, error) {
+		return t.IUHPPOTE.GetDevice(controller)
+	})
+}
+
+func (t *UHPPOTE) SetAddress(controller uint32, address, mask, gateway net.IP) (*types.Result, error) {
+	return call(t, controller, "set-address", func() (*types.Result, error) {
+		return t.IUHPPOTE.SetAddress(controller, address, mask, gateway)
+	})
+}
+
+func (t *UHPPOTE) GetListener(controller uint32) (netip.AddrPort, uint8, error) {
+	type listener struct {
+		address  netip.AddrPort
+		interval uint8
+	}
+
+	v, err := call(t, controller, "get-listener", func() (listener, error) {
+		address, interval, err := t.IUHPPOTE.GetListener(controller)
+
+		return listener{address, interval}, err
+	})
+
+	return v.address, v.interval, err
+}
+
+func (t *UHPPOTE) SetListener(controller uint32, address netip.AddrPort, interval uint8) (bool, error) {
+	return call(t, controller, "set-listener", func() (bool, error) {
+		return t.IUHPPOTE.SetListener(controller, address, interval)
+	})
+}
+
+func (t *UHPPOTE) GetTime(controller uint32) (*types.Time, error) {
+	return call(t, controller, "get-time", func() (*types.Time, error) {
+		return t.IUHPPOTE.GetTime(controller)
+	})
+}
+
+func (t *UHPPOTE) SetTime(controller uint32, datetime time.Time) (*types.Time, error) {
+	return call(t, controller, "set-time", func() (*types.Time, error) {
+		return t.IUHPPOTE.SetTime(controller, datetime)
+	})
+}
+
+func (t *UHPPOTE) GetDoorControlState(controller uint32, door byte) (*types.DoorControlState, error) {
+	return call(t, controller, "get-door-control-state", func() (*types.DoorControlState, error) {
+		return t.IUHPPOTE.GetDoorControlState(controller, door)
+	})
+}
+
+func (t *UHPPOTE) SetDoorControlState(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+	return call(t, controller, "set-door-control-state", func() (*types.DoorControlState, error) {
+		return t.IUHPPOTE.SetDoorControlState(controller, door, state, delay)
+	})
+}
+
+func (t *UHPPOTE) GetStatus(controller uint32) (*types.Status, error) {
+	return call(t, controller, "get-status", func() (*types.Status, error) {
+		return t.IUHPPOTE.GetStatus(controller)
+	})
+}
+
+func (t *UHPPOTE) GetCards(controller uint32) (uint32, error) {
+	return call(t, controller, "get-cards", func() (uint32, error) {
+		return t.IUHPPOTE.GetCards(controller)
+	})
+}
+
+func (t *UHPPOTE) GetCardByIndex(controller, index uint32) (*types.Card, error) {
+	return call(t, controller, "get-card-by-index", func() (*types.Card, error) {
+		return t.IUHPPOTE.GetCardByIndex(controller, index)
+	})
+}
+
+func (t *UHPPOTE) GetCardByID(controller, cardNumber uint32) (*types.Card, error) {
+	return call(t, controller, "get-card-by-id", func() (*types.Card, error) {
+		return t.IUHPPOTE.GetCardByID(controller, cardNumber)
+	})
+}
+
+func (t *UHPPOTE) PutCard(controller uint32, card types.Card, formats ...types.CardFormat) (bool, error) {
+	return call(t, controller, "put-card", func() (bool, error) {
+		return t.IUHPPOTE.PutCard(controller, card, formats...)
+	})
+}
+
+func (t *UHPPOTE) DeleteCard(controller uint32, cardNumber uint32) (bool, error) {
+	return call(t, controller, "delete-card", func() (bool, error) {
+		return t.IUHPPOTE.DeleteCard(controller, cardNumber)
+	})
+}
+
+func (t *UHPPOTE) DeleteCards(controller uint32) (bool, error) {
+	return call(t, controller, "delete-cards", func() (bool, error) {
+		return t.IUHPPOTE.DeleteCards(controller)
+	})
+}
+
+func (t *UHPPOTE) GetTimeProfile(controller uint32, profileID uint8) (*types.TimeProfile, error) {
+	return call(t, controller, "get-time-profile", func() (*types.TimeProfile, error) {
+		return t.IUHPPOTE.GetTimeProfile(controller, profileID)
+	})
+}
+
+func (t *UHPPOTE) SetTimeProfile(controller uint32, profile types.TimeProfile) (bool, error) {
+	return call(t, controller, "set-time-profile", func() (bool, error) {
+		return t.IUHPPOTE.SetTimeProfile(controller, profile)
+	})
+}
+
+func (t *UHPPOTE) ClearTimeProfiles(controller uint32) (bool, error) {
+	return call(t, controller, "clear-time-profiles", func() (bool, error) {
+		return t.IUHPPOTE.ClearTimeProfiles(controller)
+	})
+}
+
+func (t *UHPPOTE) ClearTaskList(controller uint32) (bool, error) {
+	return call(t, controller, "clear-task-list", func() (bool, error) {
+		return t.IUHPPOTE.ClearTaskList(controller)
+	})
+}
+
+func (t *UHPPOTE) AddTask(controller uint32, task types.Task) (bool, error) {
+	return call(t, controller, "add-task", func() (bool, error) {
+		return t.IUHPPOTE.AddTask(controller, task)
+	})
+}
+
+func (t *UHPPOTE) RefreshTaskList(controller uint32) (bool, error) {
+	return call(t, controller, "refresh-task-list", func() (bool, error) {
+		return t.IUHPPOTE.RefreshTaskList(controller)
+	})
+}
+
+func (t *UHPPOTE) RecordSpecialEvents(controller uint32, enable bool) (bool, error) {
+	return call(t, controller, "record-special-events", func() (bool, error) {
+		return t.IUHPPOTE.RecordSpecialEvents(controller, enable)
+	})
+}
+
+func (t *UHPPOTE) GetEvent(controller, index uint32) (*types.Event, error) {
+	return call(t, controller, "get-event", func() (*types.Event, error) {
+		return t.IUHPPOTE.GetEvent(controller, index)
+	})
+}
+
+func (t *UHPPOTE) GetEventIndex(controller uint32) (*types.EventIndex, error) {
+	return call(t, controller, "get-event-index", func() (*types.EventIndex, error) {
+		return t.IUHPPOTE.GetEventIndex(controller)
+	})
+}
+
+func (t *UHPPOTE) SetEventIndex(controller, index uint32) (*types.EventIndexResult, error) {
+	return call(t, controller, "set-event-index", func() (*types.EventIndexResult, error) {
+		return t.IUHPPOTE.SetEventIndex(controller, index)
+	})
+}
+
+func (t *UHPPOTE) SetDoorPasscodes(controller uint32, door uint8, passcodes ...uint32) (bool, error) {
+	return call(t, controller, "set-door-passcodes", func() (bool, error) {
+		return t.IUHPPOTE.SetDoorPasscodes(controller, door, passcodes...)
+	})
+}
+
+func (t *UHPPOTE) OpenDoor(controller uint32, door uint8) (*types.Result, error) {
+	return call(t, controller, "open-door", func() (*types.Result, error) {
+		return t.IUHPPOTE.OpenDoor(controller, door)
+	})
+}
+
+func (t *UHPPOTE) SetPCControl(controller uint32, enable bool) (bool, error) {
+	return call(t, controller, "set-pc-control", func() (bool, error) {
+		return t.IUHPPOTE.SetPCControl(controller, enable)
+	})
+}
+
+func (t *UHPPOTE) SetInterlock(controller uint32, interlock types.Interlock) (bool, error) {
+	return call(t, controller, "set-interlock", func() (bool, error) {
+		return t.IUHPPOTE.SetInterlock(controller, interlock)
+	})
+}
+
+func (t *UHPPOTE) ActivateKeypads(controller uint32, readers map[uint8]bool) (bool, error) {
+	return call(t, controller, "activate-keypads", func() (bool, error) {
+		return t.IUHPPOTE.ActivateKeypads(controller, readers)
+	})
+}
+
+func (t *UHPPOTE) GetAntiPassback(controller uint32) (types.AntiPassback, error) {
+	return call(t, controller, "get-antipassback", func() (types.AntiPassback, error) {
+		return t.IUHPPOTE.GetAntiPassback(controller)
+	})
+}
+
+func (t *UHPPOTE) SetAntiPassback(controller uint32, antipassback types.AntiPassback) (bool, error) {
+	return call(t, controller, "set-antipassback", func() (bool, error) {
+		return t.IUHPPOTE.SetAntiPassback(controller, antipassback)
+	})
+}
+
+func (t *UHPPOTE) SetFirstCard(controller uint32, door uint8, firstcard types.FirstCard) (bool, error) {
+	return call(t, controller, "set-first-card", func() (bool, error) {
+		return t.IUHPPOTE.SetFirstCard(controller, door, firstcard)
+	})
+}
+
+func (t *UHPPOTE) RestoreDefaultParameters(controller uint32) (bool, error) {
+	return call(t, controller, "restore-default-parameters", func() (bool, error) {
+		return t.IUHPPOTE.RestoreDefaultParameters(controller)
+	})
+}
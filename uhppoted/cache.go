@@ -0,0 +1,356 @@
+package uhppoted
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+// Cache is an optional IUHPPOTED decorator that caches the responses to read-heavy queries
+// (e.g. GetDevice, GetTimeProfiles, GetDoorDelay and GetCardRecords) for a per-operation TTL.
+// Cached responses for a controller are invalidated when the corresponding 'set' operation
+// is invoked through the cache. Changes made other than through the cache are only picked up
+// once the cached response expires.
+//
+// Cached responses are shared and should not be modified.
+type Cache struct {
+	IUHPPOTED
+	ttl         map[string]time.Duration
+	entries     map[cacheKey]*cacheEntry
+	generations map[uint32]uint64
+	stats       CacheStats
+	now         func() time.Time
+	guard       sync.Mutex
+}
+
+// CacheEntry is a summary of a cached response, for diagnostics.
+type CacheEntry struct {
+	Operation string    `json:"operation"`
+	DeviceID  DeviceID  `json:"device-id"`
+	Key       string    `json:"key,omitempty"`
+	Expires   time.Time `json:"expires"`
+	Hits      uint64    `json:"hits"`
+}
+
+type CacheStats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Invalidations uint64 `json:"invalidations"`
+}
+
+type cacheKey struct {
+	operation  string
+	controller uint32
+	key        string
+}
+
+type cacheEntry struct {
+	value   any
+	expires time.Time
+	hits    uint64
+}
+
+// Operations invalidated by each 'set' operation.
+var invalidates = map[string][]string{
+	"set-door-control":           {"get-door-control", "get-door-delay"},
+	"set-door-delay":             {"get-door-control", "get-door-delay"},
+	"put-card":                   {"get-card-records", "get-cards", "get-card"},
+	"delete-card":                {"get-card-records", "get-cards", "get-card"},
+	"delete-cards":               {"get-card-records", "get-cards", "get-card"},
+	"put-time-profile":           {"get-time-profiles", "get-time-profile"},
+	"put-time-profiles":          {"get-time-profiles", "get-time-profile"},
+	"clear-time-profiles":        {"get-time-profiles", "get-time-profile"},
+	"set-antipassback":           {"get-antipassback"},
+	"restore-default-parameters": {"get-door-control", "get-door-delay", "get-antipassback"},
+	"emergency":                  {"get-door-control", "get-door-delay"},
+	"reconcile":                  {"get-door-control", "get-door-delay", "get-antipassback"},
+}
+
+// Returns the default cache TTLs. Operations without a TTL are not cached.
+func DefaultCacheTTLs() map[string]time.Duration {
+	return map[string]time.Duration{
+		"get-device":        5 * time.Minute,
+		"get-door-delay":    60 * time.Second,
+		"get-door-control":  60 * time.Second,
+		"get-card-records":  60 * time.Second,
+		"get-cards":         60 * time.Second,
+		"get-card":          60 * time.Second,
+		"get-time-profiles": 5 * time.Minute,
+		"get-time-profile":  5 * time.Minute,
+		"get-antipassback":  5 * time.Minute,
+	}
+}
+
+// Wraps an IUHPPOTED with a response cache using the per-operation TTLs (or the default TTLs
+// if ttl is nil).
+func NewCache(u IUHPPOTED, ttl map[string]time.Duration) *Cache {
+	if ttl == nil {
+		ttl = DefaultCacheTTLs()
+	}
+
+	return &Cache{
+		IUHPPOTED:   u,
+		ttl:         maps.Clone(ttl),
+		entries:     map[cacheKey]*cacheEntry{},
+		generations: map[uint32]uint64{},
+		now:         time.Now,
+	}
+}
+
+// Returns the unexpired cache entries, ordered by operation, controller and key.
+func (c *Cache) Entries() []CacheEntry {
+	c.guard.Lock()
+	defer c.guard.Unlock()
+
+	now := c.now()
+	entries := []CacheEntry{}
+	for k, v := range c.entries {
+		if now.Before(v.expires) {
+			entries = append(entries, CacheEntry{
+				Operation: k.operation,
+				DeviceID:  DeviceID(k.controller),
+				Key:       k.key,
+				Expires:   v.expires,
+				Hits:      v.hits,
+			})
+		}
+	}
+
+	slices.SortFunc(entries, func(p, q CacheEntry) int {
+		return cmp.Or(
+			cmp.Compare(p.Operation, q.Operation),
+			cmp.Compare(p.DeviceID, q.DeviceID),
+			cmp.Compare(p.Key, q.Key))
+	})
+
+	return entries
+}
+
+func (c *Cache) Stats() CacheStats {
+	c.guard.Lock()
+	defer c.guard.Unlock()
+
+	return c.stats
+}
+
+// Discards all cached responses for a controller (or all controllers if the controller ID is 0).
+func (c *Cache) Invalidate(controller uint32) {
+	c.guard.Lock()
+	defer c.guard.Unlock()
+
+	for k := range c.entries {
+		if controller == 0 || k.controller == controller {
+			delete(c.entries, k)
+			c.stats.Invalidations++
+		}
+	}
+}
+
+func (c *Cache) GetDevice(request GetDeviceRequest) (*GetDeviceResponse, error) {
+	return cached(c, "get-device", uint32(request.DeviceID), "", func() (*GetDeviceResponse, error) {
+		return c.IUHPPOTED.GetDevice(request)
+	})
+}
+
+func (c *Cache) GetDoorDelay(request GetDoorDelayRequest) (*GetDoorDelayResponse, error) {
+	return cached(c, "get-door-delay", uint32(request.DeviceID), fmt.Sprintf("%v", request.Door), func() (*GetDoorDelayResponse, error) {
+		return c.IUHPPOTED.GetDoorDelay(request)
+	})
+}
+
+func (c *Cache) GetDoorControl(request GetDoorControlRequest) (*GetDoorControlResponse, error) {
+	return cached(c, "get-door-control", uint32(request.DeviceID), fmt.Sprintf("%v", request.Door), func() (*GetDoorControlResponse, error) {
+		return c.IUHPPOTED.GetDoorControl(request)
+	})
+}
+
+func (c *Cache) GetCardRecords(request GetCardRecordsRequest) (*GetCardRecordsResponse, error) {
+	return cached(c, "get-card-records", uint32(request.DeviceID), "", func() (*GetCardRecordsResponse, error) {
+		return c.IUHPPOTED.GetCardRecords(request)
+	})
+}
+
+func (c *Cache) GetCards(request GetCardsRequest) (*GetCardsResponse, error) {
+	return cached(c, "get-cards", uint32(request.DeviceID), "", func() (*GetCardsResponse, error) {
+		return c.IUHPPOTED.GetCards(request)
+	})
+}
+
+func (c *Cache) GetCard(request GetCardRequest) (*GetCardResponse, error) {
+	return cached(c, "get-card", uint32(request.DeviceID), fmt.Sprintf("%v", request.CardNumber), func() (*GetCardResponse, error) {
+		return c.IUHPPOTED.GetCard(request)
+	})
+}
+
+func (c *Cache) GetTimeProfiles(request GetTimeProfilesRequest) (*GetTimeProfilesResponse, error) {
+	return cached(c, "get-time-profiles", request.DeviceID, fmt.Sprintf("%v-%v", request.From, request.To), func() (*GetTimeProfilesResponse, error) {
+		return c.IUHPPOTED.GetTimeProfiles(request)
+	})
+}
+
+func (c *Cache) GetTimeProfile(request GetTimeProfileRequest) (*GetTimeProfileResponse, error) {
+	return cached(c, "get-time-profile", request.DeviceID, fmt.Sprintf("%v", request.ProfileID), func() (*GetTimeProfileResponse, error) {
+		return c.IUHPPOTED.GetTimeProfile(request)
+	})
+}
+
+func (c *Cache) GetAntiPassback(controller uint32) (types.AntiPassback, error) {
+	return cached(c, "get-antipassback", controller, "", func() (types.AntiPassback, error) {
+		return c.IUHPPOTED.GetAntiPassback(controller)
+	})
+}
+
+func (c *Cache) SetDoorControl(controller uint32, door uint8, mode types.ControlState) error {
+	defer c.invalidate("set-door-control", controller)
+
+	return c.IUHPPOTED.SetDoorControl(controller, door, mode)
+}
+
+func (c *Cache) SetDoorDelay(controller uint32, door uint8, delay uint8) error {
+	defer c.invalidate("set-door-delay", controller)
+
+	return c.IUHPPOTED.SetDoorDelay(controller, door, delay)
+}
+
+func (c *Cache) PutCard(controller uint32, card types.Card) (bool, error) {
+	defer c.invalidate("put-card", controller)
+
+	return c.IUHPPOTED.PutCard(controller, card)
+}
+
+func (c *Cache) DeleteCard(request DeleteCardRequest) (*DeleteCardResponse, error) {
+	defer c.invalidate("delete-card", uint32(request.DeviceID))
+
+	return c.IUHPPOTED.DeleteCard(request)
+}
+
+func (c *Cache) DeleteCards(request DeleteCardsRequest) (*DeleteCardsResponse, error) {
+	defer c.invalidate("delete-cards", uint32(request.DeviceID))
+
+	return c.IUHPPOTED.DeleteCards(request)
+}
+
+func (c *Cache) PutTimeProfile(request PutTimeProfileRequest) (*PutTimeProfileResponse, error) {
+	defer c.invalidate("put-time-profile", request.DeviceID)
+
+	return c.IUHPPOTED.PutTimeProfile(request)
+}
+
+func (c *Cache) PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProfilesResponse, error) {
+	defer c.invalidate("put-time-profiles", request.DeviceID)
+
+	return c.IUHPPOTED.PutTimeProfiles(request)
+}
+
+func (c *Cache) ClearTimeProfiles(request ClearTimeProfilesRequest) (*ClearTimeProfilesResponse, error) {
+	defer c.invalidate("clear-time-profiles", uint32(request.DeviceID))
+
+	return c.IUHPPOTED.ClearTimeProfiles(request)
+}
+
+func (c *Cache) SetAntiPassback(controller uint32, antipassback types.AntiPassback) (bool, error) {
+	defer c.invalidate("set-antipassback", controller)
+
+	return c.IUHPPOTED.SetAntiPassback(controller, antipassback)
+}
+
+func (c *Cache) RestoreDefaultParameters(controller uint32) error {
+	defer c.invalidate("restore-default-parameters", controller)
+
+	return c.IUHPPOTED.RestoreDefaultParameters(controller)
+}
+
+func (c *Cache) Lockdown(request EmergencyRequest) (*EmergencyResponse, error) {
+	defer c.invalidate("emergency", 0)
+
+	return c.IUHPPOTED.Lockdown(request)
+}
+
+func (c *Cache) Evacuate(request EmergencyRequest) (*EmergencyResponse, error) {
+	defer c.invalidate("emergency", 0)
+
+	return c.IUHPPOTED.Evacuate(request)
+}
+
+func (c *Cache) EndEmergency(request EndEmergencyRequest) (*EmergencyResponse, error) {
+	defer c.invalidate("emergency", 0)
+
+	return c.IUHPPOTED.EndEmergency(request)
+}
+
+func (c *Cache) Reconcile(request ReconcileRequest) (*ReconcileResponse, error) {
+	if !request.DryRun {
+		defer c.invalidate("reconcile", 0)
+	}
+
+	return c.IUHPPOTED.Reconcile(request)
+}
+
+// Returns the cached response if it has not expired, otherwise invokes f and caches the
+// response (errors are not cached). A response is not cached if the controller (or all
+// controllers) was invalidated while f was in progress, since it may predate the update.
+func cached[T any](c *Cache, operation string, controller uint32, key string, f func() (T, error)) (T, error) {
+	ttl, ok := c.ttl[operation]
+	if !ok || ttl <= 0 {
+		return f()
+	}
+
+	k := cacheKey{operation, controller, key}
+
+	c.guard.Lock()
+	if e, ok := c.entries[k]; ok && c.now().Before(e.expires) {
+		e.hits++
+		c.stats.Hits++
+		c.guard.Unlock()
+
+		return e.value.(T), nil
+	}
+
+	c.stats.Misses++
+	generation := c.generation(controller)
+	c.guard.Unlock()
+
+	v, err := f()
+	if err != nil {
+		return v, err
+	}
+
+	c.guard.Lock()
+	if c.generation(controller) == generation {
+		c.entries[k] = &cacheEntry{
+			value:   v,
+			expires: c.now().Add(ttl),
+		}
+	}
+	c.guard.Unlock()
+
+	return v, nil
+}
+
+// Discards the cached responses invalidated by the operation for the controller (or for all
+// controllers if the controller ID is 0).
+func (c *Cache) invalidate(operation string, controller uint32) {
+	c.guard.Lock()
+	defer c.guard.Unlock()
+
+	c.generations[controller]++
+
+	ops := invalidates[operation]
+	for k := range c.entries {
+		if (controller == 0 || k.controller == controller) && slices.Contains(ops, k.operation) {
+			delete(c.entries, k)
+			c.stats.Invalidations++
+		}
+	}
+}
+
+// Returns the invalidation count for a controller, including invalidations of all controllers.
+// Assumes the caller holds the cache guard.
+func (c *Cache) generation(controller uint32) uint64 {
+	return c.generations[0] + c.generations[controller]
+}
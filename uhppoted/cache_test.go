@@ -0,0 +1,138 @@
+package uhppoted
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 12, 30, 0, 0, time.UTC)
+	requests := 0
+	delay := uint8(5)
+
+	mock := stub{
+		getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+			requests++
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: types.Controlled, Delay: delay}, nil
+		},
+		setDoorControlState: func(controller uint32, door uint8, state types.ControlState, d uint8) (*types.DoorControlState, error) {
+			delay = d
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: d}, nil
+		},
+	}
+
+	cache := NewCache(&UHPPOTED{UHPPOTE: &mock}, nil)
+	cache.now = func() time.Time { return now }
+
+	get := func() uint8 {
+		response, err := cache.GetDoorDelay(GetDoorDelayRequest{DeviceID: 405419896, Door: 3})
+		if err != nil {
+			t.Fatalf("Unexpected error (%v)", err)
+		}
+
+		return response.Delay
+	}
+
+	get()
+	get()
+
+	if requests != 1 {
+		t.Errorf("Expected cached response - expected %v requests, got %v", 1, requests)
+	}
+
+	if entries := cache.Entries(); len(entries) != 1 || entries[0].Operation != "get-door-delay" || entries[0].Hits != 1 {
+		t.Errorf("Incorrect cache entries %+v", entries)
+	}
+
+	// ... invalidated by set-door-delay
+	if err := cache.SetDoorDelay(405419896, 3, 7); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	requests = 0
+	if d := get(); d != 7 || requests != 1 {
+		t.Errorf("Expected updated door delay after invalidation - expected:%v, got:%v (%v requests)", 7, d, requests)
+	}
+
+	// ... expired
+	now = now.Add(61 * time.Second)
+	get()
+
+	if requests != 2 {
+		t.Errorf("Expected expired cache entry to be refreshed - expected %v requests, got %v", 2, requests)
+	}
+
+	expected := CacheStats{Hits: 1, Misses: 3, Invalidations: 1}
+	if stats := cache.Stats(); stats != expected {
+		t.Errorf("Incorrect cache stats - expected:%+v, got:%+v", expected, stats)
+	}
+}
+
+func TestCacheWithInterleavedSet(t *testing.T) {
+	var guard sync.Mutex
+
+	delay := uint8(5)
+	requests := 0
+	reading := make(chan struct{})
+	release := make(chan struct{})
+
+	mock := stub{
+		getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+			guard.Lock()
+			requests++
+			d := delay
+			first := requests == 1
+			guard.Unlock()
+
+			// ... stall the first read until after the door delay has been updated
+			if first {
+				close(reading)
+				<-release
+			}
+
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: types.Controlled, Delay: d}, nil
+		},
+		setDoorControlState: func(controller uint32, door uint8, state types.ControlState, d uint8) (*types.DoorControlState, error) {
+			guard.Lock()
+			delay = d
+			guard.Unlock()
+
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: d}, nil
+		},
+	}
+
+	cache := NewCache(&UHPPOTED{UHPPOTE: &mock}, nil)
+
+	get := func() (uint8, error) {
+		if response, err := cache.GetDoorDelay(GetDoorDelayRequest{DeviceID: 405419896, Door: 3}); err != nil {
+			return 0, err
+		} else {
+			return response.Delay, nil
+		}
+	}
+
+	stale := make(chan uint8, 1)
+	go func() {
+		d, _ := get()
+		stale <- d
+	}()
+
+	<-reading
+	if err := cache.SetDoorDelay(405419896, 3, 7); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	close(release)
+	if d := <-stale; d != 5 {
+		t.Errorf("Incorrect door delay for interleaved read - expected:%v, got:%v", 5, d)
+	}
+
+	if d, err := get(); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if d != 7 {
+		t.Errorf("Stale door delay cached after update - expected:%v, got:%v", 7, d)
+	}
+}
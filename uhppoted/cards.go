@@ -13,7 +13,7 @@ func (u *UHPPOTED) GetCardRecords(request GetCardRecordsRequest) (*GetCardRecord
 
 	N, err := u.UHPPOTE.GetCards(device)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error retrieving number of cards from %v (%w)", device, err))
+		return nil, controllerError(device, "get-card-records", fmt.Errorf("error retrieving number of cards from %v (%w)", device, err))
 	}
 
 	response := GetCardRecordsResponse{
@@ -33,7 +33,7 @@ func (u *UHPPOTED) GetCards(request GetCardsRequest) (*GetCardsResponse, error)
 
 	N, err := u.UHPPOTE.GetCards(device)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error retrieving cards from %v (%w)", device, err))
+		return nil, controllerError(device, "get-cards", fmt.Errorf("error retrieving cards from %v (%w)", device, err))
 	}
 
 	cards := make([]uint32, 0)
@@ -42,7 +42,7 @@ func (u *UHPPOTED) GetCards(request GetCardsRequest) (*GetCardsResponse, error)
 	for count := uint32(0); count < N; {
 		record, err := u.UHPPOTE.GetCardByIndex(device, index)
 		if err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error retrieving cards from %v (%w)", device, err))
+			return nil, controllerError(device, "get-cards", fmt.Errorf("error retrieving cards from %v (%w)", device, err))
 		}
 
 		if record != nil {
@@ -70,7 +70,7 @@ func (u *UHPPOTED) DeleteCards(request DeleteCardsRequest) (*DeleteCardsResponse
 
 	deleted, err := u.UHPPOTE.DeleteCards(deviceID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error deleting cards from %v (%w)", deviceID, err))
+		return nil, controllerError(deviceID, "delete-cards", fmt.Errorf("error deleting cards from %v (%w)", deviceID, err))
 	}
 
 	response := DeleteCardsResponse{
@@ -91,11 +91,11 @@ func (u *UHPPOTED) GetCard(request GetCardRequest) (*GetCardResponse, error) {
 
 	card, err := u.UHPPOTE.GetCardByID(device, cardID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error retrieving card %v from %v (%w)", cardID, device, err))
+		return nil, controllerError(device, "get-card", fmt.Errorf("error retrieving card %v from %v (%w)", cardID, device, err))
 	}
 
 	if card == nil {
-		return nil, fmt.Errorf("%w: %v", ErrNotFound, fmt.Errorf("error retrieving card %v from %v", request.CardNumber, device))
+		return nil, notFound(device, "get-card", fmt.Errorf("error retrieving card %v from %v", request.CardNumber, device))
 	}
 
 	response := GetCardResponse{
@@ -112,9 +112,9 @@ func (u *UHPPOTED) PutCard(deviceID uint32, card types.Card) (bool, error) {
 	u.debug("put-card", fmt.Sprintf("%v card:%v", deviceID, card))
 
 	if ok, err := u.UHPPOTE.PutCard(deviceID, card); err != nil {
-		return false, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error writing card %v to %v (%w)", card.CardNumber, deviceID, err))
+		return false, controllerError(deviceID, "put-card", fmt.Errorf("error writing card %v to %v (%w)", card.CardNumber, deviceID, err))
 	} else if !ok {
-		return false, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("failed to write card %v to %v (%w)", card.CardNumber, deviceID, err))
+		return false, rejected(deviceID, "put-card", fmt.Errorf("failed to write card %v to %v", card.CardNumber, deviceID))
 	} else {
 		u.debug("put-card", fmt.Sprintf("response %+v", ok))
 
@@ -130,7 +130,7 @@ func (u *UHPPOTED) DeleteCard(request DeleteCardRequest) (*DeleteCardResponse, e
 
 	deleted, err := u.UHPPOTE.DeleteCard(deviceID, cardNo)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error deleting card %v from %v (%w)", cardNo, deviceID, err))
+		return nil, controllerError(deviceID, "delete-card", fmt.Errorf("error deleting card %v from %v (%w)", cardNo, deviceID, err))
 	}
 
 	response := DeleteCardResponse{
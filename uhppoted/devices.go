@@ -32,7 +32,7 @@ func (u *UHPPOTED) GetDevices(request GetDevicesRequest) (*GetDevicesResponse, e
 				u.warn("find", fmt.Errorf("get-devices: %v %v", deviceID, err))
 			} else if device != nil {
 				list.Store(uint32(device.SerialNumber), DeviceSummary{
-					DeviceType: identify(device.SerialNumber),
+					DeviceType: u.identify(device.SerialNumber),
 					Address:    device.IpAddress,
 					Port:       device.Address.Port(),
 				})
@@ -46,7 +46,7 @@ func (u *UHPPOTED) GetDevices(request GetDevicesRequest) (*GetDevicesResponse, e
 		} else {
 			for _, d := range devices {
 				list.Store(uint32(d.SerialNumber), DeviceSummary{
-					DeviceType: identify(d.SerialNumber),
+					DeviceType: u.identify(d.SerialNumber),
 					Address:    d.IpAddress,
 					Port:       d.Address.Port(),
 				})
@@ -73,18 +73,19 @@ func (u *UHPPOTED) GetDevices(request GetDevicesRequest) (*GetDevicesResponse, e
 func (u *UHPPOTED) GetDevice(request GetDeviceRequest) (*GetDeviceResponse, error) {
 	u.debug("get-device", fmt.Sprintf("request  %+v", request))
 
-	device, err := u.UHPPOTE.GetDevice(uint32(request.DeviceID))
+	controller := uint32(request.DeviceID)
+	device, err := u.UHPPOTE.GetDevice(controller)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error getting device info for %v (%w)", device, err))
+		return nil, controllerError(controller, "get-device", fmt.Errorf("error getting device info for %v (%w)", controller, err))
 	}
 
 	if device == nil {
-		return nil, fmt.Errorf("%w: %v", ErrNotFound, fmt.Errorf("no device found for device ID %v", device))
+		return nil, notFound(controller, "get-device", fmt.Errorf("no device found for device ID %v", controller))
 	}
 
 	response := GetDeviceResponse{
 		DeviceID:   DeviceID(device.SerialNumber),
-		DeviceType: identify(device.SerialNumber),
+		DeviceType: u.identify(device.SerialNumber),
 		IpAddress:  device.IpAddress,
 		SubnetMask: device.SubnetMask,
 		Gateway:    device.Gateway,
@@ -104,9 +105,9 @@ func (u *UHPPOTED) SetEventListener(controller uint32, addr netip.AddrPort, inte
 	u.debug("set-event-listener", fmt.Sprintf("%v address:%v interval:%v", controller, addr, interval))
 
 	if ok, err := u.UHPPOTE.SetListener(controller, addr, interval); err != nil {
-		return false, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("set-event-listener: %v %w", controller, err))
+		return false, controllerError(controller, "set-event-listener", err)
 	} else if !ok {
-		return false, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("set-event-listener: %v  failed", controller))
+		return false, rejected(controller, "set-event-listener", fmt.Errorf("failed to set event listener %v", addr))
 	}
 
 	return true, nil
@@ -118,7 +119,7 @@ func (u *UHPPOTED) GetAntiPassback(controller uint32) (types.AntiPassback, error
 	u.debug("get-antipassback", fmt.Sprintf("%v", controller))
 
 	if antipassback, err := u.UHPPOTE.GetAntiPassback(controller); err != nil {
-		return types.Disabled, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error retrieving antipassback (%w)", controller, err))
+		return types.Disabled, controllerError(controller, "get-antipassback", fmt.Errorf("error retrieving antipassback (%w)", err))
 	} else {
 		u.debug("get-antipassback", fmt.Sprintf("anti-passback %v", antipassback))
 
@@ -132,7 +133,7 @@ func (u *UHPPOTED) SetAntiPassback(controller uint32, antipassback types.AntiPas
 	u.debug("set-antipassback", fmt.Sprintf("%v %v", controller, antipassback))
 
 	if ok, err := u.UHPPOTE.SetAntiPassback(controller, antipassback); err != nil {
-		return false, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error setting antipassback (%w)", controller, err))
+		return false, controllerError(controller, "set-antipassback", fmt.Errorf("error setting antipassback (%w)", err))
 	} else {
 		u.debug("set-antipassback", fmt.Sprintf("anti-passback %v %v", antipassback, ok))
 
@@ -146,7 +147,7 @@ func (u *UHPPOTED) SetFirstCard(controller uint32, door uint8, firstcard types.F
 	u.debug("set-first-card", fmt.Sprintf("%v door:%v first-card:%v", controller, door, firstcard))
 
 	if ok, err := u.UHPPOTE.SetFirstCard(controller, door, firstcard); err != nil {
-		return false, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error setting first card (%w)", controller, err))
+		return false, controllerError(controller, "set-first-card", fmt.Errorf("error setting first card (%w)", err))
 	} else {
 		u.debug("set-first-card", fmt.Sprintf("anti-first-card %v %v %v", door, firstcard, ok))
 
@@ -161,9 +162,9 @@ func (u *UHPPOTED) RestoreDefaultParameters(controller uint32) error {
 
 	reset, err := u.UHPPOTE.RestoreDefaultParameters(controller)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error resetting controller to manufacturer default configuration (%w)", controller, err))
+		return controllerError(controller, "restore-default-parameters", fmt.Errorf("error resetting controller to manufacturer default configuration (%w)", err))
 	} else if !reset {
-		return fmt.Errorf("%w: %v", ErrFailed, fmt.Errorf("%v  failed to reset controller to manufacturer default configuration", controller))
+		return rejected(controller, "restore-default-parameters", fmt.Errorf("failed to reset controller to manufacturer default configuration"))
 	}
 
 	u.debug("restore-default-parameters", fmt.Sprintf("reset %v", reset))
@@ -171,7 +172,13 @@ func (u *UHPPOTED) RestoreDefaultParameters(controller uint32) error {
 	return nil
 }
 
-func identify(deviceID types.SerialNumber) string {
+// Returns the configured controller model, if any, and otherwise guesses the model from the
+// controller serial number.
+func (u *UHPPOTED) identify(deviceID types.SerialNumber) string {
+	if model, ok := u.Models[uint32(deviceID)]; ok && model != "" {
+		return model
+	}
+
 	id := strconv.FormatUint(uint64(deviceID), 10)
 
 	if strings.HasPrefix(id, "4") {
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net"
 	"testing"
+
+	"github.com/uhppoted/uhppote-core/types"
 )
 
 func TestDeviceSummaryToJSON(t *testing.T) {
@@ -25,3 +27,23 @@ func TestDeviceSummaryToJSON(t *testing.T) {
 		t.Fatalf("incorrectly marshalled DeviceSummary\nexpected: %v\ngot:     %v", expected, string(bytes))
 	}
 }
+
+func TestIdentify(t *testing.T) {
+	u := UHPPOTED{
+		Models: map[uint32]string{
+			405419896: "UT0311-L02",
+		},
+	}
+
+	tests := map[types.SerialNumber]string{
+		405419896: "UT0311-L02",
+		303986753: "UTO311-L03",
+		201020304: "UTO311-L02",
+	}
+
+	for controller, expected := range tests {
+		if model := u.identify(controller); model != expected {
+			t.Errorf("Incorrect model for %v - expected:%v, got:%v", controller, expected, model)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package uhppoted
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+// DoorState is the state of a door decoded from the controller status door sensor, relay
+// and most recent event.
+type DoorState uint8
+
+const (
+	DoorStateUnknown DoorState = iota
+	DoorStateClosed
+	DoorStateUnlocked
+	DoorStateOpen
+	DoorStateHeldOpen
+	DoorStateForced
+)
+
+// DoorStatus is the current tracked state of a door.
+type DoorStatus struct {
+	DeviceID DeviceID  `json:"device-id"`
+	Door     uint8     `json:"door"`
+	Name     string    `json:"name,omitempty"`
+	State    DoorState `json:"state"`
+	Open     bool      `json:"open"`
+	Unlocked bool      `json:"unlocked"`
+	Button   bool      `json:"button"`
+	Since    time.Time `json:"since"`
+}
+
+// DoorStateChange is the notification sent to DoorTracker subscribers when a door state
+// changes.
+type DoorStateChange struct {
+	DeviceID  DeviceID  `json:"device-id"`
+	Door      uint8     `json:"door"`
+	Name      string    `json:"name,omitempty"`
+	From      DoorState `json:"from"`
+	To        DoorState `json:"to"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DoorTracker maintains the state of controller doors from either polled controller status
+// or the status received with live events. A door is 'held open' if it has been open for
+// longer than the door delay (or the controller reports 'door open too long') and 'forced'
+// if the controller reports 'forced open'.
+type DoorTracker struct {
+	uhppoted *UHPPOTED
+	doors    map[doorID]*door
+	delays   map[doorID]time.Duration
+	events   map[uint32]uint32
+	handlers []func(DoorStateChange)
+	now      func() time.Time
+	guard    sync.Mutex
+}
+
+type doorID struct {
+	controller uint32
+	door       uint8
+}
+
+type door struct {
+	status   DoorStatus
+	opened   time.Time
+	forced   bool
+	heldOpen bool
+}
+
+const (
+	reasonDoorOpenTooLong uint8 = 37
+	reasonForcedOpen      uint8 = 38
+)
+
+var doorStates = map[DoorState]string{
+	DoorStateUnknown:  "unknown",
+	DoorStateClosed:   "closed",
+	DoorStateUnlocked: "unlocked",
+	DoorStateOpen:     "open",
+	DoorStateHeldOpen: "held open",
+	DoorStateForced:   "forced",
+}
+
+func (s DoorState) String() string {
+	if v, ok := doorStates[s]; ok {
+		return v
+	}
+
+	return fmt.Sprintf("%d", uint8(s))
+}
+
+func (s DoorState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func NewDoorTracker(u *UHPPOTED) *DoorTracker {
+	return &DoorTracker{
+		uhppoted: u,
+		doors:    map[doorID]*door{},
+		delays:   map[doorID]time.Duration{},
+		events:   map[uint32]uint32{},
+		handlers: []func(DoorStateChange){},
+		now:      time.Now,
+	}
+}
+
+// Adds a handler for door state change notifications. Handlers are invoked synchronously
+// from Poll, Update and Check.
+func (t *DoorTracker) Subscribe(handler func(DoorStateChange)) {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	t.handlers = append(t.handlers, handler)
+}
+
+// Retrieves the controller status and updates the tracked door states.
+func (t *DoorTracker) Poll(controller uint32) ([]DoorStateChange, error) {
+	status, err := t.uhppoted.UHPPOTE.GetStatus(controller)
+	if err != nil {
+		return nil, controllerError(controller, "get-status", fmt.Errorf("error retrieving status for %v (%w)", controller, err))
+	} else if status == nil {
+		return nil, rejected(controller, "get-status", fmt.Errorf("no status returned for %v", controller))
+	}
+
+	return t.Update(controller, *status), nil
+}
+
+// Updates the tracked door states from a controller status (e.g. received with a live event).
+func (t *DoorTracker) Update(controller uint32, status types.Status) []DoorStateChange {
+	now := t.now()
+	doors := []uint8{}
+
+	for d := range status.DoorState {
+		if d >= 1 && d <= 4 {
+			doors = append(doors, d)
+		}
+	}
+
+	slices.Sort(doors)
+
+	// ... door delays are retrieved outside the lock
+	for _, d := range doors {
+		if status.DoorState[d] {
+			t.delay(controller, d)
+		}
+	}
+
+	t.guard.Lock()
+
+	event := status.Event
+	if event.Index == t.events[controller] {
+		event = types.StatusEvent{}
+	} else if event.Index != 0 {
+		t.events[controller] = event.Index
+	}
+
+	changes := []DoorStateChange{}
+	for _, d := range doors {
+		v := t.door(controller, d)
+		open := status.DoorState[d]
+
+		if open && !v.status.Open {
+			v.opened = now
+		} else if !open {
+			v.opened = time.Time{}
+			v.forced = false
+			v.heldOpen = false
+		}
+
+		v.status.Open = open
+		v.status.Button = status.DoorButton[d]
+		v.status.Unlocked = status.RelayState&(1<<(d-1)) != 0
+
+		if open && event.Index != 0 && event.Door == d {
+			switch event.Reason {
+			case reasonForcedOpen:
+				v.forced = true
+
+			case reasonDoorOpenTooLong:
+				v.heldOpen = true
+			}
+		}
+
+		if change, ok := t.transition(v, now); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	handlers := slices.Clone(t.handlers)
+	t.guard.Unlock()
+
+	t.notify(handlers, changes)
+
+	return changes
+}
+
+// Re-evaluates the tracked door states against the current time, e.g. to detect a door that
+// has been held open since the last status update.
+func (t *DoorTracker) Check() []DoorStateChange {
+	now := t.now()
+	changes := []DoorStateChange{}
+
+	t.guard.Lock()
+
+	for _, v := range t.doors {
+		if change, ok := t.transition(v, now); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	handlers := slices.Clone(t.handlers)
+	t.guard.Unlock()
+
+	slices.SortFunc(changes, func(p, q DoorStateChange) int {
+		if p.DeviceID != q.DeviceID {
+			return int(p.DeviceID) - int(q.DeviceID)
+		}
+
+		return int(p.Door) - int(q.Door)
+	})
+
+	t.notify(handlers, changes)
+
+	return changes
+}
+
+// Returns the tracked state of the controller doors, ordered by door.
+func (t *DoorTracker) Doors(controller uint32) []DoorStatus {
+	t.guard.Lock()
+	defer t.guard.Unlock()
+
+	doors := []DoorStatus{}
+	for k, v := range t.doors {
+		if k.controller == controller {
+			doors = append(doors, v.status)
+		}
+	}
+
+	slices.SortFunc(doors, func(p, q DoorStatus) int {
+		return int(p.Door) - int(q.Door)
+	})
+
+	return doors
+}
+
+func (t *DoorTracker) door(controller uint32, d uint8) *door {
+	k := doorID{controller, d}
+
+	if v, ok := t.doors[k]; ok {
+		return v
+	}
+
+	v := &door{
+		status: DoorStatus{
+			DeviceID: DeviceID(controller),
+			Door:     d,
+			State:    DoorStateUnknown,
+		},
+	}
+
+	if device, ok := t.uhppoted.UHPPOTE.DeviceList()[controller]; ok && int(d) <= len(device.Doors) {
+		v.status.Name = device.Doors[d-1]
+	}
+
+	t.doors[k] = v
+
+	return v
+}
+
+func (t *DoorTracker) transition(v *door, now time.Time) (DoorStateChange, bool) {
+	state := t.classify(v, now)
+
+	if state == v.status.State {
+		return DoorStateChange{}, false
+	}
+
+	change := DoorStateChange{
+		DeviceID:  v.status.DeviceID,
+		Door:      v.status.Door,
+		Name:      v.status.Name,
+		From:      v.status.State,
+		To:        state,
+		Timestamp: now,
+	}
+
+	v.status.State = state
+	v.status.Since = now
+
+	return change, true
+}
+
+func (t *DoorTracker) classify(v *door, now time.Time) DoorState {
+	delay, ok := t.delays[doorID{uint32(v.status.DeviceID), v.status.Door}]
+
+	switch {
+	case v.status.Open && v.forced:
+		return DoorStateForced
+
+	case v.status.Open && v.heldOpen:
+		return DoorStateHeldOpen
+
+	case v.status.Open && ok && now.Sub(v.opened) > delay:
+		return DoorStateHeldOpen
+
+	case v.status.Open:
+		return DoorStateOpen
+
+	case v.status.Unlocked:
+		return DoorStateUnlocked
+
+	default:
+		return DoorStateClosed
+	}
+}
+
+// Retrieves and caches the door delay used to detect a door held open. Errors are logged and
+// retried on the next update.
+func (t *DoorTracker) delay(controller uint32, d uint8) {
+	k := doorID{controller, d}
+
+	t.guard.Lock()
+	_, ok := t.delays[k]
+	t.guard.Unlock()
+
+	if ok {
+		return
+	}
+
+	if response, err := t.uhppoted.GetDoorDelay(GetDoorDelayRequest{DeviceID: DeviceID(controller), Door: d}); err != nil {
+		t.uhppoted.warn("door-tracker", fmt.Errorf("%v: error retrieving door %v delay (%w)", controller, d, err))
+	} else {
+		t.guard.Lock()
+		t.delays[k] = time.Duration(response.Delay) * time.Second
+		t.guard.Unlock()
+	}
+}
+
+func (t *DoorTracker) notify(handlers []func(DoorStateChange), changes []DoorStateChange) {
+	for _, change := range changes {
+		t.uhppoted.debug("door-tracker", fmt.Sprintf("%v door %v (%v) %v -> %v", change.DeviceID, change.Door, change.Name, change.From, change.To))
+
+		for _, h := range handlers {
+			h(change)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package uhppoted
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+func TestDoorTracker(t *testing.T) {
+	now := time.Date(2026, time.June, 15, 12, 0, 0, 0, time.Local)
+	status := types.Status{
+		SerialNumber: 405419896,
+		DoorState:    map[uint8]bool{1: false, 2: false, 3: false, 4: false},
+		DoorButton:   map[uint8]bool{1: false, 2: false, 3: false, 4: false},
+	}
+
+	mock := stub{
+		deviceList: func() map[uint32]uhppote.Device {
+			return map[uint32]uhppote.Device{
+				405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{"Front", "Back", "Garage", "Workshop"}, time.Local),
+			}
+		},
+
+		getStatus: func(controller uint32) (*types.Status, error) {
+			return &status, nil
+		},
+
+		getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+			return &types.DoorControlState{
+				SerialNumber: types.SerialNumber(controller),
+				Door:         door,
+				ControlState: types.Controlled,
+				Delay:        5,
+			}, nil
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE: &mock,
+	}
+
+	notified := []DoorStateChange{}
+	tracker := NewDoorTracker(&u)
+	tracker.now = func() time.Time { return now }
+	tracker.Subscribe(func(change DoorStateChange) {
+		notified = append(notified, change)
+	})
+
+	poll := func() []DoorStateChange {
+		changes, err := tracker.Poll(405419896)
+		if err != nil {
+			t.Fatalf("Unexpected error (%v)", err)
+		}
+
+		return changes
+	}
+
+	if changes := poll(); len(changes) != 4 {
+		t.Errorf("Expected initial state for 4 doors, got %v", changes)
+	}
+
+	// ... unlocked
+	status.RelayState = 0x04
+	if changes := poll(); len(changes) != 1 || changes[0].Name != "Garage" || changes[0].To != DoorStateUnlocked {
+		t.Errorf("Incorrect 'unlocked' change - got %v", changes)
+	}
+
+	// ... opened
+	now = now.Add(1 * time.Second)
+	status.DoorState[3] = true
+	if changes := poll(); len(changes) != 1 || changes[0].From != DoorStateUnlocked || changes[0].To != DoorStateOpen {
+		t.Errorf("Incorrect 'open' change - got %v", changes)
+	}
+
+	// ... held open
+	now = now.Add(10 * time.Second)
+	status.RelayState = 0x00
+	if changes := tracker.Check(); len(changes) != 1 || changes[0].To != DoorStateHeldOpen {
+		t.Errorf("Incorrect 'held open' change - got %v", changes)
+	}
+
+	// ... closed
+	status.DoorState[3] = false
+	if changes := poll(); len(changes) != 1 || changes[0].To != DoorStateClosed {
+		t.Errorf("Incorrect 'closed' change - got %v", changes)
+	}
+
+	// ... forced open
+	status.DoorState[1] = true
+	status.Event = types.StatusEvent{Index: 17, Type: 2, Door: 1, Reason: 38}
+	if changes := poll(); len(changes) != 1 || changes[0].Name != "Front" || changes[0].To != DoorStateForced {
+		t.Errorf("Incorrect 'forced' change - got %v", changes)
+	}
+
+	if len(notified) != 9 {
+		t.Errorf("Expected 9 notifications, got %v", len(notified))
+	}
+
+	expected := []DoorState{DoorStateForced, DoorStateClosed, DoorStateClosed, DoorStateClosed}
+	states := []DoorState{}
+	for _, d := range tracker.Doors(405419896) {
+		states = append(states, d.State)
+	}
+
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("Incorrect door states - expected:%v, got:%v", expected, states)
+	}
+}
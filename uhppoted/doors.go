@@ -13,7 +13,7 @@ func (u *UHPPOTED) GetDoorDelay(request GetDoorDelayRequest) (*GetDoorDelayRespo
 	door := request.Door
 	result, err := u.UHPPOTE.GetDoorControlState(controller, door)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error getting door %v delay for %v (%w)", door, controller, err))
+		return nil, controllerError(controller, "get-door-delay", fmt.Errorf("error getting door %v delay for %v (%w)", door, controller, err))
 	}
 
 	response := GetDoorDelayResponse{
@@ -32,12 +32,12 @@ func (u *UHPPOTED) SetDoorDelay(controller uint32, door uint8, delay uint8) erro
 
 	state, err := u.UHPPOTE.GetDoorControlState(controller, door)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error getting door %v delay (%w)", controller, door, err))
+		return controllerError(controller, "set-door-delay", fmt.Errorf("error getting door %v delay (%w)", door, err))
 	}
 
 	response, err := u.UHPPOTE.SetDoorControlState(controller, door, state.ControlState, delay)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error setting door %v delay (%ws)", controller, door, err))
+		return controllerError(controller, "set-door-delay", fmt.Errorf("error setting door %v delay (%w)", door, err))
 	}
 
 	u.debug("set-door-delay", fmt.Sprintf("response %+v", response))
@@ -52,7 +52,7 @@ func (u *UHPPOTED) GetDoorControl(request GetDoorControlRequest) (*GetDoorContro
 	door := request.Door
 	result, err := u.UHPPOTE.GetDoorControlState(controller, door)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error getting door %v control for %v (%w)", door, controller, err))
+		return nil, controllerError(controller, "get-door-control", fmt.Errorf("error getting door %v control for %v (%w)", door, controller, err))
 	}
 
 	response := GetDoorControlResponse{
@@ -71,12 +71,12 @@ func (u *UHPPOTED) SetDoorControl(controller uint32, door uint8, mode types.Cont
 
 	state, err := u.UHPPOTE.GetDoorControlState(controller, door)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error getting door %v control mode (%w)", controller, door, err))
+		return controllerError(controller, "set-door-control", fmt.Errorf("error getting door %v control mode (%w)", door, err))
 	}
 
 	response, err := u.UHPPOTE.SetDoorControlState(controller, door, mode, state.Delay)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error setting door %v control mode %v (%w)", controller, door, mode, err))
+		return controllerError(controller, "set-door-control", fmt.Errorf("error setting door %v control mode %v (%w)", door, mode, err))
 	}
 
 	u.debug("set-door-control", fmt.Sprintf("response %+v", response))
@@ -89,7 +89,7 @@ func (u *UHPPOTED) SetDoorPasscodes(controller uint32, door uint8, passcodes ...
 
 	response, err := u.UHPPOTE.SetDoorPasscodes(controller, door, passcodes...)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error setting door %v passcodes (%w)", controller, door, err))
+		return controllerError(controller, "set-door-passcodes", fmt.Errorf("error setting door %v passcodes (%w)", door, err))
 	}
 
 	u.debug("set-door-passcodes", fmt.Sprintf("response %+v", response))
@@ -104,7 +104,7 @@ func (u *UHPPOTED) OpenDoor(request OpenDoorRequest) (*OpenDoorResponse, error)
 	door := request.Door
 	result, err := u.UHPPOTE.OpenDoor(controller, door)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error opening door %v on %v (%w)", door, controller, err))
+		return nil, controllerError(controller, "open-door", fmt.Errorf("error opening door %v on %v (%w)", door, controller, err))
 	}
 
 	response := OpenDoorResponse{
@@ -123,7 +123,7 @@ func (u *UHPPOTED) SetInterlock(controller uint32, interlock types.Interlock) er
 
 	response, err := u.UHPPOTE.SetInterlock(controller, interlock)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error setting door interlock %v (%w)", controller, interlock, err))
+		return controllerError(controller, "set-interlock", fmt.Errorf("error setting door interlock %v (%w)", interlock, err))
 	}
 
 	u.debug("set-interlock", fmt.Sprintf("%v  response:%+v", controller, response))
@@ -136,9 +136,9 @@ func (u *UHPPOTED) ActivateKeypads(controller uint32, keypads map[uint8]bool) er
 
 	response, err := u.UHPPOTE.ActivateKeypads(controller, keypads)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error activating controller access keypads (%w)", controller, err))
+		return controllerError(controller, "activate-keypads", fmt.Errorf("error activating controller access keypads (%w)", err))
 	} else if !response {
-		return fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  failed activate controller access keypads", controller))
+		return rejected(controller, "activate-keypads", fmt.Errorf("failed to activate controller access keypads"))
 	}
 
 	u.debug("activate-keypads", fmt.Sprintf("%v  response:%+v", controller, response))
@@ -0,0 +1,351 @@
+package uhppoted
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+// EmergencyMode is the site-wide door mode set by Lockdown or Evacuate.
+type EmergencyMode uint8
+
+const (
+	EmergencyNone EmergencyMode = iota
+	EmergencyLockdown
+	EmergencyEvacuation
+)
+
+// Emergency is the record of an active lockdown or evacuation, with the door control state
+// of each door before the lockdown/evacuation was started.
+type Emergency struct {
+	Mode    EmergencyMode    `json:"mode"`
+	Zone    string           `json:"zone,omitempty"`
+	Started time.Time        `json:"started"`
+	Doors   []SavedDoorState `json:"doors"`
+}
+
+// SavedDoorState is the door control state of a door before a lockdown or evacuation.
+type SavedDoorState struct {
+	DeviceID uint32             `json:"device-id"`
+	Door     uint8              `json:"door"`
+	Mode     types.ControlState `json:"mode"`
+	Delay    uint8              `json:"delay"`
+}
+
+// ControllerDoor identifies a door by controller ID and door number.
+type ControllerDoor struct {
+	DeviceID DeviceID `json:"device-id"`
+	Door     uint8    `json:"door"`
+}
+
+var emergencyStates = map[EmergencyMode]types.ControlState{
+	EmergencyLockdown:   types.NormallyClosed,
+	EmergencyEvacuation: types.NormallyOpen,
+}
+
+var emergencyModes = map[EmergencyMode]string{
+	EmergencyNone:       "none",
+	EmergencyLockdown:   "lockdown",
+	EmergencyEvacuation: "evacuation",
+}
+
+func (m EmergencyMode) String() string {
+	if v, ok := emergencyModes[m]; ok {
+		return v
+	}
+
+	return fmt.Sprintf("%d", uint8(m))
+}
+
+func (m EmergencyMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *EmergencyMode) UnmarshalText(bytes []byte) error {
+	for k, v := range emergencyModes {
+		if v == string(bytes) {
+			*m = k
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid emergency mode '%s'", string(bytes))
+}
+
+// Sets the doors in the request to 'normally closed'. If the request door list is empty, the doors
+// in the request zone (or all configured doors if the request zone is blank) are locked down. A
+// zone that is not defined in UHPPOTED.Zones is an invalid request. The existing door control
+// states are saved so that EndEmergency can restore them - a door that is already in a lockdown
+// or evacuation for another zone keeps the door control state saved by the first lockdown or
+// evacuation. Repeating a lockdown retries any doors that could not be updated without
+// overwriting the saved door control states.
+func (u *UHPPOTED) Lockdown(request EmergencyRequest) (*EmergencyResponse, error) {
+	return u.emergency("lockdown", EmergencyLockdown, types.NormallyClosed, request)
+}
+
+// Sets the doors in the request (or in the request zone, as for Lockdown) to 'normally open'. The
+// existing door control states are saved so that EndEmergency can restore them. An evacuation
+// started during a lockdown of the same zone keeps the door control states saved by the lockdown.
+func (u *UHPPOTED) Evacuate(request EmergencyRequest) (*EmergencyResponse, error) {
+	return u.emergency("evacuate", EmergencyEvacuation, types.NormallyOpen, request)
+}
+
+// Restores the door control states saved by Lockdown or Evacuate for a zone. A door that is also
+// in another active lockdown or evacuation is set to the mode of that lockdown/evacuation and is
+// only restored when the last lockdown/evacuation covering the door is ended. Doors that could
+// not be restored remain in the saved state so that EndEmergency can be retried. Ending a zone
+// with no active lockdown or evacuation is not an error.
+func (u *UHPPOTED) EndEmergency(request EndEmergencyRequest) (*EmergencyResponse, error) {
+	u.debug("end-emergency", fmt.Sprintf("request  %+v", request))
+
+	if u.Emergencies == nil {
+		return nil, controllerError(0, "end-emergency", fmt.Errorf("no emergency store configured"))
+	}
+
+	u.emergencies.Lock()
+	defer u.emergencies.Unlock()
+
+	zone := request.Zone
+	record, ok, err := u.Emergencies.GetEmergency(zone)
+	if err != nil {
+		return nil, controllerError(0, "end-emergency", fmt.Errorf("error retrieving saved door states for zone '%v' (%w)", zone, err))
+	}
+
+	response := EmergencyResponse{
+		Mode:  EmergencyNone,
+		Zone:  zone,
+		Doors: []EmergencyDoor{},
+	}
+
+	if !ok {
+		u.debug("end-emergency", fmt.Sprintf("no active lockdown or evacuation for zone '%v'", zone))
+		return &response, nil
+	}
+
+	active, err := u.Emergencies.ListEmergencies()
+	if err != nil {
+		return nil, controllerError(0, "end-emergency", fmt.Errorf("error retrieving active lockdowns and evacuations (%w)", err))
+	}
+
+	delete(active, zone)
+
+	remaining := []SavedDoorState{}
+	for _, saved := range record.Doors {
+		result := EmergencyDoor{
+			DeviceID: DeviceID(saved.DeviceID),
+			Door:     saved.Door,
+			Name:     u.doorName(saved.DeviceID, saved.Door),
+			State:    saved.Mode,
+		}
+
+		if other, ok := covering(active, saved.DeviceID, saved.Door); ok {
+			u.debug("end-emergency", fmt.Sprintf("%v door %v: %v active for zone '%v'", saved.DeviceID, saved.Door, other.Mode, other.Zone))
+			result.State = emergencyStates[other.Mode]
+		}
+
+		if err := u.setDoorMode(saved.DeviceID, saved.Door, result.State, saved.Delay, "end-emergency"); err != nil {
+			result.failed(err)
+			remaining = append(remaining, saved)
+		}
+
+		response.Doors = append(response.Doors, result)
+	}
+
+	if len(remaining) == 0 {
+		err = u.Emergencies.DeleteEmergency(zone)
+	} else {
+		record.Doors = remaining
+		response.Mode = record.Mode
+		err = u.Emergencies.PutEmergency(zone, *record)
+	}
+
+	if err != nil {
+		return nil, controllerError(0, "end-emergency", fmt.Errorf("error updating saved door states for zone '%v' (%w)", zone, err))
+	}
+
+	u.debug("end-emergency", fmt.Sprintf("response %+v", response))
+
+	return &response, nil
+}
+
+func (u *UHPPOTED) emergency(operation string, mode EmergencyMode, state types.ControlState, request EmergencyRequest) (*EmergencyResponse, error) {
+	u.debug(operation, fmt.Sprintf("request  %+v", request))
+
+	if u.Emergencies == nil {
+		return nil, controllerError(0, operation, fmt.Errorf("no emergency store configured"))
+	}
+
+	var err error
+
+	zone := request.Zone
+	doors := request.Doors
+	if len(doors) == 0 {
+		if zone == "" {
+			doors = u.doors()
+		} else if _, ok := u.lookupZone(zone); !ok {
+			return nil, invalid(0, operation, "zone", fmt.Errorf("zone '%v' is not defined", zone))
+		} else if doors, err = u.ResolveDoors([]string{zone}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(doors) == 0 {
+		return nil, invalid(0, operation, "doors", fmt.Errorf("no doors in zone '%v'", zone))
+	}
+
+	// ... the saved door states are a read-modify-write across all the active lockdowns and evacuations
+	u.emergencies.Lock()
+	defer u.emergencies.Unlock()
+
+	record, ok, err := u.Emergencies.GetEmergency(zone)
+	if err != nil {
+		return nil, controllerError(0, operation, fmt.Errorf("error retrieving saved door states for zone '%v' (%w)", zone, err))
+	} else if !ok {
+		record = &Emergency{
+			Zone:    zone,
+			Started: time.Now(),
+			Doors:   []SavedDoorState{},
+		}
+	}
+
+	record.Mode = mode
+
+	active, err := u.Emergencies.ListEmergencies()
+	if err != nil {
+		return nil, controllerError(0, operation, fmt.Errorf("error retrieving active lockdowns and evacuations (%w)", err))
+	}
+
+	delete(active, zone)
+
+	// ... save the current door control states before changing anything
+	results := []EmergencyDoor{}
+	for _, d := range doors {
+		controller := uint32(d.DeviceID)
+		result := EmergencyDoor{
+			DeviceID: d.DeviceID,
+			Door:     d.Door,
+			Name:     u.doorName(controller, d.Door),
+			State:    state,
+		}
+
+		if !slices.ContainsFunc(record.Doors, func(v SavedDoorState) bool { return v.DeviceID == controller && v.Door == d.Door }) {
+			if other, ok := covering(active, controller, d.Door); ok {
+				ix := slices.IndexFunc(other.Doors, func(v SavedDoorState) bool { return v.DeviceID == controller && v.Door == d.Door })
+				record.Doors = append(record.Doors, other.Doors[ix])
+			} else if current, err := u.UHPPOTE.GetDoorControlState(controller, d.Door); err != nil {
+				result.failed(controllerError(controller, operation, fmt.Errorf("error getting door %v control state (%w)", d.Door, err)))
+			} else if current == nil {
+				result.failed(rejected(controller, operation, fmt.Errorf("no control state returned for door %v", d.Door)))
+			} else {
+				record.Doors = append(record.Doors, SavedDoorState{
+					DeviceID: controller,
+					Door:     d.Door,
+					Mode:     current.ControlState,
+					Delay:    current.Delay,
+				})
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	if err := u.Emergencies.PutEmergency(zone, *record); err != nil {
+		return nil, controllerError(0, operation, fmt.Errorf("error saving door states for zone '%v' (%w)", zone, err))
+	}
+
+	// ... update doors
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		controller := uint32(result.DeviceID)
+		ix := slices.IndexFunc(record.Doors, func(v SavedDoorState) bool { return v.DeviceID == controller && v.Door == result.Door })
+		if err := u.setDoorMode(controller, result.Door, state, record.Doors[ix].Delay, operation); err != nil {
+			results[i].failed(err)
+		}
+	}
+
+	response := EmergencyResponse{
+		Mode:  mode,
+		Zone:  zone,
+		Doors: results,
+	}
+
+	u.debug(operation, fmt.Sprintf("response %+v", response))
+
+	return &response, nil
+}
+
+// Returns the (earliest) active lockdown/evacuation with a saved door control state for a door.
+func covering(emergencies map[string]Emergency, controller uint32, door uint8) (Emergency, bool) {
+	var found *Emergency
+
+	for _, e := range emergencies {
+		if slices.ContainsFunc(e.Doors, func(v SavedDoorState) bool { return v.DeviceID == controller && v.Door == door }) {
+			if found == nil || e.Started.Before(found.Started) || (e.Started.Equal(found.Started) && e.Zone < found.Zone) {
+				found = &e
+			}
+		}
+	}
+
+	if found != nil {
+		return *found, true
+	}
+
+	return Emergency{}, false
+}
+
+func (u *UHPPOTED) setDoorMode(controller uint32, door uint8, mode types.ControlState, delay uint8, operation string) error {
+	if response, err := u.UHPPOTE.SetDoorControlState(controller, door, mode, delay); err != nil {
+		return controllerError(controller, operation, fmt.Errorf("error setting door %v control mode %v (%w)", door, mode, err))
+	} else if response == nil || response.ControlState != mode {
+		return rejected(controller, operation, fmt.Errorf("door %v control mode not set to %v", door, mode))
+	}
+
+	return nil
+}
+
+// Returns all the doors of the configured controllers, ordered by controller ID and door.
+func (u *UHPPOTED) doors() []ControllerDoor {
+	devices := u.UHPPOTE.DeviceList()
+	controllers := []uint32{}
+
+	for id := range devices {
+		controllers = append(controllers, id)
+	}
+
+	slices.Sort(controllers)
+
+	doors := []ControllerDoor{}
+	for _, id := range controllers {
+		N := len(devices[id].Doors)
+		if N == 0 {
+			N = 4
+		}
+
+		for d := 1; d <= N; d++ {
+			doors = append(doors, ControllerDoor{
+				DeviceID: DeviceID(id),
+				Door:     uint8(d),
+			})
+		}
+	}
+
+	return doors
+}
+
+func (u *UHPPOTED) doorName(controller uint32, door uint8) string {
+	if device, ok := u.UHPPOTE.DeviceList()[controller]; ok && door >= 1 && int(door) <= len(device.Doors) {
+		return device.Doors[door-1]
+	}
+
+	return ""
+}
+
+func (d *EmergencyDoor) failed(err error) {
+	d.Err = err
+	d.Error = err.Error()
+}
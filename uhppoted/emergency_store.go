@@ -0,0 +1,169 @@
+package uhppoted
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	lib "github.com/uhppoted/uhppoted-lib/os"
+)
+
+// EmergencyStore holds the door control states saved when a lockdown or evacuation is started,
+// so that ending the lockdown or evacuation restores the doors exactly, even after a restart.
+type EmergencyStore interface {
+	// Returns the active lockdown/evacuation for a zone and false if there is none.
+	GetEmergency(zone string) (*Emergency, bool, error)
+
+	// Saves the active lockdown/evacuation for a zone.
+	PutEmergency(zone string, emergency Emergency) error
+
+	// Removes the lockdown/evacuation for a zone.
+	DeleteEmergency(zone string) error
+
+	// Returns all the active lockdowns/evacuations, keyed by zone.
+	ListEmergencies() (map[string]Emergency, error)
+}
+
+type emergencyStore struct {
+	file        string
+	emergencies map[string]Emergency
+	loaded      bool
+	guard       sync.Mutex
+}
+
+// Creates an EmergencyStore that persists the active lockdowns and evacuations to the file
+// emergency.json in the directory 'dir'. A blank directory creates an in-memory store.
+func NewEmergencyStore(dir string) EmergencyStore {
+	file := ""
+	if dir != "" {
+		file = filepath.Join(dir, "emergency.json")
+	}
+
+	return &emergencyStore{
+		file:        file,
+		emergencies: map[string]Emergency{},
+		loaded:      dir == "",
+	}
+}
+
+func (s *emergencyStore) GetEmergency(zone string) (*Emergency, bool, error) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, false, err
+	}
+
+	if v, ok := s.emergencies[zone]; ok {
+		v.Doors = slices.Clone(v.Doors)
+
+		return &v, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (s *emergencyStore) PutEmergency(zone string, emergency Emergency) error {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	emergencies := maps.Clone(s.emergencies)
+	emergencies[zone] = Emergency{
+		Mode:    emergency.Mode,
+		Zone:    emergency.Zone,
+		Started: emergency.Started,
+		Doors:   slices.Clone(emergency.Doors),
+	}
+
+	return s.save(emergencies)
+}
+
+func (s *emergencyStore) DeleteEmergency(zone string) error {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	emergencies := maps.Clone(s.emergencies)
+	delete(emergencies, zone)
+
+	return s.save(emergencies)
+}
+
+func (s *emergencyStore) ListEmergencies() (map[string]Emergency, error) {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	emergencies := map[string]Emergency{}
+	for k, v := range s.emergencies {
+		v.Doors = slices.Clone(v.Doors)
+		emergencies[k] = v
+	}
+
+	return emergencies, nil
+}
+
+func (s *emergencyStore) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	bytes, err := os.ReadFile(s.file)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.loaded = true
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	emergencies := map[string]Emergency{}
+	if err := json.Unmarshal(bytes, &emergencies); err != nil {
+		return err
+	}
+
+	s.emergencies = emergencies
+	s.loaded = true
+
+	return nil
+}
+
+func (s *emergencyStore) save(emergencies map[string]Emergency) error {
+	if s.file != "" {
+		bytes, err := json.MarshalIndent(emergencies, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(s.file), 0755); err != nil {
+			return err
+		}
+
+		tmpfile := s.file + ".tmp"
+		if err := os.WriteFile(tmpfile, bytes, 0644); err != nil {
+			return err
+		}
+
+		if err := lib.Rename(tmpfile, s.file); err != nil {
+			return err
+		}
+	}
+
+	s.emergencies = emergencies
+
+	return nil
+}
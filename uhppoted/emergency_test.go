@@ -0,0 +1,320 @@
+package uhppoted
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+func TestLockdown(t *testing.T) {
+	doors := map[uint8]types.DoorControlState{
+		1: {SerialNumber: 405419896, Door: 1, ControlState: types.Controlled, Delay: 5},
+		2: {SerialNumber: 405419896, Door: 2, ControlState: types.NormallyOpen, Delay: 7},
+		3: {SerialNumber: 405419896, Door: 3, ControlState: types.Controlled, Delay: 3},
+		4: {SerialNumber: 405419896, Door: 4, ControlState: types.NormallyClosed, Delay: 10},
+	}
+
+	original := map[uint8]types.DoorControlState{}
+	for k, v := range doors {
+		original[k] = v
+	}
+
+	offline := true
+
+	mock := stub{
+		deviceList: func() map[uint32]uhppote.Device {
+			return map[uint32]uhppote.Device{
+				405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{"Front", "Back", "Garage", "Workshop"}, time.Local),
+			}
+		},
+
+		getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+			v := doors[door]
+			return &v, nil
+		},
+
+		setDoorControlState: func(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+			if door == 2 && offline {
+				return nil, fmt.Errorf("timeout")
+			}
+
+			doors[door] = types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: delay}
+
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: delay}, nil
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE:     &mock,
+		Emergencies: NewEmergencyStore(t.TempDir()),
+	}
+
+	// ... lockdown with door 2 failing
+	response, err := u.Lockdown(EmergencyRequest{Zone: ""})
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if len(response.Doors) != 4 {
+		t.Fatalf("Expected 4 doors in response, got %v", len(response.Doors))
+	} else if response.Doors[1].Err == nil || response.Doors[1].Name != "Back" {
+		t.Errorf("Expected 'Back' door to fail, got %+v", response.Doors[1])
+	}
+
+	// ... retry
+	offline = false
+	if response, err := u.Lockdown(EmergencyRequest{Zone: ""}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else {
+		for _, d := range response.Doors {
+			if d.Err != nil {
+				t.Errorf("Unexpected error for door %v (%v)", d.Door, d.Err)
+			}
+		}
+	}
+
+	for d, v := range doors {
+		if v.ControlState != types.NormallyClosed {
+			t.Errorf("Door %v not locked down - expected:%v, got:%v", d, types.NormallyClosed, v.ControlState)
+		}
+	}
+
+	// ... evacuation keeps the door states saved by the lockdown
+	if _, err := u.Evacuate(EmergencyRequest{Zone: ""}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	for d, v := range doors {
+		if v.ControlState != types.NormallyOpen {
+			t.Errorf("Door %v not evacuated - expected:%v, got:%v", d, types.NormallyOpen, v.ControlState)
+		}
+	}
+
+	// ... restore
+	if response, err := u.EndEmergency(EndEmergencyRequest{Zone: ""}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if response.Mode != EmergencyNone {
+		t.Errorf("Incorrect mode after restore - expected:%v, got:%v", EmergencyNone, response.Mode)
+	}
+
+	if !reflect.DeepEqual(doors, original) {
+		t.Errorf("Door states not restored\n   expected:%v\n   got:     %v", original, doors)
+	}
+
+	if _, ok, err := u.Emergencies.GetEmergency(""); err != nil || ok {
+		t.Errorf("Expected saved door states to be removed (%v)", err)
+	}
+
+	// ... ending twice is not an error
+	if _, err := u.EndEmergency(EndEmergencyRequest{Zone: ""}); err != nil {
+		t.Errorf("Unexpected error (%v)", err)
+	}
+}
+
+func TestLockdownWithOverlappingZones(t *testing.T) {
+	doors := map[uint8]types.DoorControlState{
+		1: {SerialNumber: 405419896, Door: 1, ControlState: types.Controlled, Delay: 5},
+		2: {SerialNumber: 405419896, Door: 2, ControlState: types.Controlled, Delay: 7},
+		3: {SerialNumber: 405419896, Door: 3, ControlState: types.NormallyOpen, Delay: 3},
+	}
+
+	original := map[uint8]types.DoorControlState{}
+	for k, v := range doors {
+		original[k] = v
+	}
+
+	mock := stub{
+		deviceList: func() map[uint32]uhppote.Device {
+			return map[uint32]uhppote.Device{
+				405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{"Front", "Back", "Garage", "Workshop"}, time.Local),
+			}
+		},
+
+		getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+			v := doors[door]
+			return &v, nil
+		},
+
+		setDoorControlState: func(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+			doors[door] = types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: delay}
+
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: delay}, nil
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE:     &mock,
+		Emergencies: NewEmergencyStore(t.TempDir()),
+	}
+
+	lab := EmergencyRequest{
+		Zone:  "lab",
+		Doors: []ControllerDoor{{405419896, 1}, {405419896, 2}},
+	}
+
+	store := EmergencyRequest{
+		Zone:  "store",
+		Doors: []ControllerDoor{{405419896, 2}, {405419896, 3}},
+	}
+
+	if _, err := u.Lockdown(lab); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if _, err := u.Evacuate(store); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	// ... ending the lab lockdown leaves the shared door in the store evacuation
+	if _, err := u.EndEmergency(EndEmergencyRequest{Zone: "lab"}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if doors[1] != original[1] {
+		t.Errorf("Door 1 not restored - expected:%v, got:%v", original[1], doors[1])
+	}
+
+	if doors[2].ControlState != types.NormallyOpen {
+		t.Errorf("Incorrect shared door state - expected:%v, got:%v", types.NormallyOpen, doors[2].ControlState)
+	}
+
+	// ... ending the store evacuation restores the shared door to the state before the lab lockdown
+	if _, err := u.EndEmergency(EndEmergencyRequest{Zone: "store"}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !reflect.DeepEqual(doors, original) {
+		t.Errorf("Door states not restored\n   expected:%v\n   got:     %v", original, doors)
+	}
+}
+
+func TestLockdownWithConcurrentOverlappingZones(t *testing.T) {
+	for range 20 {
+		var guard sync.Mutex
+
+		doors := map[uint8]types.DoorControlState{
+			1: {SerialNumber: 405419896, Door: 1, ControlState: types.Controlled, Delay: 5},
+			2: {SerialNumber: 405419896, Door: 2, ControlState: types.Controlled, Delay: 7},
+			3: {SerialNumber: 405419896, Door: 3, ControlState: types.NormallyOpen, Delay: 3},
+		}
+
+		original := maps.Clone(doors)
+
+		mock := stub{
+			deviceList: func() map[uint32]uhppote.Device {
+				return map[uint32]uhppote.Device{
+					405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{"Front", "Back", "Garage", "Workshop"}, time.Local),
+				}
+			},
+
+			getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+				// ... slow read so that the lab lockdown completes while the store evacuation is saving states
+				if door == 3 {
+					time.Sleep(10 * time.Millisecond)
+				}
+
+				guard.Lock()
+				defer guard.Unlock()
+
+				v := doors[door]
+				return &v, nil
+			},
+
+			setDoorControlState: func(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+				guard.Lock()
+				defer guard.Unlock()
+
+				doors[door] = types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: delay}
+
+				return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: delay}, nil
+			},
+		}
+
+		u := UHPPOTED{
+			UHPPOTE:     &mock,
+			Emergencies: NewEmergencyStore(t.TempDir()),
+		}
+
+		lab := EmergencyRequest{
+			Zone:  "lab",
+			Doors: []ControllerDoor{{405419896, 1}, {405419896, 2}},
+		}
+
+		store := EmergencyRequest{
+			Zone:  "store",
+			Doors: []ControllerDoor{{405419896, 3}, {405419896, 2}},
+		}
+
+		var wg sync.WaitGroup
+
+		wg.Go(func() {
+			if _, err := u.Lockdown(lab); err != nil {
+				t.Errorf("Unexpected error (%v)", err)
+			}
+		})
+
+		wg.Go(func() {
+			if _, err := u.Evacuate(store); err != nil {
+				t.Errorf("Unexpected error (%v)", err)
+			}
+		})
+
+		wg.Wait()
+
+		for _, zone := range []string{"lab", "store"} {
+			if _, err := u.EndEmergency(EndEmergencyRequest{Zone: zone}); err != nil {
+				t.Fatalf("Unexpected error (%v)", err)
+			}
+		}
+
+		if !reflect.DeepEqual(doors, original) {
+			t.Fatalf("Door states not restored\n   expected:%v\n   got:     %v", original, doors)
+		}
+	}
+}
+
+func TestLockdownWithUnknownZone(t *testing.T) {
+	set := 0
+
+	mock := stub{
+		deviceList: func() map[uint32]uhppote.Device {
+			return map[uint32]uhppote.Device{
+				405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{"Front", "Back", "Garage", "Workshop"}, time.Local),
+			}
+		},
+
+		setDoorControlState: func(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+			set++
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: delay}, nil
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE:     &mock,
+		Emergencies: NewEmergencyStore(""),
+		Zones: map[string][]string{
+			"lab": {"Front", "Back"},
+		},
+	}
+
+	if _, err := u.Lockdown(EmergencyRequest{Zone: "labb"}); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected %v for unknown zone, got:%v", ErrBadRequest, err)
+	}
+
+	if _, err := u.Evacuate(EmergencyRequest{Zone: "labb"}); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected %v for unknown zone, got:%v", ErrBadRequest, err)
+	}
+
+	if set != 0 {
+		t.Errorf("Expected no doors to be updated for unknown zone, got:%v", set)
+	}
+
+	if _, ok, _ := u.Emergencies.GetEmergency("labb"); ok {
+		t.Errorf("Unexpected saved door states for unknown zone")
+	}
+}
@@ -0,0 +1,186 @@
+package uhppoted
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	lib "github.com/uhppoted/uhppoted-lib/os"
+)
+
+// ControllerError is the common interface implemented by the typed errors returned by the
+// UHPPOTED API functions.
+type ControllerError interface {
+	error
+	Controller() uint32
+	Op() string
+}
+
+// OpError holds the controller ID and API operation common to all the typed errors.
+type OpError struct {
+	DeviceID  uint32
+	Operation string
+	Err       error
+}
+
+// ValidationError is returned when a request is invalid. Field is the path of the invalid
+// field in the request (e.g. profiles[3].linked-profile).
+type ValidationError struct {
+	OpError
+	Field string
+}
+
+// TimeoutError is returned when the controller did not respond to a request.
+type TimeoutError struct {
+	OpError
+}
+
+// RejectedError is returned when the controller responded to a request but did not execute it.
+type RejectedError struct {
+	OpError
+}
+
+// NotFoundError is returned when the controller does not have the requested record (or the
+// controller itself could not be found).
+type NotFoundError struct {
+	OpError
+}
+
+// TransportError is returned for any other failure to communicate with the controller.
+type TransportError struct {
+	OpError
+}
+
+// Warnings is a list of non-fatal errors that is serialized as the list of error messages.
+type Warnings []error
+
+func (e OpError) Controller() uint32 {
+	return e.DeviceID
+}
+
+func (e OpError) Op() string {
+	return e.Operation
+}
+
+func (e OpError) Unwrap() error {
+	return e.Err
+}
+
+func (e OpError) Error() string {
+	if e.DeviceID != 0 {
+		return fmt.Sprintf("%v %v: %v", e.Operation, e.DeviceID, e.Err)
+	}
+
+	return fmt.Sprintf("%v: %v", e.Operation, e.Err)
+}
+
+func (e ValidationError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("%v (%v)", e.OpError.Error(), e.Field)
+	}
+
+	return e.OpError.Error()
+}
+
+func (e ValidationError) Is(target error) bool {
+	return target == ErrBadRequest
+}
+
+func (e TimeoutError) Is(target error) bool {
+	return target == ErrInternalServerError
+}
+
+func (e RejectedError) Is(target error) bool {
+	return target == ErrFailed || target == ErrInternalServerError
+}
+
+func (e NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+func (e TransportError) Is(target error) bool {
+	return target == ErrInternalServerError
+}
+
+// Serializes the warnings as a list of the error messages.
+func (w Warnings) MarshalJSON() ([]byte, error) {
+	messages := []string{}
+	for _, err := range w {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	return json.Marshal(messages)
+}
+
+// Maps an error returned by the UHPPOTED API to the equivalent HTTP status code.
+func HTTPStatus(err error) int {
+	var timeout TimeoutError
+	var rejected RejectedError
+
+	switch {
+	case err == nil:
+		return http.StatusOK
+
+	case errors.As(err, &timeout):
+		return http.StatusGatewayTimeout
+
+	case errors.As(err, &rejected):
+		return http.StatusBadGateway
+
+	default:
+		return MQTTReplyCode(err)
+	}
+}
+
+// Maps an error returned by the UHPPOTED API to the equivalent MQTT reply error code. MQTT
+// replies only distinguish between 'bad request', 'not found', 'unauthorized' and 'internal
+// error'.
+func MQTTReplyCode(err error) int {
+	switch {
+	case err == nil:
+		return StatusOK
+
+	case errors.Is(err, ErrBadRequest):
+		return StatusBadRequest
+
+	case errors.Is(err, ErrNotFound):
+		return StatusNotFound
+
+	case errors.Is(err, ErrUnauthorized):
+		return StatusUnauthorized
+
+	default:
+		return StatusInternalServerError
+	}
+}
+
+// Wraps an error returned by the IUHPPOTE implementation as either a TimeoutError (if the
+// request timed out) or a TransportError. Errors that are already typed are returned as is.
+func controllerError(controller uint32, operation string, err error) error {
+	var e ControllerError
+
+	if err == nil {
+		return nil
+	} else if errors.As(err, &e) {
+		return err
+	} else if lib.IsTimeout(err) {
+		return TimeoutError{OpError{controller, operation, err}}
+	} else {
+		return TransportError{OpError{controller, operation, err}}
+	}
+}
+
+func invalid(controller uint32, operation string, field string, err error) error {
+	return ValidationError{OpError{controller, operation, err}, field}
+}
+
+func rejected(controller uint32, operation string, err error) error {
+	return RejectedError{OpError{controller, operation, err}}
+}
+
+func notFound(controller uint32, operation string, err error) error {
+	return NotFoundError{OpError{controller, operation, err}}
+}
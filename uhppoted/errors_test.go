@@ -0,0 +1,88 @@
+package uhppoted
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+func TestControllerErrors(t *testing.T) {
+	tests := []struct {
+		err        error
+		sentinel   error
+		controller uint32
+		operation  string
+		http       int
+		mqtt       int
+	}{
+		{invalid(405419896, "put-time-profile", "time-profile.id", fmt.Errorf("invalid")), ErrBadRequest, 405419896, "put-time-profile", http.StatusBadRequest, StatusBadRequest},
+		{notFound(405419896, "get-card", fmt.Errorf("no card")), ErrNotFound, 405419896, "get-card", http.StatusNotFound, StatusNotFound},
+		{rejected(405419896, "put-card", fmt.Errorf("failed")), ErrFailed, 405419896, "put-card", http.StatusBadGateway, StatusInternalServerError},
+		{controllerError(405419896, "get-time", fmt.Errorf("wrapped (%w)", os.ErrDeadlineExceeded)), ErrInternalServerError, 405419896, "get-time", http.StatusGatewayTimeout, StatusInternalServerError},
+		{controllerError(405419896, "get-time", fmt.Errorf("no route to host")), ErrInternalServerError, 405419896, "get-time", http.StatusInternalServerError, StatusInternalServerError},
+		{fmt.Errorf("%w: %v", ErrUnauthorized, fmt.Errorf("not allowed")), ErrUnauthorized, 0, "", http.StatusUnauthorized, StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		if !errors.Is(test.err, test.sentinel) {
+			t.Errorf("%v: expected errors.Is(%v), got false", test.err, test.sentinel)
+		}
+
+		var e ControllerError
+		if test.controller != 0 {
+			if !errors.As(test.err, &e) {
+				t.Errorf("%v: expected ControllerError", test.err)
+			} else if e.Controller() != test.controller || e.Op() != test.operation {
+				t.Errorf("%v: incorrect controller/operation - expected:%v %v, got:%v %v", test.err, test.controller, test.operation, e.Controller(), e.Op())
+			}
+		}
+
+		if status := HTTPStatus(test.err); status != test.http {
+			t.Errorf("%v: incorrect HTTP status - expected:%v, got:%v", test.err, test.http, status)
+		}
+
+		if code := MQTTReplyCode(test.err); code != test.mqtt {
+			t.Errorf("%v: incorrect MQTT reply code - expected:%v, got:%v", test.err, test.mqtt, code)
+		}
+	}
+}
+
+func TestPutTimeProfilesWithDuplicateProfile(t *testing.T) {
+	profile := types.TimeProfile{
+		ID:       29,
+		From:     types.MustParseDate("2026-01-01"),
+		To:       types.MustParseDate("2026-12-31"),
+		Weekdays: types.Weekdays{time.Monday: true},
+		Segments: types.Segments{},
+	}
+
+	duplicate := profile
+	duplicate.To = types.MustParseDate("2026-06-30")
+
+	u := UHPPOTED{
+		UHPPOTE: &stub{},
+	}
+
+	_, err := u.PutTimeProfiles(PutTimeProfilesRequest{
+		DeviceID: 405419896,
+		Profiles: []types.TimeProfile{profile, duplicate},
+	})
+
+	var verr ValidationError
+	if err == nil {
+		t.Fatalf("Expected validation error, got %v", err)
+	} else if !errors.As(err, &verr) {
+		t.Fatalf("Expected ValidationError, got %T (%v)", err, err)
+	} else if verr.Field != "profiles[1]" || verr.Controller() != 405419896 || verr.Op() != "put-time-profiles" {
+		t.Errorf("Incorrect validation error (%#v)", verr)
+	}
+
+	if HTTPStatus(err) != http.StatusBadRequest {
+		t.Errorf("Incorrect HTTP status - expected:%v, got:%v", http.StatusBadRequest, HTTPStatus(err))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+	"github.com/uhppoted/uhppoted-lib/tracing"
 )
 
 // FIXME: remove - superseded by reworked uhppote-core/types/Event
@@ -29,19 +31,19 @@ func (u *UHPPOTED) GetEventIndices(deviceID uint32) (uint32, uint32, uint32, err
 	var current uint32 = 0
 
 	if v, err := u.UHPPOTE.GetEvent(deviceID, 0); err != nil {
-		return 0, 0, 0, err
+		return 0, 0, 0, controllerError(deviceID, "get-event-indices", err)
 	} else if v != nil {
 		first = v.Index
 	}
 
 	if v, err := u.UHPPOTE.GetEvent(deviceID, 0xffffffff); err != nil {
-		return 0, 0, 0, err
+		return 0, 0, 0, controllerError(deviceID, "get-event-indices", err)
 	} else if v != nil {
 		last = v.Index
 	}
 
 	if v, err := u.UHPPOTE.GetEventIndex(deviceID); err != nil {
-		return 0, 0, 0, err
+		return 0, 0, 0, controllerError(deviceID, "get-event-indices", err)
 	} else if v != nil {
 		current = v.Index
 	}
@@ -52,11 +54,11 @@ func (u *UHPPOTED) GetEventIndices(deviceID uint32) (uint32, uint32, uint32, err
 func (u *UHPPOTED) GetEvent(deviceID uint32, index uint32) (*Event, error) {
 	event, err := u.UHPPOTE.GetEvent(deviceID, index)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, err)
+		return nil, controllerError(deviceID, "get-event", err)
 	} else if event == nil {
-		return nil, fmt.Errorf("%w: %v", ErrNotFound, fmt.Errorf("%v: no event %v", deviceID, index))
+		return nil, notFound(deviceID, "get-event", fmt.Errorf("no event %v", index))
 	} else if index != 0 && index != 0xffffffff && event.Index != index {
-		return nil, fmt.Errorf("%w: %v", ErrNotFound, fmt.Errorf("%v: no event %v", deviceID, index))
+		return nil, notFound(deviceID, "get-event", fmt.Errorf("no event %v", index))
 	}
 
 	return &Event{
@@ -75,17 +77,27 @@ func (u *UHPPOTED) GetEvent(deviceID uint32, index uint32) (*Event, error) {
 // Retrieves up to N events subsequent to the 'current' event index (or the 'first' event if the current event index
 // is less than the first event index). The on-device index is updated to the index of the last retrieved event.
 func (u *UHPPOTED) GetEvents(deviceID uint32, N int) ([]Event, error) {
+	x, span := u.trace("get-events", deviceID, tracing.Attr("N", N))
+	events, err := getEvents(x, deviceID, N)
+
+	span.SetAttributes(tracing.Attr("events", len(events)))
+	span.End(err)
+
+	return events, err
+}
+
+func getEvents(u uhppote.IUHPPOTE, deviceID uint32, N int) ([]Event, error) {
 	var first uint32 = 0
 	var current uint32 = 0
 
-	if v, err := u.UHPPOTE.GetEvent(deviceID, 0); err != nil {
-		return nil, err
+	if v, err := u.GetEvent(deviceID, 0); err != nil {
+		return nil, controllerError(deviceID, "get-events", err)
 	} else if v != nil {
 		first = v.Index
 	}
 
-	if v, err := u.UHPPOTE.GetEventIndex(deviceID); err != nil {
-		return nil, err
+	if v, err := u.GetEventIndex(deviceID); err != nil {
+		return nil, controllerError(deviceID, "get-events", err)
 	} else if v != nil {
 		current = v.Index
 	}
@@ -95,9 +107,9 @@ func (u *UHPPOTED) GetEvents(deviceID uint32, N int) ([]Event, error) {
 	events := []Event{}
 
 	for len(events) < N {
-		event, err := u.UHPPOTE.GetEvent(deviceID, index)
+		event, err := u.GetEvent(deviceID, index)
 		if err != nil {
-			return nil, fmt.Errorf("%w", err)
+			return nil, controllerError(deviceID, "get-events", err)
 		}
 
 		if event == nil {
@@ -120,13 +132,13 @@ func (u *UHPPOTED) GetEvents(deviceID uint32, N int) ([]Event, error) {
 		index++
 	}
 
-	response, err := u.UHPPOTE.SetEventIndex(deviceID, current)
+	response, err := u.SetEventIndex(deviceID, current)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, err)
+		return nil, controllerError(deviceID, "get-events", err)
 	} else if response == nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("no response to set-event-index %v for %v", current, deviceID))
+		return nil, controllerError(deviceID, "get-events", fmt.Errorf("no response to set-event-index %v for %v", current, deviceID))
 	} else if response.Index != current {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("failed to update %v event index to %v", deviceID, current))
+		return nil, rejected(deviceID, "get-events", fmt.Errorf("failed to update %v event index to %v", deviceID, current))
 	}
 
 	return events, nil
@@ -139,7 +151,7 @@ func (u *UHPPOTED) RecordSpecialEvents(deviceID uint32, enable bool) (bool, erro
 
 	updated, err := u.UHPPOTE.RecordSpecialEvents(deviceID, enable)
 	if err != nil {
-		return false, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("%v  error enabling/disabling 'record special events' (%w)", deviceID, err))
+		return false, controllerError(deviceID, "record-special-events", fmt.Errorf("error enabling/disabling 'record special events' (%w)", err))
 	}
 
 	u.debug("record-special-events", fmt.Sprintf("updated %+v", updated))
@@ -150,16 +162,16 @@ func (u *UHPPOTED) RecordSpecialEvents(deviceID uint32, enable bool) (bool, erro
 func (u *UHPPOTED) FetchEvents(controller uint32, from uint32, N uint32) ([]types.Event, error) {
 	first, err := u.UHPPOTE.GetEvent(controller, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve 'first' event for controller %d (%w)", controller, err)
+		return nil, controllerError(controller, "fetch-events", fmt.Errorf("failed to retrieve 'first' event for controller %d (%w)", controller, err))
 	} else if first == nil {
-		return nil, fmt.Errorf("no 'first' event record returned for controller %d", controller)
+		return nil, notFound(controller, "fetch-events", fmt.Errorf("no 'first' event record returned for controller %d", controller))
 	}
 
 	last, err := u.UHPPOTE.GetEvent(controller, 0xffffffff)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve 'last' event for controller %d (%w)", controller, err)
-	} else if first == nil {
-		return nil, fmt.Errorf("no 'last' event record returned for controller %d", controller)
+		return nil, controllerError(controller, "fetch-events", fmt.Errorf("failed to retrieve 'last' event for controller %d (%w)", controller, err))
+	} else if last == nil {
+		return nil, notFound(controller, "fetch-events", fmt.Errorf("no 'last' event record returned for controller %d", controller))
 	}
 
 	var events []types.Event
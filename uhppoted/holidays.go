@@ -0,0 +1,342 @@
+package uhppoted
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppoted-lib/encoding/tsv"
+)
+
+type Holiday struct {
+	Date   types.Date `json:"date"`
+	Name   string     `json:"name,omitempty"`
+	Yearly bool       `json:"yearly,omitempty"`
+}
+
+type HolidayCalendar struct {
+	Name     string    `json:"name,omitempty"`
+	Holidays []Holiday `json:"holidays"`
+}
+
+type PutHolidayProfilesRequest struct {
+	DeviceID uint32
+	Profile  types.TimeProfile
+	Calendar HolidayCalendar
+	Reserved []uint8
+}
+
+// Parses a holiday calendar from a TSV file with 'Date' and 'Holiday' columns e.g.
+//
+//	Date        Holiday
+//	2026-01-01  New Year's Day
+//	2026-12-25  Christmas Day
+func ParseHolidaysTSV(r io.Reader) (HolidayCalendar, error) {
+	records := []struct {
+		Date types.Date `tsv:"Date"`
+		Name string     `tsv:"Holiday"`
+	}{}
+
+	calendar := HolidayCalendar{
+		Holidays: []Holiday{},
+	}
+
+	if bytes, err := io.ReadAll(r); err != nil {
+		return calendar, err
+	} else if err := tsv.Unmarshal(bytes, &records); err != nil {
+		return calendar, err
+	}
+
+	for _, record := range records {
+		calendar.Holidays = append(calendar.Holidays, Holiday{
+			Date: record.Date,
+			Name: record.Name,
+		})
+	}
+
+	return calendar, nil
+}
+
+// Parses a holiday calendar from an iCalendar (RFC 5545) file. Only the VEVENT DTSTART, DTEND, SUMMARY
+// and RRULE properties are used - multi-day events are expanded to one holiday per day and the only
+// supported recurrence rule is FREQ=YEARLY (which is typical of published public holiday calendars).
+func ParseHolidaysICS(r io.Reader) (HolidayCalendar, error) {
+	calendar := HolidayCalendar{
+		Holidays: []Holiday{},
+	}
+
+	lines, err := unfold(r)
+	if err != nil {
+		return calendar, err
+	}
+
+	re := regexp.MustCompile(`^([A-Za-z0-9-]+)((?:;[^:]*)?):(.*)$`)
+
+	var event map[string]string
+	for i, line := range lines {
+		match := re.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		property := strings.ToUpper(match[1])
+		value := match[3]
+
+		switch {
+		case property == "X-WR-CALNAME":
+			calendar.Name = unescape(value)
+
+		case property == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			event = map[string]string{}
+
+		case property == "END" && strings.EqualFold(value, "VEVENT"):
+			if event != nil {
+				if holidays, err := vevent(event); err != nil {
+					return calendar, fmt.Errorf("line %v: %w", i+1, err)
+				} else {
+					calendar.Holidays = append(calendar.Holidays, holidays...)
+				}
+			}
+
+			event = nil
+
+		case event != nil:
+			event[property] = value
+		}
+	}
+
+	return calendar, nil
+}
+
+// Returns the sorted list of unique holiday dates in the date range [from,to], with yearly
+// holidays expanded for every year in the range. A yearly holiday is skipped in the years in
+// which its date does not exist (e.g. February 29 in a non-leap year).
+func (c HolidayCalendar) Between(from, to types.Date) []types.Date {
+	dates := []types.Date{}
+	contains := func(d types.Date) bool {
+		return !d.Before(from) && !d.After(to)
+	}
+
+	for _, h := range c.Holidays {
+		if h.Date.IsZero() {
+			continue
+		}
+
+		if !h.Yearly {
+			if contains(h.Date) {
+				dates = append(dates, h.Date)
+			}
+			continue
+		}
+
+		d := time.Time(h.Date)
+		for year := time.Time(from).Year(); year <= time.Time(to).Year(); year++ {
+			if year < d.Year() {
+				continue
+			}
+
+			date := types.ToDate(year, d.Month(), d.Day())
+			if time.Time(date).Month() != d.Month() {
+				continue
+			}
+
+			if contains(date) {
+				dates = append(dates, date)
+			}
+		}
+	}
+
+	slices.SortFunc(dates, func(p, q types.Date) int {
+		return time.Time(p).Compare(time.Time(q))
+	})
+
+	return slices.CompactFunc(dates, func(p, q types.Date) bool {
+		return p.Equals(q)
+	})
+}
+
+// Splits a time profile into a chain of linked time profiles that collectively cover the profile
+// date range less the holidays in the calendar. The first profile in the chain retains the original
+// profile ID (so that cards assigned to the profile are unaffected), the remaining profiles are
+// allocated from the 'reserved' list and the last profile in the chain links to the original profile's
+// linked profile (if any).
+//
+// Holidays that fall on a weekday on which the profile is not active do not split the profile and
+// date ranges with no active weekdays are discarded.
+func HolidayProfiles(profile types.TimeProfile, calendar HolidayCalendar, reserved []uint8) ([]types.TimeProfile, error) {
+	if profile.From.IsZero() || profile.To.IsZero() {
+		return nil, fmt.Errorf("profile %v: invalid date range (%v:%v)", profile.ID, profile.From, profile.To)
+	}
+
+	active := func(d types.Date) bool {
+		return profile.Weekdays[d.Weekday()]
+	}
+
+	holidays := []types.Date{}
+	for _, d := range calendar.Between(profile.From, profile.To) {
+		if active(d) {
+			holidays = append(holidays, d)
+		}
+	}
+
+	// ... split date range at holidays
+	type daterange struct {
+		from types.Date
+		to   types.Date
+	}
+
+	ranges := []daterange{}
+	from := profile.From
+
+	for _, h := range append(holidays, types.Date(time.Time(profile.To).AddDate(0, 0, 1))) {
+		to := types.Date(time.Time(h).AddDate(0, 0, -1))
+
+		if !to.Before(from) {
+			for d := from; !d.After(to); d = types.Date(time.Time(d).AddDate(0, 0, 1)) {
+				if active(d) {
+					ranges = append(ranges, daterange{from, to})
+					break
+				}
+			}
+		}
+
+		from = types.Date(time.Time(h).AddDate(0, 0, 1))
+	}
+
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("profile %v: no active days in date range %v:%v after excluding holidays", profile.ID, profile.From, profile.To)
+	}
+
+	// ... allocate profile IDs
+	ids := []uint8{profile.ID}
+	for _, id := range reserved {
+		if len(ids) == len(ranges) {
+			break
+		}
+
+		if id >= 2 && id <= 254 && !slices.Contains(ids, id) && id != profile.LinkedProfileID {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) < len(ranges) {
+		return nil, fmt.Errorf("profile %v: insufficient reserved profile IDs (require %v, have %v)", profile.ID, len(ranges)-1, len(ids)-1)
+	}
+
+	// ... generate linked profiles
+	profiles := []types.TimeProfile{}
+
+	for i, r := range ranges {
+		p := types.TimeProfile{
+			ID:              ids[i],
+			LinkedProfileID: profile.LinkedProfileID,
+			From:            r.from,
+			To:              r.to,
+			Weekdays:        types.Weekdays{},
+			Segments:        types.Segments{},
+		}
+
+		if i+1 < len(ranges) {
+			p.LinkedProfileID = ids[i+1]
+		}
+
+		maps.Copy(p.Weekdays, profile.Weekdays)
+		maps.Copy(p.Segments, profile.Segments)
+
+		profiles = append(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+// Compiles the time profile and holiday calendar into a chain of linked time profiles (see HolidayProfiles)
+// and stores the generated profiles on the controller using PutTimeProfiles.
+func (u *UHPPOTED) PutHolidayProfiles(request PutHolidayProfilesRequest) (*PutTimeProfilesResponse, error) {
+	u.debug("put-holiday-profiles", fmt.Sprintf("request  %+v", request))
+
+	profiles, err := HolidayProfiles(request.Profile, request.Calendar, request.Reserved)
+	if err != nil {
+		return nil, invalid(request.DeviceID, "put-holiday-profiles", "profile", err)
+	}
+
+	return u.PutTimeProfiles(PutTimeProfilesRequest{
+		DeviceID: request.DeviceID,
+		Profiles: profiles,
+	})
+}
+
+func vevent(event map[string]string) ([]Holiday, error) {
+	start, err := icsdate(event["DTSTART"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid DTSTART (%w)", err)
+	}
+
+	end := start
+	if v, ok := event["DTEND"]; ok {
+		// NTS: DTEND is exclusive for all-day events
+		if d, err := icsdate(v); err != nil {
+			return nil, fmt.Errorf("invalid DTEND (%w)", err)
+		} else if d.After(start) {
+			end = types.Date(time.Time(d).AddDate(0, 0, -1))
+		}
+	}
+
+	yearly := false
+	if rule, ok := event["RRULE"]; ok {
+		if !slices.Contains(strings.Split(strings.ToUpper(rule), ";"), "FREQ=YEARLY") {
+			return nil, fmt.Errorf("unsupported RRULE (%v)", rule)
+		}
+
+		yearly = true
+	}
+
+	holidays := []Holiday{}
+	name := unescape(event["SUMMARY"])
+	for d := start; !d.After(end); d = types.Date(time.Time(d).AddDate(0, 0, 1)) {
+		holidays = append(holidays, Holiday{
+			Date:   d,
+			Name:   name,
+			Yearly: yearly,
+		})
+	}
+
+	return holidays, nil
+}
+
+func icsdate(s string) (types.Date, error) {
+	re := regexp.MustCompile(`^([0-9]{8})(?:T[0-9]{6}Z?)?$`)
+	if match := re.FindStringSubmatch(strings.TrimSpace(s)); match == nil {
+		return types.Date{}, fmt.Errorf("invalid date '%v'", s)
+	} else if date, err := time.ParseInLocation("20060102", match[1], time.Local); err != nil {
+		return types.Date{}, err
+	} else {
+		return types.Date(date), nil
+	}
+}
+
+// Ref. RFC 5545, 3.1 Content Lines
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+		} else {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, s.Err()
+}
+
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -0,0 +1,172 @@
+package uhppoted
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+func TestParseHolidaysTSV(t *testing.T) {
+	tsv := `Date	Holiday
+2026-01-01	New Year's Day
+2026-12-25	Christmas Day
+`
+
+	expected := HolidayCalendar{
+		Holidays: []Holiday{
+			{Date: types.MustParseDate("2026-01-01"), Name: "New Year's Day"},
+			{Date: types.MustParseDate("2026-12-25"), Name: "Christmas Day"},
+		},
+	}
+
+	calendar, err := ParseHolidaysTSV(strings.NewReader(tsv))
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !reflect.DeepEqual(calendar, expected) {
+		t.Errorf("Incorrect holiday calendar\n   expected: %+v\n   got:      %+v", expected, calendar)
+	}
+}
+
+func TestParseHolidaysICS(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"X-WR-CALNAME:Public Holidays\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20260101\r\n" +
+		"DTEND;VALUE=DATE:20260102\r\n" +
+		"RRULE:FREQ=YEARLY\r\n" +
+		"SUMMARY:New Year\\, Day\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20260403\r\n" +
+		"DTEND;VALUE=DATE:20260407\r\n" +
+		"SUMMARY:Easter\r\n" +
+		"  Weekend\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	expected := HolidayCalendar{
+		Name: "Public Holidays",
+		Holidays: []Holiday{
+			{Date: types.MustParseDate("2026-01-01"), Name: "New Year, Day", Yearly: true},
+			{Date: types.MustParseDate("2026-04-03"), Name: "Easter Weekend"},
+			{Date: types.MustParseDate("2026-04-04"), Name: "Easter Weekend"},
+			{Date: types.MustParseDate("2026-04-05"), Name: "Easter Weekend"},
+			{Date: types.MustParseDate("2026-04-06"), Name: "Easter Weekend"},
+		},
+	}
+
+	calendar, err := ParseHolidaysICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !reflect.DeepEqual(calendar, expected) {
+		t.Errorf("Incorrect holiday calendar\n   expected: %+v\n   got:      %+v", expected, calendar)
+	}
+}
+
+func TestHolidayProfiles(t *testing.T) {
+	profile := types.TimeProfile{
+		ID:              29,
+		LinkedProfileID: 19,
+		From:            types.MustParseDate("2026-12-01"),
+		To:              types.MustParseDate("2027-01-31"),
+		Weekdays: types.Weekdays{
+			time.Monday:    true,
+			time.Tuesday:   true,
+			time.Wednesday: true,
+			time.Thursday:  true,
+			time.Friday:    true,
+			time.Saturday:  false,
+			time.Sunday:    false,
+		},
+		Segments: types.Segments{
+			1: types.Segment{Start: hhmm("08:30"), End: hhmm("17:30")},
+			2: types.Segment{Start: hhmm("00:00"), End: hhmm("00:00")},
+			3: types.Segment{Start: hhmm("00:00"), End: hhmm("00:00")},
+		},
+	}
+
+	calendar := HolidayCalendar{
+		Holidays: []Holiday{
+			{Date: types.MustParseDate("2026-12-25"), Name: "Christmas Day"},
+			{Date: types.MustParseDate("2026-12-26"), Name: "Boxing Day"},
+			{Date: types.MustParseDate("2020-01-01"), Name: "New Year's Day", Yearly: true},
+		},
+	}
+
+	expected := []struct {
+		ID     uint8
+		From   string
+		To     string
+		Linked uint8
+	}{
+		{29, "2026-12-01", "2026-12-24", 30},
+		{30, "2026-12-26", "2026-12-31", 31},
+		{31, "2027-01-02", "2027-01-31", 19},
+	}
+
+	profiles, err := HolidayProfiles(profile, calendar, []uint8{19, 30, 31, 32})
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if len(profiles) != len(expected) {
+		t.Fatalf("Incorrect number of profiles - expected:%v, got:%v (%v)", len(expected), len(profiles), profiles)
+	}
+
+	for i, p := range profiles {
+		e := expected[i]
+		if p.ID != e.ID || p.From.String() != e.From || p.To.String() != e.To || p.LinkedProfileID != e.Linked {
+			t.Errorf("Incorrect profile %v\n   expected: %+v\n   got:      %v", i+1, e, p)
+		}
+
+		if !reflect.DeepEqual(p.Weekdays, profile.Weekdays) || !reflect.DeepEqual(p.Segments, profile.Segments) {
+			t.Errorf("Incorrect profile %v weekdays/segments (%v)", i+1, p)
+		}
+	}
+}
+
+func TestHolidayProfilesWithInsufficientIDs(t *testing.T) {
+	profile := types.TimeProfile{
+		ID:       29,
+		From:     types.MustParseDate("2026-01-01"),
+		To:       types.MustParseDate("2026-12-31"),
+		Weekdays: types.Weekdays{time.Friday: true},
+		Segments: types.Segments{},
+	}
+
+	calendar := HolidayCalendar{
+		Holidays: []Holiday{
+			{Date: types.MustParseDate("2026-04-03"), Name: "Good Friday"},
+			{Date: types.MustParseDate("2026-08-07"), Name: "Summer Bank Holiday"},
+		},
+	}
+
+	if _, err := HolidayProfiles(profile, calendar, []uint8{30}); err == nil {
+		t.Errorf("Expected error allocating linked profiles, got %v", err)
+	}
+}
+
+func TestHolidayCalendarBetweenWithYearlyLeapDay(t *testing.T) {
+	calendar := HolidayCalendar{
+		Holidays: []Holiday{
+			{Date: types.MustParseDate("2024-02-29"), Name: "Leap Day", Yearly: true},
+		},
+	}
+
+	expected := []types.Date{
+		types.MustParseDate("2028-02-29"),
+	}
+
+	dates := calendar.Between(types.MustParseDate("2026-01-01"), types.MustParseDate("2028-12-31"))
+	if !reflect.DeepEqual(dates, expected) {
+		t.Errorf("Incorrect holidays - expected:%v, got:%v", expected, dates)
+	}
+}
@@ -21,12 +21,19 @@ type IUHPPOTED interface {
 	GetCard(request GetCardRequest) (*GetCardResponse, error)
 	DeleteCard(request DeleteCardRequest) (*DeleteCardResponse, error)
 	GetTimeProfiles(request GetTimeProfilesRequest) (*GetTimeProfilesResponse, error)
-	PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProfilesResponse, int, error)
+	PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProfilesResponse, error)
 	GetTimeProfile(request GetTimeProfileRequest) (*GetTimeProfileResponse, error)
 	PutTimeProfile(request PutTimeProfileRequest) (*PutTimeProfileResponse, error)
 	ClearTimeProfiles(request ClearTimeProfilesRequest) (*ClearTimeProfilesResponse, error)
-	PutTaskList(request PutTaskListRequest) (*PutTaskListResponse, int, error)
+	PutTaskList(request PutTaskListRequest) (*PutTaskListResponse, error)
+	GetTaskList(request GetTaskListRequest) (*GetTaskListResponse, error)
+	UpdateTaskList(request UpdateTaskListRequest) (*UpdateTaskListResponse, error)
 	OpenDoor(request OpenDoorRequest) (*OpenDoorResponse, error)
+	Lockdown(request EmergencyRequest) (*EmergencyResponse, error)
+	Evacuate(request EmergencyRequest) (*EmergencyResponse, error)
+	EndEmergency(request EndEmergencyRequest) (*EmergencyResponse, error)
+	GetSiteReport(request GetSiteReportRequest) (*GetSiteReportResponse, error)
+	Reconcile(request ReconcileRequest) (*ReconcileResponse, error)
 
 	SetDoorControl(controller uint32, door uint8, mode types.ControlState) error
 	SetDoorDelay(controller uint32, door uint8, delay uint8) error
@@ -219,6 +226,28 @@ type PutTaskListResponse struct {
 	Warnings []error  `json:"warnings"`
 }
 
+type GetTaskListRequest struct {
+	DeviceID uint32
+}
+
+type GetTaskListResponse struct {
+	DeviceID DeviceID     `json:"device-id"`
+	Tasks    []types.Task `json:"tasks"`
+}
+
+type UpdateTaskListRequest struct {
+	DeviceID uint32
+	Tasks    []types.Task `json:"tasks"`
+	Force    bool         `json:"force,omitempty"`
+}
+
+type UpdateTaskListResponse struct {
+	DeviceID DeviceID     `json:"device-id"`
+	Diff     TaskListDiff `json:"diff"`
+	Updated  bool         `json:"updated"`
+	Warnings []error      `json:"warnings"`
+}
+
 type OpenDoorRequest struct {
 	DeviceID DeviceID
 	Door     uint8
@@ -229,3 +258,45 @@ type OpenDoorResponse struct {
 	Door     uint8    `json:"door"`
 	Opened   bool     `json:"opened"`
 }
+
+type EmergencyRequest struct {
+	Zone  string
+	Doors []ControllerDoor
+}
+
+type EndEmergencyRequest struct {
+	Zone string
+}
+
+type EmergencyResponse struct {
+	Mode  EmergencyMode   `json:"mode"`
+	Zone  string          `json:"zone,omitempty"`
+	Doors []EmergencyDoor `json:"doors"`
+}
+
+type GetSiteReportRequest struct {
+	Controllers []uint32
+	Interlocks  map[uint32]types.Interlock
+}
+
+type GetSiteReportResponse struct {
+	Controllers []ControllerSetup `json:"controllers"`
+}
+
+type ReconcileRequest struct {
+	Controllers map[uint32]ControllerSettings
+	DryRun      bool
+}
+
+type ReconcileResponse struct {
+	Controllers []ControllerReconciliation `json:"controllers"`
+}
+
+type EmergencyDoor struct {
+	DeviceID DeviceID           `json:"device-id"`
+	Door     uint8              `json:"door"`
+	Name     string             `json:"name,omitempty"`
+	State    types.ControlState `json:"state"`
+	Error    string             `json:"error,omitempty"`
+	Err      error              `json:"-"`
+}
@@ -0,0 +1,239 @@
+package uhppoted
+
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+	"github.com/uhppoted/uhppoted-lib/tracing"
+)
+
+// ControllerSettings is the declared configuration of a controller. Settings that are not
+// declared (nil or zero) are left 'as is' on the controller.
+type ControllerSettings struct {
+	Doors            map[uint8]DoorSettings
+	Interlock        *types.Interlock
+	AntiPassback     *types.AntiPassback
+	Keypads          map[uint8]bool
+	Listener         *netip.AddrPort
+	ListenerInterval *uint8
+}
+
+// DoorSettings is the declared control mode and unlock delay of a door. A zero mode or nil
+// delay is not declared and is left 'as is'.
+type DoorSettings struct {
+	Mode  types.ControlState
+	Delay *uint8
+}
+
+// ControllerReconciliation lists the differences between the declared and actual settings of
+// a controller. The interlock and keypad settings cannot be retrieved from a controller and
+// are listed as 'unverified' rather than compared.
+type ControllerReconciliation struct {
+	DeviceID   DeviceID `json:"device-id"`
+	Drift      []Drift  `json:"drift,omitempty"`
+	Unverified []string `json:"unverified,omitempty"`
+	Warnings   Warnings `json:"warnings,omitempty"`
+}
+
+// Drift is a setting that differs from the declared setting, using the configuration file
+// setting names (e.g. door.1.mode, anti-passback).
+type Drift struct {
+	Setting  string `json:"setting"`
+	Declared string `json:"declared"`
+	Actual   string `json:"actual"`
+	Updated  bool   `json:"updated"`
+}
+
+// Compares the declared settings of the controllers in the request with the actual controller
+// settings and (unless the request is a 'dry run') updates the controllers to match the
+// declared settings. Errors retrieving or updating a setting are reported as warnings for the
+// controller rather than failing the request.
+func (u *UHPPOTED) Reconcile(request ReconcileRequest) (*ReconcileResponse, error) {
+	u.debug("reconcile", fmt.Sprintf("request  %+v", request))
+
+	if len(request.Controllers) == 0 {
+		return nil, invalid(0, "reconcile", "controllers", fmt.Errorf("no controllers"))
+	}
+
+	response := ReconcileResponse{
+		Controllers: []ControllerReconciliation{},
+	}
+
+	for _, controller := range slices.Sorted(maps.Keys(request.Controllers)) {
+		x, span := u.trace("reconcile", controller, tracing.Attr("dry-run", request.DryRun))
+		reconciliation := u.reconcile(x, controller, request.Controllers[controller], request.DryRun)
+
+		span.SetAttributes(tracing.Attr("drift", len(reconciliation.Drift)), tracing.Attr("warnings", len(reconciliation.Warnings)))
+		span.End(nil)
+
+		response.Controllers = append(response.Controllers, reconciliation)
+	}
+
+	u.debug("reconcile", fmt.Sprintf("response %+v", response))
+
+	return &response, nil
+}
+
+func (u *UHPPOTED) reconcile(x uhppote.IUHPPOTE, controller uint32, settings ControllerSettings, dryrun bool) ControllerReconciliation {
+	r := ControllerReconciliation{
+		DeviceID:   DeviceID(controller),
+		Drift:      []Drift{},
+		Unverified: []string{},
+		Warnings:   []error{},
+	}
+
+	warn := func(err error) {
+		r.Warnings = append(r.Warnings, controllerError(controller, "reconcile", err))
+	}
+
+	// ... door control modes and delays
+	for _, door := range slices.Sorted(maps.Keys(settings.Doors)) {
+		declared := settings.Doors[door]
+
+		state, err := x.GetDoorControlState(controller, door)
+		if err != nil {
+			warn(fmt.Errorf("error retrieving door %v control state (%w)", door, err))
+			continue
+		} else if state == nil {
+			warn(fmt.Errorf("no response retrieving door %v control state", door))
+			continue
+		}
+
+		mode := state.ControlState
+		delay := state.Delay
+		drift := []Drift{}
+
+		if declared.Mode != 0 && declared.Mode != mode {
+			drift = append(drift, Drift{
+				Setting:  fmt.Sprintf("door.%v.mode", door),
+				Declared: fmt.Sprintf("%v", declared.Mode),
+				Actual:   fmt.Sprintf("%v", mode),
+			})
+
+			mode = declared.Mode
+		}
+
+		if declared.Delay != nil && *declared.Delay != delay {
+			drift = append(drift, Drift{
+				Setting:  fmt.Sprintf("door.%v.delay", door),
+				Declared: fmt.Sprintf("%v", *declared.Delay),
+				Actual:   fmt.Sprintf("%v", delay),
+			})
+
+			delay = *declared.Delay
+		}
+
+		if len(drift) > 0 && !dryrun {
+			if _, err := x.SetDoorControlState(controller, door, mode, delay); err != nil {
+				warn(fmt.Errorf("error setting door %v control state (%w)", door, err))
+			} else {
+				updated(drift)
+			}
+		}
+
+		r.Drift = append(r.Drift, drift...)
+	}
+
+	// ... anti-passback
+	if settings.AntiPassback != nil {
+		if antipassback, err := x.GetAntiPassback(controller); err != nil {
+			warn(fmt.Errorf("error retrieving anti-passback (%w)", err))
+		} else if antipassback != *settings.AntiPassback {
+			drift := []Drift{{
+				Setting:  "anti-passback",
+				Declared: fmt.Sprintf("%v", *settings.AntiPassback),
+				Actual:   fmt.Sprintf("%v", antipassback),
+			}}
+
+			if !dryrun {
+				if ok, err := x.SetAntiPassback(controller, *settings.AntiPassback); err != nil {
+					warn(fmt.Errorf("error setting anti-passback (%w)", err))
+				} else if !ok {
+					warn(fmt.Errorf("failed to set anti-passback %v", *settings.AntiPassback))
+				} else {
+					updated(drift)
+				}
+			}
+
+			r.Drift = append(r.Drift, drift...)
+		}
+	}
+
+	// ... event listener
+	if settings.Listener != nil || settings.ListenerInterval != nil {
+		if address, interval, err := x.GetListener(controller); err != nil {
+			warn(fmt.Errorf("error retrieving event listener (%w)", err))
+		} else {
+			drift := []Drift{}
+
+			if settings.Listener != nil && *settings.Listener != address {
+				drift = append(drift, Drift{
+					Setting:  "listener",
+					Declared: fmt.Sprintf("%v", *settings.Listener),
+					Actual:   fmt.Sprintf("%v", address),
+				})
+
+				address = *settings.Listener
+			}
+
+			if settings.ListenerInterval != nil && *settings.ListenerInterval != interval {
+				drift = append(drift, Drift{
+					Setting:  "listener.interval",
+					Declared: fmt.Sprintf("%v", *settings.ListenerInterval),
+					Actual:   fmt.Sprintf("%v", interval),
+				})
+
+				interval = *settings.ListenerInterval
+			}
+
+			if len(drift) > 0 && !dryrun {
+				if ok, err := x.SetListener(controller, address, interval); err != nil {
+					warn(fmt.Errorf("error setting event listener (%w)", err))
+				} else if !ok {
+					warn(fmt.Errorf("failed to set event listener %v", address))
+				} else {
+					updated(drift)
+				}
+			}
+
+			r.Drift = append(r.Drift, drift...)
+		}
+	}
+
+	// ... write-only settings
+	if settings.Interlock != nil {
+		r.Unverified = append(r.Unverified, "interlock")
+
+		if !dryrun {
+			if ok, err := x.SetInterlock(controller, *settings.Interlock); err != nil {
+				warn(fmt.Errorf("error setting door interlock %v (%w)", *settings.Interlock, err))
+			} else if !ok {
+				warn(fmt.Errorf("failed to set door interlock %v", *settings.Interlock))
+			}
+		}
+	}
+
+	if settings.Keypads != nil {
+		r.Unverified = append(r.Unverified, "keypads")
+
+		if !dryrun {
+			if ok, err := x.ActivateKeypads(controller, settings.Keypads); err != nil {
+				warn(fmt.Errorf("error activating keypads (%w)", err))
+			} else if !ok {
+				warn(fmt.Errorf("failed to activate keypads"))
+			}
+		}
+	}
+
+	return r
+}
+
+func updated(drift []Drift) {
+	for i := range drift {
+		drift[i].Updated = true
+	}
+}
@@ -0,0 +1,193 @@
+package uhppoted
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+func TestReconcile(t *testing.T) {
+	delay := uint8(5)
+	antipassback := types.Readers13_24
+	interlock := types.Interlock12
+	listener := netip.MustParseAddrPort("192.168.1.100:60001")
+
+	updates := []string{}
+
+	mock := stub{
+		getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: types.Controlled, Delay: 3}, nil
+		},
+		setDoorControlState: func(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+			if state != types.NormallyOpen || delay != 5 {
+				t.Errorf("Incorrect door %v control state - expected:%v %v, got:%v %v", door, types.NormallyOpen, 5, state, delay)
+			}
+
+			updates = append(updates, "door")
+			return &types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: state, Delay: delay}, nil
+		},
+		getAntiPassback: func(controller uint32) (types.AntiPassback, error) {
+			return types.Readers13_24, nil
+		},
+		setAntiPassback: func(controller uint32, antipassback types.AntiPassback) (bool, error) {
+			updates = append(updates, "anti-passback")
+			return true, nil
+		},
+		getListener: func(controller uint32) (netip.AddrPort, uint8, error) {
+			return netip.MustParseAddrPort("192.168.1.100:60002"), 0, nil
+		},
+		setListener: func(controller uint32, address netip.AddrPort, interval uint8) (bool, error) {
+			updates = append(updates, "listener")
+			return true, nil
+		},
+		setInterlock: func(controller uint32, interlock types.Interlock) (bool, error) {
+			updates = append(updates, "interlock")
+			return true, nil
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE: &mock,
+	}
+
+	request := ReconcileRequest{
+		Controllers: map[uint32]ControllerSettings{
+			405419896: {
+				Doors: map[uint8]DoorSettings{
+					2: {Mode: types.NormallyOpen, Delay: &delay},
+				},
+				AntiPassback: &antipassback,
+				Interlock:    &interlock,
+				Listener:     &listener,
+			},
+		},
+	}
+
+	response, err := u.Reconcile(request)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if len(response.Controllers) != 1 {
+		t.Fatalf("Incorrect number of controllers - expected:%v, got:%v", 1, len(response.Controllers))
+	}
+
+	drift := []Drift{
+		{Setting: "door.2.mode", Declared: "normally open", Actual: "controlled", Updated: true},
+		{Setting: "door.2.delay", Declared: "5", Actual: "3", Updated: true},
+		{Setting: "listener", Declared: "192.168.1.100:60001", Actual: "192.168.1.100:60002", Updated: true},
+	}
+
+	reconciliation := response.Controllers[0]
+
+	if !reflect.DeepEqual(reconciliation.Drift, drift) {
+		t.Errorf("Incorrect drift\n   expected:%+v\n   got:     %+v", drift, reconciliation.Drift)
+	}
+
+	if !reflect.DeepEqual(reconciliation.Unverified, []string{"interlock"}) {
+		t.Errorf("Incorrect unverified settings - expected:%v, got:%v", []string{"interlock"}, reconciliation.Unverified)
+	}
+
+	if len(reconciliation.Warnings) != 0 {
+		t.Errorf("Unexpected warnings %v", reconciliation.Warnings)
+	}
+
+	if expected := []string{"door", "listener", "interlock"}; !reflect.DeepEqual(updates, expected) {
+		t.Errorf("Incorrect updates - expected:%v, got:%v", expected, updates)
+	}
+}
+
+func TestReconcileDryRun(t *testing.T) {
+	antipassback := types.Readers12_34
+
+	mock := stub{
+		getAntiPassback: func(controller uint32) (types.AntiPassback, error) {
+			return types.Disabled, nil
+		},
+		setAntiPassback: func(controller uint32, antipassback types.AntiPassback) (bool, error) {
+			t.Errorf("Unexpected anti-passback update for dry run")
+			return true, nil
+		},
+		activateKeypads: func(controller uint32, keypads map[uint8]bool) (bool, error) {
+			t.Errorf("Unexpected keypads update for dry run")
+			return true, nil
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE: &mock,
+	}
+
+	request := ReconcileRequest{
+		Controllers: map[uint32]ControllerSettings{
+			405419896: {
+				AntiPassback: &antipassback,
+				Keypads:      map[uint8]bool{1: true, 2: false, 3: false, 4: false},
+			},
+		},
+		DryRun: true,
+	}
+
+	response, err := u.Reconcile(request)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	drift := []Drift{
+		{Setting: "anti-passback", Declared: "(1:2);(3:4)", Actual: "disabled", Updated: false},
+	}
+
+	if !reflect.DeepEqual(response.Controllers[0].Drift, drift) {
+		t.Errorf("Incorrect drift\n   expected:%+v\n   got:     %+v", drift, response.Controllers[0].Drift)
+	}
+
+	if !reflect.DeepEqual(response.Controllers[0].Unverified, []string{"keypads"}) {
+		t.Errorf("Incorrect unverified settings - expected:%v, got:%v", []string{"keypads"}, response.Controllers[0].Unverified)
+	}
+}
+
+func TestReconcileWithWarnings(t *testing.T) {
+	antipassback := types.Readers12_34
+
+	mock := stub{
+		getAntiPassback: func(controller uint32) (types.AntiPassback, error) {
+			return types.Disabled, fmt.Errorf("timeout")
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE: &mock,
+	}
+
+	request := ReconcileRequest{
+		Controllers: map[uint32]ControllerSettings{
+			405419896: {
+				AntiPassback: &antipassback,
+			},
+		},
+	}
+
+	response, err := u.Reconcile(request)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	var v struct {
+		Controllers []struct {
+			Warnings []string `json:"warnings"`
+		} `json:"controllers"`
+	}
+
+	if bytes, err := json.Marshal(response); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if err := json.Unmarshal(bytes, &v); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if len(v.Controllers) != 1 || len(v.Controllers[0].Warnings) != 1 || !strings.Contains(v.Controllers[0].Warnings[0], "anti-passback") {
+		t.Errorf("Incorrect serialized warnings - got:%s", bytes)
+	}
+}
@@ -0,0 +1,279 @@
+package uhppoted
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+// ControllerSetup is the door, anti-passback and interlock configuration of a controller,
+// described in terms of the configured door names.
+type ControllerSetup struct {
+	DeviceID     DeviceID          `json:"device-id"`
+	Name         string            `json:"name,omitempty"`
+	Doors        []DoorSetup       `json:"doors"`
+	AntiPassback AntiPassbackSetup `json:"anti-passback"`
+	Interlock    *InterlockSetup   `json:"interlock,omitempty"`
+	Issues       []string          `json:"issues,omitempty"`
+	Warnings     Warnings          `json:"warnings,omitempty"`
+}
+
+type DoorSetup struct {
+	Door  uint8              `json:"door"`
+	Name  string             `json:"name,omitempty"`
+	Mode  types.ControlState `json:"mode,omitempty"`
+	Delay uint8              `json:"delay,omitempty"`
+}
+
+// AntiPassbackSetup describes an anti-passback mode as a list of rules - a card that last
+// passed through one of the 'in' doors must next pass through one of the 'out' doors (and
+// vice versa).
+type AntiPassbackSetup struct {
+	Mode        types.AntiPassback `json:"mode"`
+	Description string             `json:"description"`
+	Rules       []AntiPassbackRule `json:"rules,omitempty"`
+}
+
+type AntiPassbackRule struct {
+	In  []string `json:"in"`
+	Out []string `json:"out"`
+}
+
+// InterlockSetup describes an interlock mode as groups of doors - only one door in a group
+// may be open at any one time.
+type InterlockSetup struct {
+	Mode        types.Interlock `json:"mode"`
+	Description string          `json:"description"`
+	Groups      [][]string      `json:"groups,omitempty"`
+}
+
+var antipassbackRules = map[types.AntiPassback][][2][]uint8{
+	types.Disabled:     {},
+	types.Readers12_34: {{{1}, {2}}, {{3}, {4}}},
+	types.Readers13_24: {{{1, 3}, {2, 4}}},
+	types.Readers1_23:  {{{1}, {2, 3}}},
+	types.Readers1_234: {{{1}, {2, 3, 4}}},
+}
+
+var interlockGroups = map[types.Interlock][][]uint8{
+	types.NoInterlock:    {},
+	types.Interlock12:    {{1, 2}},
+	types.Interlock34:    {{3, 4}},
+	types.Interlock12_34: {{1, 2}, {3, 4}},
+	types.Interlock123:   {{1, 2, 3}},
+	types.Interlock1234:  {{1, 2, 3, 4}},
+}
+
+// Retrieves the door control states and anti-passback mode of the controllers in the request
+// (or all configured controllers) and describes the anti-passback and interlock modes in terms
+// of the configured door names, along with any inconsistencies between the modes and the doors.
+//
+// The controllers do not report the interlock mode, so the interlock for a controller is only
+// described and checked if it is included in the request.
+func (u *UHPPOTED) GetSiteReport(request GetSiteReportRequest) (*GetSiteReportResponse, error) {
+	u.debug("get-site-report", fmt.Sprintf("request  %+v", request))
+
+	controllers := request.Controllers
+	if len(controllers) == 0 {
+		for id := range u.UHPPOTE.DeviceList() {
+			controllers = append(controllers, id)
+		}
+
+		slices.Sort(controllers)
+	}
+
+	if len(controllers) == 0 {
+		return nil, invalid(0, "get-site-report", "controllers", fmt.Errorf("no controllers"))
+	}
+
+	response := GetSiteReportResponse{
+		Controllers: []ControllerSetup{},
+	}
+
+	for _, controller := range controllers {
+		response.Controllers = append(response.Controllers, u.controllerSetup(controller, request.Interlocks))
+	}
+
+	u.debug("get-site-report", fmt.Sprintf("response %+v", response))
+
+	return &response, nil
+}
+
+// Describes an anti-passback mode in terms of the door names (indexed by door number - 1).
+func DescribeAntiPassback(mode types.AntiPassback, doors []string) AntiPassbackSetup {
+	setup := AntiPassbackSetup{
+		Mode:  mode,
+		Rules: []AntiPassbackRule{},
+	}
+
+	rules, ok := antipassbackRules[mode]
+	if !ok {
+		setup.Description = fmt.Sprintf("invalid anti-passback mode (%v)", uint8(mode))
+		return setup
+	} else if len(rules) == 0 {
+		setup.Description = "anti-passback disabled"
+		return setup
+	}
+
+	descriptions := []string{}
+	for _, rule := range rules {
+		in := names(rule[0], doors)
+		out := names(rule[1], doors)
+
+		setup.Rules = append(setup.Rules, AntiPassbackRule{In: in, Out: out})
+		descriptions = append(descriptions, fmt.Sprintf("in through %v, out through %v", strings.Join(in, " or "), strings.Join(out, " or ")))
+	}
+
+	setup.Description = strings.Join(descriptions, "; ")
+
+	return setup
+}
+
+// Describes an interlock mode in terms of the door names (indexed by door number - 1).
+func DescribeInterlock(mode types.Interlock, doors []string) InterlockSetup {
+	setup := InterlockSetup{
+		Mode:   mode,
+		Groups: [][]string{},
+	}
+
+	groups, ok := interlockGroups[mode]
+	if !ok {
+		setup.Description = fmt.Sprintf("invalid interlock mode (%v)", uint8(mode))
+		return setup
+	} else if len(groups) == 0 {
+		setup.Description = "interlock disabled"
+		return setup
+	}
+
+	descriptions := []string{}
+	for _, group := range groups {
+		list := names(group, doors)
+
+		setup.Groups = append(setup.Groups, list)
+		descriptions = append(descriptions, fmt.Sprintf("only one of %v open at a time", strings.Join(list, ", ")))
+	}
+
+	setup.Description = strings.Join(descriptions, "; ")
+
+	return setup
+}
+
+// Checks an anti-passback mode and (optional) interlock mode against the configured doors
+// and door control modes, returning a list of the inconsistencies. A door with a blank name
+// is regarded as not configured. The door control modes are optional.
+func ValidateAccessModes(antipassback types.AntiPassback, interlock *types.Interlock, doors []string, modes map[uint8]types.ControlState) []string {
+	issues := []string{}
+
+	configured := func(door uint8) bool {
+		return int(door) <= len(doors) && strings.TrimSpace(doors[door-1]) != ""
+	}
+
+	if rules, ok := antipassbackRules[antipassback]; !ok {
+		issues = append(issues, fmt.Sprintf("invalid anti-passback mode (%v)", uint8(antipassback)))
+	} else {
+		for _, rule := range rules {
+			for _, door := range slices.Concat(rule[0], rule[1]) {
+				if !configured(door) {
+					issues = append(issues, fmt.Sprintf("anti-passback %v: door %v is not configured", antipassback, door))
+				} else if modes[door] == types.NormallyOpen {
+					issues = append(issues, fmt.Sprintf("anti-passback %v: %v is normally open and does not record passage", antipassback, doors[door-1]))
+				}
+			}
+		}
+	}
+
+	if interlock != nil {
+		if groups, ok := interlockGroups[*interlock]; !ok {
+			issues = append(issues, fmt.Sprintf("invalid interlock mode (%v)", uint8(*interlock)))
+		} else {
+			for _, group := range groups {
+				for _, door := range group {
+					if !configured(door) {
+						issues = append(issues, fmt.Sprintf("interlock %v: door %v is not configured", *interlock, door))
+					} else if modes[door] == types.NormallyOpen {
+						issues = append(issues, fmt.Sprintf("interlock %v: %v is normally open and blocks the other interlocked doors", *interlock, doors[door-1]))
+					}
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+func (u *UHPPOTED) controllerSetup(controller uint32, interlocks map[uint32]types.Interlock) ControllerSetup {
+	doors := []string{"", "", "", ""}
+	setup := ControllerSetup{
+		DeviceID: DeviceID(controller),
+		Doors:    []DoorSetup{},
+		Issues:   []string{},
+		Warnings: []error{},
+	}
+
+	if device, ok := u.UHPPOTE.DeviceList()[controller]; ok {
+		setup.Name = device.Name
+		if len(device.Doors) > 0 {
+			doors = device.Doors
+		}
+	} else {
+		setup.Issues = append(setup.Issues, fmt.Sprintf("controller %v is not configured", controller))
+	}
+
+	modes := map[uint8]types.ControlState{}
+	for i := range doors {
+		door := uint8(i + 1)
+		v := DoorSetup{
+			Door: door,
+			Name: doors[i],
+		}
+
+		if state, err := u.UHPPOTE.GetDoorControlState(controller, door); err != nil {
+			setup.Warnings = append(setup.Warnings, controllerError(controller, "get-site-report", fmt.Errorf("error retrieving door %v control state (%w)", door, err)))
+		} else if state != nil {
+			v.Mode = state.ControlState
+			v.Delay = state.Delay
+			modes[door] = state.ControlState
+		}
+
+		setup.Doors = append(setup.Doors, v)
+	}
+
+	// ... an unknown anti-passback mode is noted and the door modes are validated against the
+	//     interlock only
+	antipassback, err := u.UHPPOTE.GetAntiPassback(controller)
+	if err != nil {
+		antipassback = types.Disabled
+		setup.AntiPassback = AntiPassbackSetup{Description: "unknown"}
+		setup.Warnings = append(setup.Warnings, controllerError(controller, "get-site-report", fmt.Errorf("error retrieving anti-passback (%w)", err)))
+		setup.Issues = append(setup.Issues, "anti-passback not checked (error retrieving anti-passback mode)")
+	} else {
+		setup.AntiPassback = DescribeAntiPassback(antipassback, doors)
+	}
+
+	var interlock *types.Interlock
+	if v, ok := interlocks[controller]; ok {
+		interlock = &v
+		description := DescribeInterlock(v, doors)
+		setup.Interlock = &description
+	}
+
+	setup.Issues = append(setup.Issues, ValidateAccessModes(antipassback, interlock, doors, modes)...)
+
+	return setup
+}
+
+func names(list []uint8, doors []string) []string {
+	l := []string{}
+
+	for _, door := range list {
+		if int(door) <= len(doors) && strings.TrimSpace(doors[door-1]) != "" {
+			l = append(l, strings.TrimSpace(doors[door-1]))
+		} else {
+			l = append(l, fmt.Sprintf("door %v", door))
+		}
+	}
+
+	return l
+}
@@ -0,0 +1,195 @@
+package uhppoted
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+func TestDescribeAntiPassback(t *testing.T) {
+	doors := []string{"Front", "Back", "Garage", "Workshop"}
+
+	tests := []struct {
+		mode        types.AntiPassback
+		description string
+		rules       []AntiPassbackRule
+	}{
+		{types.Disabled, "anti-passback disabled", []AntiPassbackRule{}},
+		{types.Readers12_34, "in through Front, out through Back; in through Garage, out through Workshop", []AntiPassbackRule{
+			{In: []string{"Front"}, Out: []string{"Back"}},
+			{In: []string{"Garage"}, Out: []string{"Workshop"}},
+		}},
+		{types.Readers13_24, "in through Front or Garage, out through Back or Workshop", []AntiPassbackRule{
+			{In: []string{"Front", "Garage"}, Out: []string{"Back", "Workshop"}},
+		}},
+		{types.Readers1_234, "in through Front, out through Back or Garage or Workshop", []AntiPassbackRule{
+			{In: []string{"Front"}, Out: []string{"Back", "Garage", "Workshop"}},
+		}},
+	}
+
+	for _, test := range tests {
+		setup := DescribeAntiPassback(test.mode, doors)
+
+		if setup.Description != test.description {
+			t.Errorf("%v: incorrect description\n   expected:%v\n   got:     %v", test.mode, test.description, setup.Description)
+		}
+
+		if !reflect.DeepEqual(setup.Rules, test.rules) {
+			t.Errorf("%v: incorrect rules\n   expected:%v\n   got:     %v", test.mode, test.rules, setup.Rules)
+		}
+	}
+}
+
+func TestDescribeInterlock(t *testing.T) {
+	setup := DescribeInterlock(types.Interlock12_34, []string{"Front", "Back", "", ""})
+
+	expected := InterlockSetup{
+		Mode:        types.Interlock12_34,
+		Description: "only one of Front, Back open at a time; only one of door 3, door 4 open at a time",
+		Groups:      [][]string{{"Front", "Back"}, {"door 3", "door 4"}},
+	}
+
+	if !reflect.DeepEqual(setup, expected) {
+		t.Errorf("Incorrect interlock description\n   expected:%+v\n   got:     %+v", expected, setup)
+	}
+}
+
+func TestGetSiteReport(t *testing.T) {
+	mock := stub{
+		deviceList: func() map[uint32]uhppote.Device {
+			return map[uint32]uhppote.Device{
+				405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{"Front", "Back", "Garage", "Workshop"}, time.Local),
+				303986753: uhppote.NewDevice("Beta", 303986753, types.ControllerAddr{}, "udp", []string{"Lab A", "Lab B", "", ""}, time.Local),
+			}
+		},
+		getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+			state := types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: types.Controlled, Delay: 5}
+			if controller == 303986753 && door == 2 {
+				state.ControlState = types.NormallyOpen
+			}
+
+			return &state, nil
+		},
+		getAntiPassback: func(controller uint32) (types.AntiPassback, error) {
+			if controller == 303986753 {
+				return types.Readers12_34, nil
+			}
+
+			return types.Readers13_24, nil
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE: &mock,
+	}
+
+	request := GetSiteReportRequest{
+		Interlocks: map[uint32]types.Interlock{
+			303986753: types.Interlock12,
+		},
+	}
+
+	response, err := u.GetSiteReport(request)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if len(response.Controllers) != 2 {
+		t.Fatalf("Incorrect number of controllers - expected:%v, got:%v", 2, len(response.Controllers))
+	}
+
+	beta := response.Controllers[0]
+	alpha := response.Controllers[1]
+
+	if alpha.DeviceID != 405419896 || beta.DeviceID != 303986753 {
+		t.Fatalf("Incorrect controller order - got %v, %v", beta.DeviceID, alpha.DeviceID)
+	}
+
+	if len(alpha.Issues) != 0 {
+		t.Errorf("Unexpected issues for %v: %v", alpha.DeviceID, alpha.Issues)
+	}
+
+	if alpha.Interlock != nil {
+		t.Errorf("Unexpected interlock for %v: %+v", alpha.DeviceID, alpha.Interlock)
+	}
+
+	if beta.Doors[1].Mode != types.NormallyOpen {
+		t.Errorf("Incorrect door 2 mode - expected:%v, got:%v", types.NormallyOpen, beta.Doors[1].Mode)
+	}
+
+	issues := []string{
+		"anti-passback (1:2);(3:4): Lab B is normally open and does not record passage",
+		"anti-passback (1:2);(3:4): door 3 is not configured",
+		"anti-passback (1:2);(3:4): door 4 is not configured",
+		"interlock 1&2: Lab B is normally open and blocks the other interlocked doors",
+	}
+
+	if !reflect.DeepEqual(beta.Issues, issues) {
+		t.Errorf("Incorrect issues\n   expected:%q\n   got:     %q", issues, beta.Issues)
+	}
+}
+
+func TestGetSiteReportWithAntiPassbackError(t *testing.T) {
+	mock := stub{
+		deviceList: func() map[uint32]uhppote.Device {
+			return map[uint32]uhppote.Device{
+				303986753: uhppote.NewDevice("Beta", 303986753, types.ControllerAddr{}, "udp", []string{"Lab A", "Lab B", "", ""}, time.Local),
+			}
+		},
+		getDoorControlState: func(controller uint32, door uint8) (*types.DoorControlState, error) {
+			state := types.DoorControlState{SerialNumber: types.SerialNumber(controller), Door: door, ControlState: types.Controlled, Delay: 5}
+			if door == 2 {
+				state.ControlState = types.NormallyOpen
+			}
+
+			return &state, nil
+		},
+		getAntiPassback: func(controller uint32) (types.AntiPassback, error) {
+			return types.Disabled, fmt.Errorf("timeout")
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE: &mock,
+	}
+
+	request := GetSiteReportRequest{
+		Interlocks: map[uint32]types.Interlock{
+			303986753: types.Interlock12,
+		},
+	}
+
+	response, err := u.GetSiteReport(request)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	issues := []string{
+		"anti-passback not checked (error retrieving anti-passback mode)",
+		"interlock 1&2: Lab B is normally open and blocks the other interlocked doors",
+	}
+
+	if !reflect.DeepEqual(response.Controllers[0].Issues, issues) {
+		t.Errorf("Incorrect issues\n   expected:%q\n   got:     %q", issues, response.Controllers[0].Issues)
+	}
+
+	var v struct {
+		Controllers []struct {
+			Warnings []string `json:"warnings"`
+		} `json:"controllers"`
+	}
+
+	if bytes, err := json.Marshal(response); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if err := json.Unmarshal(bytes, &v); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if len(v.Controllers) != 1 || len(v.Controllers[0].Warnings) != 1 || !strings.Contains(v.Controllers[0].Warnings[0], "error retrieving anti-passback") {
+		t.Errorf("Incorrect serialized warnings - got:%s", bytes)
+	}
+}
@@ -1,44 +1,339 @@
 package uhppoted
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
-	"net/http"
+	"io"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppoted-lib/encoding/tsv"
 )
 
-func (u *UHPPOTED) PutTaskList(request PutTaskListRequest) (*PutTaskListResponse, int, error) {
+type TaskListDiff struct {
+	Unchanged []types.Task `json:"unchanged"`
+	Added     []types.Task `json:"added"`
+	Removed   []types.Task `json:"removed"`
+}
+
+func (d TaskListDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0
+}
+
+type tsvTask struct {
+	Task      types.TaskType `tsv:"Task"`
+	Door      uint8          `tsv:"Door"`
+	From      types.Date     `tsv:"From"`
+	To        types.Date     `tsv:"To"`
+	Monday    bool           `tsv:"Mon"`
+	Tuesday   bool           `tsv:"Tue"`
+	Wednesday bool           `tsv:"Wed"`
+	Thursday  bool           `tsv:"Thurs"`
+	Friday    bool           `tsv:"Fri"`
+	Saturday  bool           `tsv:"Sat"`
+	Sunday    bool           `tsv:"Sun"`
+	Start     types.HHmm     `tsv:"Start"`
+	Cards     uint8          `tsv:"Cards"`
+}
+
+var weekdays = []time.Weekday{
+	time.Monday,
+	time.Tuesday,
+	time.Wednesday,
+	time.Thursday,
+	time.Friday,
+	time.Saturday,
+	time.Sunday,
+}
+
+func (u *UHPPOTED) PutTaskList(request PutTaskListRequest) (*PutTaskListResponse, error) {
 	u.debug("put-task-list", fmt.Sprintf("request  %+v", request))
 
 	deviceID := request.DeviceID
 	tasks := request.Tasks
-	warnings := []error{}
 
+	added, warnings, err := u.putTaskList(deviceID, "put-task-list", tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.TaskLists != nil {
+		if err := u.TaskLists.PutTaskList(deviceID, added); err != nil {
+			warnings = append(warnings, fmt.Errorf("%v: could not update stored task list (%v)", deviceID, err))
+		}
+	}
+
+	// ... format response
+	response := PutTaskListResponse{
+		DeviceID: DeviceID(deviceID),
+		Warnings: warnings,
+	}
+
+	u.debug("put-task-list", fmt.Sprintf("response %+v", response))
+
+	return &response, nil
+}
+
+// Returns the locally stored copy of the controller task list. Returns ErrNotFound if there
+// is no stored task list for the controller.
+func (u *UHPPOTED) GetTaskList(request GetTaskListRequest) (*GetTaskListResponse, error) {
+	u.debug("get-task-list", fmt.Sprintf("request  %+v", request))
+
+	deviceID := request.DeviceID
+
+	if u.TaskLists == nil {
+		return nil, notFound(deviceID, "get-task-list", fmt.Errorf("no task list store configured"))
+	}
+
+	tasks, ok, err := u.TaskLists.GetTaskList(deviceID)
+	if err != nil {
+		return nil, controllerError(deviceID, "get-task-list", fmt.Errorf("error retrieving stored task list for %v (%w)", deviceID, err))
+	} else if !ok {
+		return nil, notFound(deviceID, "get-task-list", fmt.Errorf("no stored task list for %v", deviceID))
+	}
+
+	response := GetTaskListResponse{
+		DeviceID: DeviceID(deviceID),
+		Tasks:    tasks,
+	}
+
+	u.debug("get-task-list", fmt.Sprintf("response %+v", response))
+
+	return &response, nil
+}
+
+// Compares the requested task list with the locally stored copy and updates the controller
+// only if the task list has changed. Task lists that only add tasks are updated incrementally,
+// otherwise the controller task list is cleared and rewritten. A controller with no stored task
+// list (or a 'forced' update) is always rewritten.
+func (u *UHPPOTED) UpdateTaskList(request UpdateTaskListRequest) (*UpdateTaskListResponse, error) {
+	u.debug("update-task-list", fmt.Sprintf("request  %+v", request))
+
+	deviceID := request.DeviceID
+	tasks := request.Tasks
+
+	if u.TaskLists == nil {
+		return nil, controllerError(deviceID, "update-task-list", fmt.Errorf("no task list store configured"))
+	}
+
+	stored, ok, err := u.TaskLists.GetTaskList(deviceID)
+	if err != nil {
+		return nil, controllerError(deviceID, "update-task-list", fmt.Errorf("error retrieving stored task list for %v (%w)", deviceID, err))
+	}
+
+	diff := DiffTaskList(stored, tasks)
+	response := UpdateTaskListResponse{
+		DeviceID: DeviceID(deviceID),
+		Diff:     diff,
+		Updated:  false,
+		Warnings: []error{},
+	}
+
+	switch {
+	case ok && !request.Force && !diff.HasChanges():
+		u.debug("update-task-list", fmt.Sprintf("%v: task list unchanged", deviceID))
+
+	case ok && !request.Force && len(diff.Removed) == 0:
+		added, warnings := u.addTasks(deviceID, diff.Added)
+
+		if ok, err := u.UHPPOTE.RefreshTaskList(deviceID); err != nil {
+			return nil, controllerError(deviceID, "update-task-list", err)
+		} else if !ok {
+			return nil, rejected(deviceID, "update-task-list", fmt.Errorf("could not refresh task list on controller"))
+		}
+
+		if err := u.TaskLists.PutTaskList(deviceID, append(stored, added...)); err != nil {
+			warnings = append(warnings, fmt.Errorf("%v: could not update stored task list (%v)", deviceID, err))
+		}
+
+		response.Updated = true
+		response.Warnings = warnings
+
+	default:
+		added, warnings, err := u.putTaskList(deviceID, "update-task-list", tasks)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := u.TaskLists.PutTaskList(deviceID, added); err != nil {
+			warnings = append(warnings, fmt.Errorf("%v: could not update stored task list (%v)", deviceID, err))
+		}
+
+		response.Updated = true
+		response.Warnings = warnings
+	}
+
+	u.debug("update-task-list", fmt.Sprintf("response %+v", response))
+
+	return &response, nil
+}
+
+// Compares two task lists, returning the tasks that are common to both lists, the tasks that
+// are only in the 'desired' list and the tasks that are only in the 'current' list. Duplicate
+// tasks are matched one-for-one.
+func DiffTaskList(current, desired []types.Task) TaskListDiff {
+	diff := TaskListDiff{
+		Unchanged: []types.Task{},
+		Added:     []types.Task{},
+		Removed:   []types.Task{},
+	}
+
+	matched := make([]bool, len(current))
+
+loop:
+	for _, task := range desired {
+		for i, t := range current {
+			if !matched[i] && equalTasks(t, task) {
+				matched[i] = true
+				diff.Unchanged = append(diff.Unchanged, task)
+				continue loop
+			}
+		}
+
+		diff.Added = append(diff.Added, task)
+	}
+
+	for i, t := range current {
+		if !matched[i] {
+			diff.Removed = append(diff.Removed, t)
+		}
+	}
+
+	return diff
+}
+
+// Parses a task list from a TSV file with the columns:
+//
+//	Task  Door  From  To  Mon  Tue  Wed  Thurs  Fri  Sat  Sun  Start  Cards
+//
+// Tasks may be specified either by task code (1-13) or by task description (e.g. 'unlock door').
+func ParseTaskListTSV(r io.Reader) ([]types.Task, error) {
+	records := []tsvTask{}
+
+	if b, err := io.ReadAll(r); err != nil {
+		return nil, err
+	} else if err := tsv.Unmarshal(b, &records); err != nil {
+		return nil, err
+	}
+
+	tasks := []types.Task{}
+	for _, record := range records {
+		tasks = append(tasks, types.Task{
+			Task: record.Task,
+			Door: record.Door,
+			From: record.From,
+			To:   record.To,
+			Weekdays: types.Weekdays{
+				time.Monday:    record.Monday,
+				time.Tuesday:   record.Tuesday,
+				time.Wednesday: record.Wednesday,
+				time.Thursday:  record.Thursday,
+				time.Friday:    record.Friday,
+				time.Saturday:  record.Saturday,
+				time.Sunday:    record.Sunday,
+			},
+			Start: record.Start,
+			Cards: record.Cards,
+		})
+	}
+
+	return tasks, nil
+}
+
+// Writes a task list as TSV, in the format accepted by ParseTaskListTSV.
+func TaskListToTSV(w io.Writer, tasks []types.Task) error {
+	var b bytes.Buffer
+
+	yn := func(v bool) string {
+		if v {
+			return "Y"
+		}
+
+		return "N"
+	}
+
+	t := csv.NewWriter(&b)
+	t.Comma = '\t'
+
+	t.Write([]string{"Task", "Door", "From", "To", "Mon", "Tue", "Wed", "Thurs", "Fri", "Sat", "Sun", "Start", "Cards"})
+	for _, task := range tasks {
+		record := []string{
+			fmt.Sprintf("%v", int(task.Task)+1),
+			fmt.Sprintf("%v", task.Door),
+			fmt.Sprintf("%v", task.From),
+			fmt.Sprintf("%v", task.To),
+		}
+
+		for _, d := range weekdays {
+			record = append(record, yn(task.Weekdays[d]))
+		}
+
+		record = append(record, fmt.Sprintf("%v", task.Start), fmt.Sprintf("%v", task.Cards))
+
+		t.Write(record)
+	}
+
+	t.Flush()
+
+	if err := t.Error(); err != nil {
+		return err
+	}
+
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+func (u *UHPPOTED) putTaskList(deviceID uint32, operation string, tasks []types.Task) ([]types.Task, []error, error) {
 	if ok, err := u.UHPPOTE.ClearTaskList(deviceID); err != nil {
-		return nil, http.StatusInternalServerError, err
+		return nil, nil, controllerError(deviceID, operation, err)
+	} else if !ok {
+		return nil, nil, rejected(deviceID, operation, fmt.Errorf("could not clear task list"))
+	}
+
+	added, warnings := u.addTasks(deviceID, tasks)
+
+	if ok, err := u.UHPPOTE.RefreshTaskList(deviceID); err != nil {
+		return nil, nil, controllerError(deviceID, operation, err)
 	} else if !ok {
-		return nil, http.StatusInternalServerError, fmt.Errorf("%v: could not clear  task list", deviceID)
+		return nil, nil, rejected(deviceID, operation, fmt.Errorf("could not refresh task list on controller"))
 	}
 
+	return added, warnings, nil
+}
+
+func (u *UHPPOTED) addTasks(deviceID uint32, tasks []types.Task) ([]types.Task, []error) {
+	added := []types.Task{}
+	warnings := []error{}
+
 	for i, task := range tasks {
 		if ok, err := u.UHPPOTE.AddTask(deviceID, task); err != nil {
 			warnings = append(warnings, fmt.Errorf("%v: could not add task %d to controller (%v)", deviceID, i+1, err))
 		} else if !ok {
 			warnings = append(warnings, fmt.Errorf("%v: could not add task %d to controller", deviceID, i+1))
+		} else {
+			added = append(added, task)
 		}
 	}
 
-	if ok, err := u.UHPPOTE.RefreshTaskList(deviceID); err != nil {
-		return nil, http.StatusInternalServerError, err
-	} else if !ok {
-		return nil, http.StatusInternalServerError, fmt.Errorf("%v: could not refresh  task list on controller", deviceID)
+	return added, warnings
+}
+
+func equalTasks(p, q types.Task) bool {
+	if p.Task != q.Task || p.Door != q.Door || p.Cards != q.Cards {
+		return false
 	}
 
-	// ... format response
-	response := PutTaskListResponse{
-		DeviceID: DeviceID(deviceID),
-		Warnings: warnings,
+	if !p.From.Equals(q.From) || !p.To.Equals(q.To) || !p.Start.Equals(q.Start) {
+		return false
 	}
 
-	u.debug("put-task-list", fmt.Sprintf("response %+v", response))
+	for _, d := range weekdays {
+		if p.Weekdays[d] != q.Weekdays[d] {
+			return false
+		}
+	}
 
-	return &response, http.StatusOK, nil
+	return true
 }
@@ -0,0 +1,119 @@
+package uhppoted
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/uhppoted/uhppote-core/types"
+	lib "github.com/uhppoted/uhppoted-lib/os"
+)
+
+// TaskListStore is the local authoritative copy of the controller task lists (the controller
+// does not provide any means of retrieving the stored task list).
+type TaskListStore interface {
+	// Returns the stored task list for a controller and false if there is no stored task list.
+	GetTaskList(controller uint32) ([]types.Task, bool, error)
+
+	// Replaces the stored task list for a controller.
+	PutTaskList(controller uint32, tasks []types.Task) error
+}
+
+type tasklistStore struct {
+	dir       string
+	tasklists map[uint32][]types.Task
+	guard     sync.RWMutex
+}
+
+// Creates a TaskListStore that persists each controller task list as a JSON file in the
+// directory 'dir'. A blank directory creates an in-memory store.
+func NewTaskListStore(dir string) TaskListStore {
+	return &tasklistStore{
+		dir:       dir,
+		tasklists: map[uint32][]types.Task{},
+	}
+}
+
+func (s *tasklistStore) GetTaskList(controller uint32) ([]types.Task, bool, error) {
+	s.guard.RLock()
+	tasks, ok := s.tasklists[controller]
+	s.guard.RUnlock()
+
+	if ok {
+		return cloneTasks(tasks), true, nil
+	}
+
+	if s.dir == "" {
+		return nil, false, nil
+	}
+
+	bytes, err := os.ReadFile(s.file(controller))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	tasks = []types.Task{}
+	if err := json.Unmarshal(bytes, &tasks); err != nil {
+		return nil, false, fmt.Errorf("%v: invalid stored task list (%w)", controller, err)
+	}
+
+	s.guard.Lock()
+	s.tasklists[controller] = tasks
+	s.guard.Unlock()
+
+	return cloneTasks(tasks), true, nil
+}
+
+func (s *tasklistStore) PutTaskList(controller uint32, tasks []types.Task) error {
+	s.guard.Lock()
+	defer s.guard.Unlock()
+
+	if s.dir != "" {
+		bytes, err := json.MarshalIndent(tasks, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(s.dir, 0755); err != nil {
+			return err
+		}
+
+		file := s.file(controller)
+		tmpfile := file + ".tmp"
+		if err := os.WriteFile(tmpfile, bytes, 0644); err != nil {
+			return err
+		}
+
+		if err := lib.Rename(tmpfile, file); err != nil {
+			return err
+		}
+	}
+
+	s.tasklists[controller] = cloneTasks(tasks)
+
+	return nil
+}
+
+func (s *tasklistStore) file(controller uint32) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%v.tasks.json", controller))
+}
+
+func cloneTasks(tasks []types.Task) []types.Task {
+	list := make([]types.Task, len(tasks))
+
+	for i, task := range tasks {
+		list[i] = task
+		list[i].Weekdays = types.Weekdays{}
+
+		maps.Copy(list[i].Weekdays, task.Weekdays)
+	}
+
+	return list
+}
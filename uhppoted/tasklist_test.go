@@ -0,0 +1,180 @@
+package uhppoted
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+)
+
+var tasklist = []types.Task{
+	{
+		Task:     types.DoorNormallyOpen,
+		Door:     3,
+		From:     types.MustParseDate("2026-01-01"),
+		To:       types.MustParseDate("2026-12-31"),
+		Weekdays: types.Weekdays{time.Monday: true, time.Tuesday: true, time.Wednesday: false, time.Thursday: true, time.Friday: true, time.Saturday: false, time.Sunday: false},
+		Start:    hhmm("08:30"),
+	},
+	{
+		Task:     types.DoorControlled,
+		Door:     3,
+		From:     types.MustParseDate("2026-01-01"),
+		To:       types.MustParseDate("2026-12-31"),
+		Weekdays: types.Weekdays{time.Monday: true, time.Tuesday: true, time.Wednesday: false, time.Thursday: true, time.Friday: true, time.Saturday: false, time.Sunday: false},
+		Start:    hhmm("17:30"),
+	},
+}
+
+func TestTaskListTSV(t *testing.T) {
+	expected := `Task	Door	From	To	Mon	Tue	Wed	Thurs	Fri	Sat	Sun	Start	Cards
+2	3	2026-01-01	2026-12-31	Y	Y	N	Y	Y	N	N	08:30	0
+1	3	2026-01-01	2026-12-31	Y	Y	N	Y	Y	N	N	17:30	0
+`
+
+	var b bytes.Buffer
+	if err := TaskListToTSV(&b, tasklist); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if b.String() != expected {
+		t.Errorf("Incorrect TSV\n   expected:\n%v\n   got:\n%v", expected, b.String())
+	}
+
+	tasks, err := ParseTaskListTSV(strings.NewReader(expected))
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !reflect.DeepEqual(tasks, tasklist) {
+		t.Errorf("Incorrect task list\n   expected: %v\n   got:      %v", tasklist, tasks)
+	}
+}
+
+func TestDiffTaskList(t *testing.T) {
+	added := tasklist[1]
+	added.Start = hhmm("18:00")
+
+	diff := DiffTaskList(tasklist, []types.Task{tasklist[0], added})
+
+	if !reflect.DeepEqual(diff.Unchanged, tasklist[0:1]) {
+		t.Errorf("Incorrect 'unchanged' tasks - expected:%v, got:%v", tasklist[0:1], diff.Unchanged)
+	}
+
+	if !reflect.DeepEqual(diff.Added, []types.Task{added}) {
+		t.Errorf("Incorrect 'added' tasks - expected:%v, got:%v", []types.Task{added}, diff.Added)
+	}
+
+	if !reflect.DeepEqual(diff.Removed, tasklist[1:2]) {
+		t.Errorf("Incorrect 'removed' tasks - expected:%v, got:%v", tasklist[1:2], diff.Removed)
+	}
+}
+
+func TestUpdateTaskList(t *testing.T) {
+	cleared := 0
+	added := []types.Task{}
+	refreshed := 0
+
+	mock := stub{
+		clearTaskList: func(controller uint32) (bool, error) {
+			cleared++
+			added = []types.Task{}
+			return true, nil
+		},
+
+		addTask: func(controller uint32, task types.Task) (bool, error) {
+			added = append(added, task)
+			return true, nil
+		},
+
+		refreshTaskList: func(controller uint32) (bool, error) {
+			refreshed++
+			return true, nil
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE:   &mock,
+		TaskLists: NewTaskListStore(""),
+	}
+
+	// ... initial update rewrites task list
+	if response, err := u.UpdateTaskList(UpdateTaskListRequest{DeviceID: 405419896, Tasks: tasklist[0:1]}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if !response.Updated || cleared != 1 || refreshed != 1 || len(added) != 1 {
+		t.Errorf("Incorrect initial update - updated:%v cleared:%v refreshed:%v added:%v", response.Updated, cleared, refreshed, len(added))
+	}
+
+	// ... unchanged task list is not updated
+	if response, err := u.UpdateTaskList(UpdateTaskListRequest{DeviceID: 405419896, Tasks: tasklist[0:1]}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if response.Updated || cleared != 1 || refreshed != 1 {
+		t.Errorf("Unexpected update of unchanged task list - updated:%v cleared:%v refreshed:%v", response.Updated, cleared, refreshed)
+	}
+
+	// ... additional task is added incrementally
+	if response, err := u.UpdateTaskList(UpdateTaskListRequest{DeviceID: 405419896, Tasks: tasklist}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if !response.Updated || cleared != 1 || refreshed != 2 || len(added) != 2 {
+		t.Errorf("Incorrect incremental update - updated:%v cleared:%v refreshed:%v added:%v", response.Updated, cleared, refreshed, len(added))
+	}
+
+	// ... removed task rewrites task list
+	if response, err := u.UpdateTaskList(UpdateTaskListRequest{DeviceID: 405419896, Tasks: tasklist[1:]}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if !response.Updated || cleared != 2 || refreshed != 3 || len(added) != 1 {
+		t.Errorf("Incorrect update - updated:%v cleared:%v refreshed:%v added:%v", response.Updated, cleared, refreshed, len(added))
+	}
+
+	if response, err := u.GetTaskList(GetTaskListRequest{DeviceID: 405419896}); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if !reflect.DeepEqual(response.Tasks, tasklist[1:]) {
+		t.Errorf("Incorrect stored task list\n   expected: %v\n   got:      %v", tasklist[1:], response.Tasks)
+	}
+}
+
+func TestTaskListStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewTaskListStore(dir)
+
+	if err := store.PutTaskList(405419896, tasklist); err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	tasks, ok, err := NewTaskListStore(dir).GetTaskList(405419896)
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	} else if !ok {
+		t.Fatalf("Missing stored task list")
+	}
+
+	if len(tasks) != len(tasklist) {
+		t.Fatalf("Incorrect stored task list\n   expected: %v\n   got:      %v", tasklist, tasks)
+	}
+
+	for i := range tasks {
+		if !equalTasks(tasks[i], tasklist[i]) {
+			t.Errorf("Incorrect stored task %v\n   expected: %v\n   got:      %v", i+1, tasklist[i], tasks[i])
+		}
+	}
+}
+
+func TestGetTaskListWithoutStore(t *testing.T) {
+	u := UHPPOTED{}
+
+	_, err := u.GetTaskList(GetTaskListRequest{DeviceID: 405419896})
+
+	var e ControllerError
+	if !errors.As(err, &e) {
+		t.Fatalf("Expected ControllerError, got %v", err)
+	} else if e.Controller() != 405419896 {
+		t.Errorf("Incorrect error controller - expected:%v, got:%v", 405419896, e.Controller())
+	}
+
+	if status := HTTPStatus(err); status != http.StatusNotFound {
+		t.Errorf("Incorrect HTTP status - expected:%v, got:%v", http.StatusNotFound, status)
+	}
+}
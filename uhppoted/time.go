@@ -11,7 +11,7 @@ func (u *UHPPOTED) GetTime(request GetTimeRequest) (*GetTimeResponse, error) {
 	device := uint32(request.DeviceID)
 	result, err := u.UHPPOTE.GetTime(device)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error getting time for %v (%w)", device, err))
+		return nil, controllerError(device, "get-time", fmt.Errorf("error getting time for %v (%w)", device, err))
 	}
 
 	response := GetTimeResponse{
@@ -30,7 +30,7 @@ func (u *UHPPOTED) SetTime(request SetTimeRequest) (*SetTimeResponse, error) {
 	device := uint32(request.DeviceID)
 	result, err := u.UHPPOTE.SetTime(device, time.Time(request.DateTime))
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error setting time for %v (%w)", device, err))
+		return nil, controllerError(device, "set-time", fmt.Errorf("error setting time for %v (%w)", device, err))
 	}
 
 	response := SetTimeResponse{
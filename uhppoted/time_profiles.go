@@ -2,10 +2,11 @@ package uhppoted
 
 import (
 	"fmt"
-	"net/http"
 	"reflect"
 
 	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+	"github.com/uhppoted/uhppoted-lib/tracing"
 )
 
 func (u *UHPPOTED) GetTimeProfiles(request GetTimeProfilesRequest) (*GetTimeProfilesResponse, error) {
@@ -28,7 +29,7 @@ func (u *UHPPOTED) GetTimeProfiles(request GetTimeProfilesRequest) (*GetTimeProf
 	for i := from; i <= to; i++ {
 		profile, err := u.UHPPOTE.GetTimeProfile(deviceID, uint8(i))
 		if err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error retrieving time profile %v from %v (%w)", i, deviceID, err))
+			return nil, controllerError(deviceID, "get-time-profiles", fmt.Errorf("error retrieving time profile %v from %v (%w)", i, deviceID, err))
 		}
 
 		if profile != nil {
@@ -46,9 +47,27 @@ func (u *UHPPOTED) GetTimeProfiles(request GetTimeProfilesRequest) (*GetTimeProf
 	return &response, nil
 }
 
-func (u *UHPPOTED) PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProfilesResponse, int, error) {
+func (u *UHPPOTED) PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProfilesResponse, error) {
 	u.debug("put-time-profiles", fmt.Sprintf("request  %+v", request))
 
+	x, span := u.trace("put-time-profiles", request.DeviceID, tracing.Attr("profiles", len(request.Profiles)))
+	response, err := u.putTimeProfiles(x, request)
+	if response != nil {
+		span.SetAttributes(tracing.Attr("warnings", len(response.Warnings)))
+	}
+
+	span.End(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	u.debug("put-time-profiles", fmt.Sprintf("response %+v", response))
+
+	return response, nil
+}
+
+func (u *UHPPOTED) putTimeProfiles(x uhppote.IUHPPOTE, request PutTimeProfilesRequest) (*PutTimeProfilesResponse, error) {
 	deviceID := request.DeviceID
 	profiles := request.Profiles
 
@@ -59,7 +78,7 @@ func (u *UHPPOTED) PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProf
 	for i, profile := range profiles {
 		if index, ok := set[profile.ID]; ok {
 			if !reflect.DeepEqual(profile, profiles[index-1]) {
-				return nil, http.StatusBadRequest, fmt.Errorf("profile %v has more than one definition (records %v and %v)", profile.ID, index, i+1)
+				return nil, invalid(deviceID, "put-time-profiles", fmt.Sprintf("profiles[%v]", i), fmt.Errorf("profile %v has more than one definition (records %v and %v)", profile.ID, index, i+1))
 			}
 
 			prewarn = append(prewarn, fmt.Errorf("profile %-3v is defined twice (records %v and %v)", profile.ID, index, i+1))
@@ -93,8 +112,8 @@ func (u *UHPPOTED) PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProf
 
 			// verify linked profile exists
 			if linked := profile.LinkedProfileID; linked != 0 {
-				if p, err := u.UHPPOTE.GetTimeProfile(deviceID, linked); err != nil {
-					return nil, http.StatusInternalServerError, err
+				if p, err := x.GetTimeProfile(deviceID, linked); err != nil {
+					return nil, controllerError(deviceID, "put-time-profiles", err)
 				} else if p == nil {
 					warnings = append(warnings, fmt.Errorf("profile %-3v: linked time profile %v is not defined", profile.ID, linked))
 					continue
@@ -102,14 +121,14 @@ func (u *UHPPOTED) PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProf
 			}
 
 			// check for circular references
-			if err := circularReference(u, deviceID, profile); err != nil {
+			if err := circularReference(x, deviceID, profile); err != nil {
 				warnings = append(warnings, fmt.Errorf("profile %-3v: %v", profile.ID, err))
 				continue
 			}
 
 			// good to go!
-			if ok, err := u.UHPPOTE.SetTimeProfile(deviceID, profile); err != nil {
-				return nil, http.StatusInternalServerError, err
+			if ok, err := x.SetTimeProfile(deviceID, profile); err != nil {
+				return nil, controllerError(deviceID, "put-time-profiles", err)
 			} else if !ok {
 				warnings = append(warnings, fmt.Errorf("%v: could not create time profile %v", deviceID, profile.ID))
 			} else {
@@ -131,9 +150,7 @@ func (u *UHPPOTED) PutTimeProfiles(request PutTimeProfilesRequest) (*PutTimeProf
 		Warnings: warnings,
 	}
 
-	u.debug("put-time-profiles", fmt.Sprintf("response %+v", response))
-
-	return &response, http.StatusOK, nil
+	return &response, nil
 }
 
 func (u *UHPPOTED) GetTimeProfile(request GetTimeProfileRequest) (*GetTimeProfileResponse, error) {
@@ -144,11 +161,11 @@ func (u *UHPPOTED) GetTimeProfile(request GetTimeProfileRequest) (*GetTimeProfil
 
 	profile, err := u.UHPPOTE.GetTimeProfile(deviceID, profileID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error retrieving time profile %v from %v (%w)", profileID, deviceID, err))
+		return nil, controllerError(deviceID, "get-time-profile", fmt.Errorf("error retrieving time profile %v from %v (%w)", profileID, deviceID, err))
 	}
 
 	if profile == nil {
-		return nil, fmt.Errorf("%w: %v", ErrNotFound, fmt.Errorf("error retrieving time profile %v from %v", profileID, deviceID))
+		return nil, notFound(deviceID, "get-time-profile", fmt.Errorf("error retrieving time profile %v from %v", profileID, deviceID))
 	}
 
 	response := GetTimeProfileResponse{
@@ -169,31 +186,31 @@ func (u *UHPPOTED) PutTimeProfile(request PutTimeProfileRequest) (*PutTimeProfil
 	linked := profile.LinkedProfileID
 
 	if profile.ID < 2 || profile.ID > 254 {
-		return nil, fmt.Errorf("invalid time profile ID (%v) - valid range is [1..254]", profile.ID)
+		return nil, invalid(deviceID, "put-time-profile", "time-profile.id", fmt.Errorf("invalid time profile ID (%v) - valid range is [2..254]", profile.ID))
 	}
 
 	if linked != 0 {
 		if linked == profile.ID {
-			return nil, fmt.Errorf("link to self creates circular reference")
+			return nil, invalid(deviceID, "put-time-profile", "time-profile.linked-profile", fmt.Errorf("link to self creates circular reference"))
 		}
 
 		if p, err := u.UHPPOTE.GetTimeProfile(deviceID, linked); err != nil {
-			return nil, err
+			return nil, controllerError(deviceID, "put-time-profile", err)
 		} else if p == nil {
-			return nil, fmt.Errorf("linked time profile %v is not defined", linked)
+			return nil, invalid(deviceID, "put-time-profile", "time-profile.linked-profile", fmt.Errorf("linked time profile %v is not defined", linked))
 		}
 
 		profiles := map[uint8]bool{profile.ID: true}
 		links := []uint8{profile.ID}
 		for l := linked; l != 0; {
 			if p, err := u.UHPPOTE.GetTimeProfile(deviceID, l); err != nil {
-				return nil, err
+				return nil, controllerError(deviceID, "put-time-profile", err)
 			} else if p == nil {
-				return nil, fmt.Errorf("linked time profile %v is not defined", l)
+				return nil, invalid(deviceID, "put-time-profile", "time-profile.linked-profile", fmt.Errorf("linked time profile %v is not defined", l))
 			} else {
 				links = append(links, p.ID)
 				if profiles[p.ID] {
-					return nil, fmt.Errorf("linking to time profile %v creates a circular reference (%v)", linked, links)
+					return nil, invalid(deviceID, "put-time-profile", "time-profile.linked-profile", fmt.Errorf("linking to time profile %v creates a circular reference (%v)", linked, links))
 				}
 
 				profiles[p.ID] = true
@@ -204,11 +221,11 @@ func (u *UHPPOTED) PutTimeProfile(request PutTimeProfileRequest) (*PutTimeProfil
 
 	ok, err := u.UHPPOTE.SetTimeProfile(deviceID, profile)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error writing time profile %v to %v (%w)", profile.ID, deviceID, err))
+		return nil, controllerError(deviceID, "put-time-profile", fmt.Errorf("error writing time profile %v to %v (%w)", profile.ID, deviceID, err))
 	}
 
 	if !ok {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("failed to write time profile %v to %v", profile.ID, deviceID))
+		return nil, rejected(deviceID, "put-time-profile", fmt.Errorf("failed to write time profile %v to %v", profile.ID, deviceID))
 	}
 
 	response := PutTimeProfileResponse{
@@ -228,7 +245,7 @@ func (u *UHPPOTED) ClearTimeProfiles(request ClearTimeProfilesRequest) (*ClearTi
 
 	cleared, err := u.UHPPOTE.ClearTimeProfiles(deviceID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalServerError, fmt.Errorf("error clearing time profiles from %v (%w)", deviceID, err))
+		return nil, controllerError(deviceID, "clear-time-profiles", fmt.Errorf("error clearing time profiles from %v (%w)", deviceID, err))
 	}
 
 	response := ClearTimeProfilesResponse{
@@ -267,13 +284,13 @@ func validateTimeProfile(profile types.TimeProfile) error {
 	return nil
 }
 
-func circularReference(u *UHPPOTED, deviceID uint32, profile types.TimeProfile) error {
+func circularReference(u uhppote.IUHPPOTE, deviceID uint32, profile types.TimeProfile) error {
 	if linked := profile.LinkedProfileID; linked != 0 {
 		profiles := map[uint8]bool{profile.ID: true}
 		chain := []uint8{profile.ID}
 
 		for l := linked; l != 0; {
-			if p, err := u.UHPPOTE.GetTimeProfile(deviceID, l); err != nil {
+			if p, err := u.GetTimeProfile(deviceID, l); err != nil {
 				return err
 			} else if p == nil {
 				return fmt.Errorf("linked time profile %v is not defined", l)
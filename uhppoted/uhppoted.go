@@ -3,9 +3,11 @@ package uhppoted
 import (
 	"errors"
 	"net/http"
+	"sync"
 
 	"github.com/uhppoted/uhppote-core/uhppote"
 	"github.com/uhppoted/uhppoted-lib/log"
+	"github.com/uhppoted/uhppoted-lib/tracing"
 )
 
 const (
@@ -27,6 +29,26 @@ var (
 type UHPPOTED struct {
 	UHPPOTE         uhppote.IUHPPOTE
 	ListenBatchSize int
+	TaskLists       TaskListStore
+	Emergencies     EmergencyStore
+	Zones           map[string][]string
+	Models          map[uint32]string
+	Tracer          tracing.Tracer
+
+	emergencies sync.Mutex
+}
+
+// Starts the span for a multi-step operation and returns the IUHPPOTE to use for the operation,
+// which traces each controller call as a child span if a tracer is configured.
+func (u *UHPPOTED) trace(operation string, deviceID uint32, attributes ...tracing.Attribute) (uhppote.IUHPPOTE, tracing.Span) {
+	if u.Tracer == nil {
+		return u.UHPPOTE, tracing.NopTracer{}.Start(operation)
+	}
+
+	attributes = append([]tracing.Attribute{tracing.Attr("controller", deviceID)}, attributes...)
+	span := u.Tracer.Start(operation, attributes...)
+
+	return tracing.NewUHPPOTE(u.UHPPOTE, span), span
 }
 
 func (u *UHPPOTED) debug(tag string, msg any) {
@@ -12,16 +12,33 @@ import (
 )
 
 type stub struct {
+	deviceList          func() map[uint32]uhppote.Device
+	getStatus           func(controller uint32) (*types.Status, error)
+	getDoorControlState func(controller uint32, door uint8) (*types.DoorControlState, error)
+	setDoorControlState func(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error)
 	getTimeProfile      func(controller uint32, profileID uint8) (*types.TimeProfile, error)
 	setTimeProfile      func(controller uint32, profile types.TimeProfile) (bool, error)
 	clearTimeProfiles   func(controller uint32) (bool, error)
+	clearTaskList       func(controller uint32) (bool, error)
+	addTask             func(controller uint32, task types.Task) (bool, error)
+	refreshTaskList     func(controller uint32) (bool, error)
 	getEventIndex       func(controller uint32) (*types.EventIndex, error)
 	setEventIndex       func(controller, index uint32) (*types.EventIndexResult, error)
 	getEvent            func(controller, index uint32) (*types.Event, error)
 	recordSpecialEvents func(controller uint32, enable bool) (bool, error)
+	getAntiPassback     func(controller uint32) (types.AntiPassback, error)
+	setAntiPassback     func(controller uint32, antipassback types.AntiPassback) (bool, error)
+	getListener         func(controller uint32) (netip.AddrPort, uint8, error)
+	setListener         func(controller uint32, address netip.AddrPort, interval uint8) (bool, error)
+	setInterlock        func(controller uint32, interlock types.Interlock) (bool, error)
+	activateKeypads     func(controller uint32, keypads map[uint8]bool) (bool, error)
 }
 
 func (m *stub) DeviceList() map[uint32]uhppote.Device {
+	if m.deviceList != nil {
+		return m.deviceList()
+	}
+
 	return nil
 }
 
@@ -50,14 +67,26 @@ func (m *stub) SetTime(serialNumber uint32, datetime time.Time) (*types.Time, er
 }
 
 func (m *stub) GetListener(controller uint32) (netip.AddrPort, uint8, error) {
+	if m.getListener != nil {
+		return m.getListener(controller)
+	}
+
 	return netip.AddrPort{}, 0, nil
 }
 
 func (m *stub) SetListener(controller uint32, address netip.AddrPort, interval uint8) (bool, error) {
+	if m.setListener != nil {
+		return m.setListener(controller, address, interval)
+	}
+
 	return false, nil
 }
 
 func (m *stub) GetStatus(serialNumber uint32) (*types.Status, error) {
+	if m.getStatus != nil {
+		return m.getStatus(serialNumber)
+	}
+
 	return nil, nil
 }
 
@@ -86,10 +115,18 @@ func (m *stub) DeleteCards(controller uint32) (bool, error) {
 }
 
 func (m *stub) GetDoorControlState(controller uint32, door byte) (*types.DoorControlState, error) {
+	if m.getDoorControlState != nil {
+		return m.getDoorControlState(controller, door)
+	}
+
 	return nil, nil
 }
 
 func (m *stub) SetDoorControlState(controller uint32, door uint8, state types.ControlState, delay uint8) (*types.DoorControlState, error) {
+	if m.setDoorControlState != nil {
+		return m.setDoorControlState(controller, door, state, delay)
+	}
+
 	return nil, nil
 }
 
@@ -98,10 +135,18 @@ func (m *stub) SetDoorPasscodes(controller uint32, door uint8, passcodes ...uint
 }
 
 func (m *stub) SetInterlock(controller uint32, interlock types.Interlock) (bool, error) {
+	if m.setInterlock != nil {
+		return m.setInterlock(controller, interlock)
+	}
+
 	return false, nil
 }
 
 func (m *stub) ActivateKeypads(controller uint32, keypads map[uint8]bool) (bool, error) {
+	if m.activateKeypads != nil {
+		return m.activateKeypads(controller, keypads)
+	}
+
 	return false, nil
 }
 
@@ -130,14 +175,26 @@ func (m *stub) ClearTimeProfiles(controller uint32) (bool, error) {
 }
 
 func (m *stub) ClearTaskList(controller uint32) (bool, error) {
+	if m.clearTaskList != nil {
+		return m.clearTaskList(controller)
+	}
+
 	return false, fmt.Errorf("Not implemented")
 }
 
 func (m *stub) AddTask(controller uint32, task types.Task) (bool, error) {
+	if m.addTask != nil {
+		return m.addTask(controller, task)
+	}
+
 	return false, fmt.Errorf("Not implemented")
 }
 
 func (m *stub) RefreshTaskList(controller uint32) (bool, error) {
+	if m.refreshTaskList != nil {
+		return m.refreshTaskList(controller)
+	}
+
 	return false, fmt.Errorf("Not implemented")
 }
 
@@ -182,10 +239,18 @@ func (m *stub) SetPCControl(controller uint32, enable bool) (bool, error) {
 }
 
 func (m *stub) GetAntiPassback(controller uint32) (types.AntiPassback, error) {
+	if m.getAntiPassback != nil {
+		return m.getAntiPassback(controller)
+	}
+
 	return types.Disabled, fmt.Errorf("NOT IMPLEMENTED")
 }
 
 func (m *stub) SetAntiPassback(controller uint32, antipassback types.AntiPassback) (bool, error) {
+	if m.setAntiPassback != nil {
+		return m.setAntiPassback(controller, antipassback)
+	}
+
 	return false, fmt.Errorf("NOT IMPLEMENTED")
 }
 
@@ -0,0 +1,66 @@
+package uhppoted
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Resolves a list of door and zone names to the controller doors, for operations that are
+// 'fanned out' across multiple doors. Names are matched case-insensitively, ignoring spaces,
+// against the zones in UHPPOTED.Zones and the door names of the configured controllers.
+func (u *UHPPOTED) ResolveDoors(names []string) ([]ControllerDoor, error) {
+	doors := []ControllerDoor{}
+
+	add := func(door ControllerDoor) {
+		if !slices.Contains(doors, door) {
+			doors = append(doors, door)
+		}
+	}
+
+	for _, name := range names {
+		if door, ok := u.lookupDoor(name); ok {
+			add(door)
+		} else if zone, ok := u.lookupZone(name); ok {
+			for _, d := range zone {
+				if door, ok := u.lookupDoor(d); ok {
+					add(door)
+				} else {
+					return nil, invalid(0, "resolve-doors", "doors", fmt.Errorf("zone '%v': door '%v' is not defined in the device configuration", name, d))
+				}
+			}
+		} else {
+			return nil, invalid(0, "resolve-doors", "doors", fmt.Errorf("'%v' is not a configured door or zone", name))
+		}
+	}
+
+	return doors, nil
+}
+
+func (u *UHPPOTED) lookupDoor(name string) (ControllerDoor, bool) {
+	key := normalise(name)
+
+	for _, door := range u.doors() {
+		if n := u.doorName(uint32(door.DeviceID), door.Door); n != "" && normalise(n) == key {
+			return door, true
+		}
+	}
+
+	return ControllerDoor{}, false
+}
+
+func (u *UHPPOTED) lookupZone(name string) ([]string, bool) {
+	key := normalise(name)
+
+	for k, v := range u.Zones {
+		if normalise(k) == key {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+func normalise(s string) string {
+	return strings.ReplaceAll(strings.ToLower(s), " ", "")
+}
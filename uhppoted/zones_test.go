@@ -0,0 +1,49 @@
+package uhppoted
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uhppoted/uhppote-core/types"
+	"github.com/uhppoted/uhppote-core/uhppote"
+)
+
+func TestResolveDoors(t *testing.T) {
+	mock := stub{
+		deviceList: func() map[uint32]uhppote.Device {
+			return map[uint32]uhppote.Device{
+				405419896: uhppote.NewDevice("Alpha", 405419896, types.ControllerAddr{}, "udp", []string{"Lab A", "Lab B", "Store", "Front Door"}, time.Local),
+				303986753: uhppote.NewDevice("Beta", 303986753, types.ControllerAddr{}, "udp", []string{"Lab C", "", "", ""}, time.Local),
+			}
+		},
+	}
+
+	u := UHPPOTED{
+		UHPPOTE: &mock,
+		Zones: map[string][]string{
+			"lab": {"Lab A", "Lab B", "Lab C"},
+		},
+	}
+
+	expected := []ControllerDoor{
+		{DeviceID: 405419896, Door: 4},
+		{DeviceID: 405419896, Door: 1},
+		{DeviceID: 405419896, Door: 2},
+		{DeviceID: 303986753, Door: 1},
+	}
+
+	doors, err := u.ResolveDoors([]string{"front door", "LAB", "Lab A"})
+	if err != nil {
+		t.Fatalf("Unexpected error (%v)", err)
+	}
+
+	if !reflect.DeepEqual(doors, expected) {
+		t.Errorf("Incorrect doors\n   expected:%v\n   got:     %v", expected, doors)
+	}
+
+	if _, err := u.ResolveDoors([]string{"Garage"}); !errors.Is(err, ErrBadRequest) {
+		t.Errorf("Expected 'bad request' error for unknown door, got %v", err)
+	}
+}